@@ -15,11 +15,24 @@ job "barista-api" {
       port "http" {
         to = 5003
       }
+
+      port "admin" {
+        to = 9100
+      }
     }
 
     service {
       name = "barista-api"
       port = "5003"
+
+      check {
+        name     = "ready"
+        type     = "http"
+        path     = "/readyz"
+        port     = "admin"
+        interval = "10s"
+        timeout  = "2s"
+      }
     }
 
     task "barista-api" {
@@ -34,14 +47,14 @@ job "barista-api" {
         command = "bash"
         args = [
           "-c",
-          "cd local/repo/cmd/barista && go mod tidy && go mod download && CGO_ENABLED=0 go run -tags migrate github.com/thangchung/go-coffeeshop/cmd/barista"
+          "cd local/repo/cmd/barista && go mod tidy && go mod download && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/migrate -service barista up && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/barista"
         ]
       }
 
       env {
         APP_NAME     = "barista-service in docker"
-        IN_DOCKER    = "false"
-        PG_URL       = "postgres://postgres:P@ssw0rd@${attr.unique.network.ip-address}:5432/postgres"
+        ADMIN_PORT   = "9100"
+        PG_DSN_URL   = "host=${attr.unique.network.ip-address} user=postgres password=P@ssw0rd dbname=postgres sslmode=disable"
         RABBITMQ_URL = "amqp://guest:guest@${attr.unique.network.ip-address}:5672/"
       }
 
@@ -15,12 +15,27 @@ job "counter-api" {
       port "grpc" {
         to = 5002
       }
+
+      port "admin" {
+        to = 9100
+      }
     }
 
     service {
       name = "counter-api-grpc"
       port = "5002"
 
+      check {
+        name     = "ready"
+        type     = "http"
+        path     = "/readyz"
+        port     = "admin"
+        interval = "10s"
+        timeout  = "2s"
+        # the sidecar only forwards the mesh traffic, the check goes through an exposed path
+        expose   = true
+      }
+
       connect {
         sidecar_service {
           proxy {
@@ -53,14 +68,14 @@ job "counter-api" {
         command = "bash"
         args = [
           "-c",
-          "cd local/repo/cmd/counter && go mod tidy && go mod download && CGO_ENABLED=0 go run -tags migrate github.com/thangchung/go-coffeeshop/cmd/counter"
+          "cd local/repo/cmd/counter && go mod tidy && go mod download && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/migrate -service counter up && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/counter"
         ]
       }
 
       env {
         APP_NAME           = "counter-service in docker"
-        IN_DOCKER          = "false"
-        PG_URL             = "postgres://postgres:P@ssw0rd@${attr.unique.network.ip-address}:5432/postgres"
+        ADMIN_PORT         = "9100"
+        PG_DSN_URL         = "host=${attr.unique.network.ip-address} user=postgres password=P@ssw0rd dbname=postgres sslmode=disable"
         RABBITMQ_URL       = "amqp://guest:guest@${attr.unique.network.ip-address}:5672/"
         PRODUCT_CLIENT_URL = "${NOMAD_UPSTREAM_ADDR_product_api_grpc}"
       }
@@ -21,6 +21,17 @@ job "grpc-gw" {
       name = "grpc-gw-http"
       port = "5000"
 
+      check {
+        name     = "ready"
+        type     = "http"
+        path     = "/readyz"
+        port     = "http"
+        interval = "10s"
+        timeout  = "2s"
+        # the sidecar only forwards the mesh traffic, the check goes through an exposed path
+        expose   = true
+      }
+
       connect {
         sidecar_service {
           proxy {
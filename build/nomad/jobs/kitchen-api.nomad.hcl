@@ -15,11 +15,24 @@ job "kitchen-api" {
       port "http" { 
         to = 5004 
       }
+
+      port "admin" {
+        to = 9100
+      }
     }
 
     service {
       name = "kitchen-api"
       port = "5004"
+
+      check {
+        name     = "ready"
+        type     = "http"
+        path     = "/readyz"
+        port     = "admin"
+        interval = "10s"
+        timeout  = "2s"
+      }
     }
 
     task "kitchen-api" {
@@ -34,14 +47,14 @@ job "kitchen-api" {
         command = "bash"
         args = [
           "-c",
-          "cd local/repo/cmd/kitchen && go mod tidy && go mod download && CGO_ENABLED=0 go run -tags migrate github.com/thangchung/go-coffeeshop/cmd/kitchen"
+          "cd local/repo/cmd/kitchen && go mod tidy && go mod download && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/migrate -service kitchen up && CGO_ENABLED=0 go run github.com/thangchung/go-coffeeshop/cmd/kitchen"
         ]
       }
 
       env {
         APP_NAME     = "kitchen-service in docker"
-        IN_DOCKER    = "false"
-        PG_URL       = "postgres://postgres:P@ssw0rd@${attr.unique.network.ip-address}:5432/postgres"
+        ADMIN_PORT   = "9100"
+        PG_DSN_URL   = "host=${attr.unique.network.ip-address} user=postgres password=P@ssw0rd dbname=postgres sslmode=disable"
         RABBITMQ_URL = "amqp://guest:guest@${attr.unique.network.ip-address}:5672/"
       }
 
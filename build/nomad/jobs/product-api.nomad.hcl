@@ -15,12 +15,27 @@ job "product-api" {
       port "grpc" {
         to = 5001
       }
+
+      port "admin" {
+        to = 9100
+      }
     }
 
     service {
       name = "product-api-grpc"
       port = "5001"
 
+      check {
+        name     = "ready"
+        type     = "http"
+        path     = "/readyz"
+        port     = "admin"
+        interval = "10s"
+        timeout  = "2s"
+        # the sidecar only forwards the mesh traffic, the check goes through an exposed path
+        expose   = true
+      }
+
       connect {
         sidecar_service {}
       }
@@ -43,7 +58,8 @@ job "product-api" {
       }
 
       env {
-        APP_NAME = "product-service in docker"
+        APP_NAME   = "product-service in docker"
+        ADMIN_PORT = "9100"
       }
 
       resources {
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	counterConfig "github.com/thangchung/go-coffeeshop/cmd/counter/config"
+	productConfig "github.com/thangchung/go-coffeeshop/cmd/product/config"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+)
+
+type (
+	// Config runs every service in one process, the gateway listens on the HTTP host and port.
+	Config struct {
+		configs.App              `yaml:"app"`
+		configs.HTTP             `yaml:"http"`
+		configs.GRPC             `yaml:"grpc"`
+		configs.Log              `yaml:"logger"`
+		configs.Tracing          `yaml:"tracing"`
+		configs.PG               `yaml:"postgres"`
+		configs.Preparation      `yaml:"preparation"`
+		configs.Auth             `yaml:"auth"`
+		configs.CORS             `yaml:"cors"`
+		EmbeddedPG               `yaml:"embedded_postgres"`
+		Web                      `yaml:"web"`
+		ProductCache             `yaml:"product_cache"`
+		productConfig.Images     `yaml:"images"`
+		productConfig.Menu       `yaml:"menu"`
+		productConfig.Catalog    `yaml:"catalog"`
+		counterConfig.OrderStore `yaml:"order_store"`
+		counterConfig.Reporting  `yaml:"reporting"`
+	}
+
+	// EmbeddedPG starts a PostgreSQL server of its own when it is enabled, the DsnURL of PG is not used then.
+	// The sqlite driver of PG needs no server, it is ignored with it.
+	// The binaries are downloaded into CachePath the first time.
+	EmbeddedPG struct {
+		Enabled bool   `env-default:"true"  yaml:"enabled" env:"EMBEDDED_PG_ENABLED"`
+		Port    uint32 `env-default:"5433"  yaml:"port"    env:"EMBEDDED_PG_PORT"`
+		// DataPath keeps the database between the runs, empty starts from scratch every time.
+		DataPath  string `env-default:"" yaml:"data_path"  env:"EMBEDDED_PG_DATA_PATH"`
+		CachePath string `env-default:"" yaml:"cache_path" env:"EMBEDDED_PG_CACHE_PATH"`
+	}
+
+	Web struct {
+		Host string `env-default:"0.0.0.0" yaml:"host" env:"WEB_HOST"`
+		Port int    `env-default:"8888"    yaml:"port" env:"WEB_PORT"`
+		// ReverseProxyURL is where the browser finds the gateway.
+		ReverseProxyURL string `env-default:"http://localhost:5000" yaml:"reverse_proxy_url" env:"REVERSE_PROXY_URL"`
+	}
+
+	ProductCache struct {
+		TTL      time.Duration `env-default:"30s" yaml:"ttl"       env:"PRODUCT_CACHE_TTL"`
+		MaxStale time.Duration `env-default:"1h"  yaml:"max_stale" env:"PRODUCT_CACHE_MAX_STALE"`
+	}
+)
+
+func NewConfig() (*Config, error) {
+	cfg := &Config{}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = cleanenv.ReadConfig(dir+"/config.yml", cfg)
+	if err != nil {
+		return nil, fmt.Errorf("config error: %w", err)
+	}
+
+	err = cleanenv.ReadEnv(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
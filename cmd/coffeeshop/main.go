@@ -0,0 +1,150 @@
+// Coffeeshop runs the product, counter, barista and kitchen services, the gateway and the web UI in one process.
+// The services talk over in-memory gRPC connections and an in-memory bus and share one database,
+// an embedded PostgreSQL unless it is turned off, so nothing else has to run for local development and demos.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	// the price schedules are in the time zone of each location
+	_ "time/tzdata"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/thangchung/go-coffeeshop/cmd/coffeeshop/config"
+	"github.com/thangchung/go-coffeeshop/cmd/web/app"
+	"github.com/thangchung/go-coffeeshop/internal/gateway"
+	"github.com/thangchung/go-coffeeshop/internal/inprocess"
+	"github.com/thangchung/go-coffeeshop/pkg/admin"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/logger"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
+	"go.uber.org/automaxprocs/maxprocs"
+	"golang.org/x/exp/slog"
+
+	_ "github.com/lib/pq"
+)
+
+const _readHeaderTimeout = 5 * time.Second
+
+func main() {
+	// set GOMAXPROCS
+	_, err := maxprocs.Set()
+	if err != nil {
+		slog.Error("failed set max procs", "error", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg, err := config.NewConfig()
+	if err != nil {
+		slog.Error("failed get config", "error", err)
+
+		return
+	}
+
+	slog.Info("⚡ init app", "name", cfg.Name, "version", cfg.Version)
+
+	// set up logrus
+	logrus.SetFormatter(&logrus.JSONFormatter{})
+	logrus.SetOutput(os.Stdout)
+	logrus.SetLevel(logger.ConvertLogLevel(cfg.Log.Level))
+
+	// integrate Logrus with the slog logger
+	slog.New(logger.NewLogrusHandler(logrus.StandardLogger()))
+
+	shutdownTracing, err := tracing.Init(ctx, cfg.App, cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to init tracing", "error", err)
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush the spans", "error", err)
+		}
+	}()
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to load the auth keys", "error", err)
+
+		return
+	}
+
+	checker := health.NewChecker()
+
+	gw, cleanup, err := inprocess.Start(ctx, cfg, checker)
+	if err != nil {
+		slog.Error("failed init app", "error", err)
+
+		return
+	}
+
+	defer cleanup()
+
+	// the probes and the scrapes are neither logged nor traced
+	mux := http.NewServeMux()
+	admin.Register(mux, checker)
+	mux.Handle("/", gateway.Handler(
+		cfg.Name,
+		gw,
+		gateway.AllowedOrigins(cfg.CORS.AllowedOrigins...),
+		gateway.Authenticator(authn),
+	))
+
+	go serveHTTP(ctx, cancel, "gateway", fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port), mux)
+
+	e := echo.New()
+	e.HideBanner = true
+	app.Register(e, http.FS(app.Files), cfg.Web.ReverseProxyURL)
+
+	go serveHTTP(ctx, cancel, "web", fmt.Sprintf("%s:%d", cfg.Web.Host, cfg.Web.Port), e)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case v := <-quit:
+		slog.Info("signal.Notify", "signal", v)
+	case <-ctx.Done():
+		slog.Info("ctx.Done")
+	}
+
+	// the servers stop taking requests before the services are cleaned up
+	cancel()
+}
+
+// serveHTTP serves handler until ctx is done, the process stops when it can't listen.
+func serveHTTP(ctx context.Context, cancel context.CancelFunc, name, address string, handler http.Handler) {
+	s := &http.Server{
+		Addr:              address,
+		Handler:           handler,
+		ReadHeaderTimeout: _readHeaderTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := s.Shutdown(context.Background()); err != nil {
+			slog.Error("failed to shutdown http server", "error", err, "server", name)
+		}
+	}()
+
+	slog.Info("🌏 start server...", "server", name, "address", address)
+
+	if err := s.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to listen and serve", "error", err, "server", name)
+		cancel()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	configs "github.com/thangchung/go-coffeeshop/pkg/config"
@@ -11,17 +12,18 @@ import (
 
 type (
 	Config struct {
-		configs.App   `yaml:"app"`
-		configs.HTTP  `yaml:"http"`
-		configs.Log   `yaml:"logger"`
-		PG            `yaml:"postgres"`
-		RabbitMQ      `yaml:"rabbitmq"`
-		ProductClient `yaml:"product_client"`
-	}
-
-	PG struct {
-		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
-		DsnURL  string `env-required:"true" yaml:"dsn_url" env:"PG_DSN_URL"`
+		configs.App     `yaml:"app"`
+		configs.HTTP    `yaml:"http"`
+		configs.GRPC    `yaml:"grpc"`
+		configs.Log     `yaml:"logger"`
+		configs.Tracing `yaml:"tracing"`
+		configs.Admin   `yaml:"admin"`
+		configs.Auth    `yaml:"auth"`
+		configs.PG      `yaml:"postgres"`
+		RabbitMQ        `yaml:"rabbitmq"`
+		ProductClient   `yaml:"product_client"`
+		OrderStore      `yaml:"order_store"`
+		Reporting       `yaml:"reporting"`
 	}
 
 	RabbitMQ struct {
@@ -30,6 +32,24 @@ type (
 
 	ProductClient struct {
 		URL string `env-required:"true" yaml:"url" env:"PRODUCT_CLIENT_URL"`
+		// Timeout is the deadline of a lookup, retries included.
+		Timeout time.Duration `env-default:"5s" yaml:"timeout" env:"PRODUCT_CLIENT_TIMEOUT"`
+		TLS     configs.TLS   `yaml:"tls" env-prefix:"PRODUCT_CLIENT_TLS_"`
+		// CacheTTL is how long looked up items are used as they are, then they are refreshed in the background.
+		CacheTTL time.Duration `env-default:"30s" yaml:"cache_ttl" env:"PRODUCT_CLIENT_CACHE_TTL"`
+		// CacheMaxStale is how long looked up items are still used while the product service can't be reached.
+		CacheMaxStale time.Duration `env-default:"1h" yaml:"cache_max_stale" env:"PRODUCT_CLIENT_CACHE_MAX_STALE"`
+	}
+
+	OrderStore struct {
+		// Kind is either "postgres" (state stored in orders/line_items) or "eventsourced".
+		Kind          string `env-default:"postgres" yaml:"kind" env:"ORDER_STORE_KIND"`
+		SnapshotEvery int    `env-default:"10" yaml:"snapshot_every" env:"ORDER_STORE_SNAPSHOT_EVERY"`
+	}
+
+	Reporting struct {
+		// RefreshInterval is how often the aggregated report views are rebuilt from the orders.
+		RefreshInterval time.Duration `env-default:"1m" yaml:"refresh_interval" env:"REPORTING_REFRESH_INTERVAL"`
 	}
 )
 
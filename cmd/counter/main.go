@@ -7,35 +7,45 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/thangchung/go-coffeeshop/cmd/counter/config"
 	"github.com/thangchung/go-coffeeshop/internal/counter/app"
+	"github.com/thangchung/go-coffeeshop/internal/counter/app/router"
+	"github.com/thangchung/go-coffeeshop/pkg/admin"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcvalidate"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
 	"github.com/thangchung/go-coffeeshop/pkg/logger"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
 	"go.uber.org/automaxprocs/maxprocs"
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
 
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
-
 	_ "github.com/lib/pq"
 )
 
+// _readinessInterval is how often the gRPC health service is brought in line with the readiness checks.
+const _readinessInterval = 10 * time.Second
+
 func main() {
 	// set GOMAXPROCS
 	_, err := maxprocs.Set()
 	if err != nil {
-		slog.Error("failed set max procs", err)
+		slog.Error("failed set max procs", "error", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	cfg, err := config.NewConfig()
 	if err != nil {
-		slog.Error("failed get config", err)
+		slog.Error("failed get config", "error", err)
 	}
 
 	slog.Info("⚡ init app", "name", cfg.Name, "version", cfg.Version)
@@ -48,14 +58,75 @@ func main() {
 	// integrate Logrus with the slog logger
 	slog.New(logger.NewLogrusHandler(logrus.StandardLogger()))
 
-	server := grpc.NewServer()
+	shutdownTracing, err := tracing.Init(ctx, cfg.App, cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to init tracing", "error", err)
+		cancel()
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush the spans", "error", err)
+		}
+	}()
+
+	checker := health.NewChecker()
+
+	go admin.Serve(ctx, cfg.Admin, checker)
+
+	validation, err := grpcvalidate.UnaryServerInterceptor(cfg.Name)
+	if err != nil {
+		slog.Error("failed to load the validation rules", "error", err)
+		cancel()
+
+		return
+	}
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to load the auth keys", "error", err)
+		cancel()
+
+		return
+	}
+
+	// the callers are checked before anything else runs for them,
+	// the error mapping comes next, so it passes the statuses of the validation on as they are
+	server, healthServer, err := grpcx.NewServer(
+		grpcx.ServerConfig(cfg.GRPC),
+		grpcx.UnaryInterceptors(
+			auth.UnaryServerInterceptor(cfg.Name, authn),
+			grpcerror.UnaryServerInterceptor(cfg.Name, router.ErrorRules...),
+			validation,
+		),
+		grpcx.StreamInterceptors(auth.StreamServerInterceptor(cfg.Name, authn)),
+	)
+	if err != nil {
+		slog.Error("failed to create the gRPC server", "error", err)
+		cancel()
+
+		return
+	}
 
 	go func() {
 		defer server.GracefulStop()
 		<-ctx.Done()
+		healthServer.Shutdown()
 	}()
 
-	cleanup := prepareApp(ctx, cancel, cfg, server)
+	cleanup, err := prepareApp(ctx, cancel, cfg, server, checker)
+	if err != nil {
+		slog.Error("failed init app", "error", err)
+		cancel()
+
+		return
+	}
+
+	grpcx.InitializeMetrics(server)
+
+	go checker.Watch(ctx, _readinessInterval, healthServer)
 
 	// gRPC Server.
 	address := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port)
@@ -63,7 +134,7 @@ func main() {
 
 	l, err := net.Listen(network, address)
 	if err != nil {
-		slog.Error("failed to listen to address", err, "network", network, "address", address)
+		slog.Error("failed to listen to address", "error", err, "network", network, "address", address)
 		cancel()
 		<-ctx.Done()
 	}
@@ -72,14 +143,14 @@ func main() {
 
 	defer func() {
 		if err1 := l.Close(); err != nil {
-			slog.Error("failed to close", err1, "network", network, "address", address)
+			slog.Error("failed to close", "error", err1, "network", network, "address", address)
 			<-ctx.Done()
 		}
 	}()
 
 	err = server.Serve(l)
 	if err != nil {
-		slog.Error("failed start gRPC server", err, "network", network, "address", address)
+		slog.Error("failed start gRPC server", "error", err, "network", network, "address", address)
 		cancel()
 		<-ctx.Done()
 	}
@@ -97,41 +168,48 @@ func main() {
 	}
 }
 
-func prepareApp(ctx context.Context, cancel context.CancelFunc, cfg *config.Config, server *grpc.Server) func() {
+func prepareApp(
+	ctx context.Context,
+	cancel context.CancelFunc,
+	cfg *config.Config,
+	server *grpc.Server,
+	checker *health.Checker,
+) (func(), error) {
 	a, cleanup, err := app.InitApp(cfg, postgres.DBConnString(cfg.PG.DsnURL), rabbitmq.RabbitMQConnStr(cfg.RabbitMQ.URL), server)
 	if err != nil {
-		slog.Error("failed init app", err)
-		cancel()
-		<-ctx.Done()
+		return nil, err
 	}
 
-	a.BaristaOrderPub.Configure(
-		pkgPublisher.ExchangeName("barista-order-exchange"),
-		pkgPublisher.BindingKey("barista-order-routing-key"),
-		pkgPublisher.MessageTypeName("barista-order-created"),
-	)
-
-	a.KitchenOrderPub.Configure(
-		pkgPublisher.ExchangeName("kitchen-order-exchange"),
-		pkgPublisher.BindingKey("kitchen-order-routing-key"),
-		pkgPublisher.MessageTypeName("kitchen-order-created"),
-	)
+	a.RegisterChecks(checker)
 
-	a.Consumer.Configure(
-		pkgConsumer.ExchangeName("counter-order-exchange"),
-		pkgConsumer.QueueName("counter-order-queue"),
-		pkgConsumer.BindingKey("counter-order-routing-key"),
-		pkgConsumer.ConsumerTag("counter-order-consumer"),
-	)
+	a.ConfigureRoutes()
 
 	go func() {
-		err1 := a.Consumer.StartConsumer(a.Worker)
+		err1 := a.Consumer.Subscribe(a.Worker)
 		if err1 != nil {
-			slog.Error("failed to start Consumer", err1)
+			slog.Error("failed to start Consumer", "error", err1)
 			cancel()
 			<-ctx.Done()
 		}
 	}()
 
-	return cleanup
+	catalogConsumer, err := a.NewCatalogConsumer()
+	if err != nil {
+		cleanup()
+
+		return nil, errors.Wrap(err, "failed to create the catalog Consumer")
+	}
+
+	checker.Register("catalog-consumer", catalogConsumer.Check)
+
+	go func() {
+		err1 := catalogConsumer.Subscribe(a.Worker)
+		if err1 != nil {
+			slog.Error("failed to start the catalog Consumer", "error", err1)
+		}
+	}()
+
+	go a.RefreshReports(ctx, cfg.Reporting.RefreshInterval)
+
+	return cleanup, nil
 }
@@ -11,16 +11,14 @@ import (
 
 type (
 	Config struct {
-		configs.App  `yaml:"app"`
-		configs.HTTP `yaml:"http"`
-		configs.Log  `yaml:"logger"`
-		PG           `yaml:"postgres"`
-		RabbitMQ     `yaml:"rabbitmq"`
-	}
-
-	PG struct {
-		PoolMax int    `env-required:"true" yaml:"pool_max" env:"PG_POOL_MAX"`
-		DsnURL  string `env-required:"true" yaml:"dsn_url" env:"PG_DSN_URL"`
+		configs.App         `yaml:"app"`
+		configs.HTTP        `yaml:"http"`
+		configs.Log         `yaml:"logger"`
+		configs.Tracing     `yaml:"tracing"`
+		configs.Admin       `yaml:"admin"`
+		configs.PG          `yaml:"postgres"`
+		configs.Preparation `yaml:"preparation"`
+		RabbitMQ            `yaml:"rabbitmq"`
 	}
 
 	RabbitMQ struct {
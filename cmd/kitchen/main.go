@@ -10,15 +10,15 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
 	"github.com/thangchung/go-coffeeshop/internal/kitchen/app"
+	"github.com/thangchung/go-coffeeshop/pkg/admin"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
 	"github.com/thangchung/go-coffeeshop/pkg/logger"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
 	"go.uber.org/automaxprocs/maxprocs"
 	"golang.org/x/exp/slog"
 
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
-
 	_ "github.com/lib/pq"
 )
 
@@ -26,14 +26,14 @@ func main() {
 	// set GOMAXPROCS
 	_, err := maxprocs.Set()
 	if err != nil {
-		slog.Error("failed set max procs", err)
+		slog.Error("failed set max procs", "error", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	cfg, err := config.NewConfig()
 	if err != nil {
-		slog.Error("failed get config", err)
+		slog.Error("failed get config", "error", err)
 	}
 
 	slog.Info("⚡ init app", "name", cfg.Name, "version", cfg.Version)
@@ -46,31 +46,42 @@ func main() {
 	// integrate Logrus with the slog logger
 	slog.New(logger.NewLogrusHandler(logrus.StandardLogger()))
 
+	shutdownTracing, err := tracing.Init(ctx, cfg.App, cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to init tracing", "error", err)
+		cancel()
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush the spans", "error", err)
+		}
+	}()
+
+	checker := health.NewChecker()
+
+	go admin.Serve(ctx, cfg.Admin, checker)
+
 	a, cleanup, err := app.InitApp(cfg, postgres.DBConnString(cfg.PG.DsnURL), rabbitmq.RabbitMQConnStr(cfg.RabbitMQ.URL))
 	if err != nil {
-		slog.Error("failed init app", err)
+		slog.Error("failed init app", "error", err)
 		cancel()
+
+		return
 	}
 
-	a.CounterOrderPub.Configure(
-		pkgPublisher.ExchangeName("counter-order-exchange"),
-		pkgPublisher.BindingKey("counter-order-routing-key"),
-		pkgPublisher.MessageTypeName("kitchen-order-updated"),
-	)
+	a.RegisterChecks(checker)
 
-	a.Consumer.Configure(
-		pkgConsumer.ExchangeName("kitchen-order-exchange"),
-		pkgConsumer.QueueName("kitchen-order-queue"),
-		pkgConsumer.BindingKey("kitchen-order-routing-key"),
-		pkgConsumer.ConsumerTag("kitchen-order-consumer"),
-	)
+	a.ConfigureRoutes()
 
 	slog.Info("🌏 start server...", "address", fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port))
 
 	go func() {
-		err := a.Consumer.StartConsumer(a.Worker)
+		err := a.Consumer.Subscribe(a.Worker)
 		if err != nil {
-			slog.Error("failed to start Consumer", err)
+			slog.Error("failed to start Consumer", "error", err)
 			cancel()
 		}
 	}()
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	transportHTTP = "http"
+	transportGRPC = "grpc"
+
+	_maxErrorBody = 512
+)
+
+// counterClient is the part of the counter service the load goes through.
+type counterClient interface {
+	PlaceOrder(context.Context, *gen.PlaceOrderRequest) (string, error)
+	GetOrderTimeline(ctx context.Context, orderID string) ([]*gen.TimelineEntryDto, error)
+	Close() error
+}
+
+func newClient(opts *options) (counterClient, error) {
+	switch opts.transport {
+	case transportHTTP:
+		return newHTTPClient(opts.addr, opts.concurrency, credentialsOf(opts)), nil
+	case transportGRPC:
+		return newGRPCClient(opts.addr, opts.caFile, credentialsOf(opts))
+	default:
+		return nil, errors.Errorf("unknown transport %q, want %s or %s", opts.transport, transportHTTP, transportGRPC)
+	}
+}
+
+// credentialsOf tells the services who places the orders, the orders are anonymous without -token or -api-key.
+func credentialsOf(opts *options) auth.Credentials {
+	creds := auth.Credentials{APIKey: opts.apiKey}
+	if opts.token != "" {
+		creds.Authorization = "Bearer " + opts.token
+	}
+
+	return creds
+}
+
+// httpClient goes through the JSON API of the gateway.
+type httpClient struct {
+	baseURL string
+	client  *http.Client
+	creds   auth.Credentials
+}
+
+func newHTTPClient(baseURL string, concurrency int, creds auth.Credentials) *httpClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// every worker keeps its connection instead of dialing a new one per order
+	transport.MaxIdleConnsPerHost = concurrency
+
+	return &httpClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Transport: transport},
+		creds:   creds,
+	}
+}
+
+func (c *httpClient) PlaceOrder(ctx context.Context, request *gen.PlaceOrderRequest) (string, error) {
+	body, err := protojson.Marshal(request)
+	if err != nil {
+		return "", errors.Wrap(err, "protojson.Marshal")
+	}
+
+	res := gen.PlaceOrderResponse{}
+	if err := c.do(ctx, http.MethodPost, "/v1/api/orders", body, &res); err != nil {
+		return "", err
+	}
+
+	return res.GetId(), nil
+}
+
+func (c *httpClient) GetOrderTimeline(ctx context.Context, orderID string) ([]*gen.TimelineEntryDto, error) {
+	res := gen.GetOrderTimelineResponse{}
+	if err := c.do(ctx, http.MethodGet, "/v1/api/orders/"+url.PathEscape(orderID)+"/timeline", nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res.GetEntries(), nil
+}
+
+func (c *httpClient) Close() error {
+	c.client.CloseIdleConnections()
+
+	return nil
+}
+
+func (c *httpClient) do(ctx context.Context, method, path string, body []byte, reply proto.Message) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "http.NewRequest")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.creds.Authorization != "" {
+		req.Header.Set(auth.HeaderAuthorization, c.creds.Authorization)
+	}
+
+	if c.creds.APIKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, c.creds.APIKey)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return errors.Wrap(err, "io.ReadAll")
+	}
+
+	if res.StatusCode != http.StatusOK {
+		if len(data) > _maxErrorBody {
+			data = data[:_maxErrorBody]
+		}
+
+		return &statusError{code: res.StatusCode, body: string(data)}
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, reply)
+}
+
+// statusError is an answer of the gateway other than 200.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.code, http.StatusText(e.code), e.body)
+}
+
+// grpcClient calls the counter service directly.
+type grpcClient struct {
+	conn    *grpc.ClientConn
+	counter gen.CounterServiceClient
+	creds   auth.Credentials
+}
+
+func newGRPCClient(addr, caFile string, creds auth.Credentials) (*grpcClient, error) {
+	tls := configs.TLS{Enabled: caFile != "", CAFile: caFile}
+
+	// the calls get the deadline of the load generator, not a default one
+	conn, err := grpcx.Dial(addr, grpcx.ClientTLS(tls), grpcx.CallTimeout(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &grpcClient{
+		conn:    conn,
+		counter: gen.NewCounterServiceClient(conn),
+		creds:   creds,
+	}, nil
+}
+
+func (c *grpcClient) PlaceOrder(ctx context.Context, request *gen.PlaceOrderRequest) (string, error) {
+	res, err := c.counter.PlaceOrder(auth.WithCredentials(ctx, c.creds), request)
+	if err != nil {
+		return "", err
+	}
+
+	return res.GetId(), nil
+}
+
+func (c *grpcClient) GetOrderTimeline(ctx context.Context, orderID string) ([]*gen.TimelineEntryDto, error) {
+	res, err := c.counter.GetOrderTimeline(auth.WithCredentials(ctx, c.creds), &gen.GetOrderTimelineRequest{OrderId: orderID})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.GetEntries(), nil
+}
+
+func (c *grpcClient) Close() error {
+	return c.conn.Close()
+}
+
+// errorKind groups the failures of a run by what the service answered,
+// the messages themselves carry order details which would make every failure its own kind.
+func errorKind(err error) string {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("HTTP %d %s", statusErr.code, http.StatusText(statusErr.code))
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return "gRPC " + s.Code().String()
+	}
+
+	return "transport error"
+}
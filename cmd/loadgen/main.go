@@ -0,0 +1,257 @@
+// Loadgen places orders at the counter, through the gateway or over gRPC, and reports how it kept up.
+// The orders are made up from a mix of items and locations, or replayed from a file with one PlaceOrderRequest
+// per line in the JSON the gateway takes.
+//
+//	loadgen -rate 50 -concurrency 20 -duration 1m -items LATTE=3,CROISSANT
+//	loadgen -transport grpc -addr localhost:5002 -replay orders.jsonl
+//	loadgen -api-key $CASHIER_KEY -duration 1m
+//
+// The report has the latency histogram and the errors of placing the orders, and the fulfilment latency of the orders
+// it follows by polling their timelines. A run ends after -duration, after -orders, at the end of the replay or on
+// an interrupt, whichever comes first.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+)
+
+const (
+	_defaultGatewayAddr = "http://localhost:5000"
+	_defaultCounterAddr = "localhost:5002"
+)
+
+type options struct {
+	transport string
+	addr      string
+	caFile    string
+	token     string
+	apiKey    string
+
+	rate        float64
+	concurrency int
+	duration    time.Duration
+	orders      int
+	timeout     time.Duration
+
+	replay        string
+	items         string
+	locations     string
+	itemsPerOrder string
+	seed          int64
+
+	track             float64
+	pollInterval      time.Duration
+	fulfilmentTimeout time.Duration
+}
+
+func main() {
+	opts := &options{}
+
+	flag.StringVar(&opts.transport, "transport", transportHTTP, "place the orders through the gateway (http) or at the counter service (grpc)")
+	flag.StringVar(&opts.addr, "addr", "", "the gateway URL or the counter address, "+_defaultGatewayAddr+" or "+_defaultCounterAddr+" by default")
+	flag.StringVar(&opts.caFile, "ca", "", "the CA of the counter service, gRPC goes over TLS when it is set")
+	flag.StringVar(&opts.token, "token", "", "a JWT of a cashier, sent as a bearer token when the services check their callers")
+	flag.StringVar(&opts.apiKey, "api-key", "", "an API key with the cashier role, instead of -token")
+	flag.Float64Var(&opts.rate, "rate", 10, "orders per second, 0 sends them as fast as the workers can")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "the number of orders in flight")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "how long to send orders, 0 for no limit")
+	flag.IntVar(&opts.orders, "orders", 0, "stop after this many orders, 0 for no limit")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "the deadline of every call")
+	flag.StringVar(&opts.replay, "replay", "", "a file of orders to replay instead of made up ones, - for stdin")
+	flag.StringVar(&opts.items, "items", "", "the item mix, like LATTE=3,CROISSANT=1, all the items evenly by default")
+	flag.StringVar(&opts.locations, "locations", "", "the location mix, like ATLANTA=2,RALEIGH=1, all the locations evenly by default")
+	flag.StringVar(&opts.itemsPerOrder, "items-per-order", "1-3", "the number of items of an order, or a range of it")
+	flag.Int64Var(&opts.seed, "seed", time.Now().UnixNano(), "the seed of the made up orders, the same seed gives the same orders")
+	flag.Float64Var(&opts.track, "track", 1, "the fraction of the orders to follow until fulfilled, 0 turns it off")
+	flag.DurationVar(&opts.pollInterval, "poll-interval", 500*time.Millisecond, "how often the timeline of a followed order is polled")
+	flag.DurationVar(&opts.fulfilmentTimeout, "fulfilment-timeout", 2*time.Minute, "how long to follow an order before counting it as not fulfilled")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := run(ctx, opts, os.Stdout)
+	stop()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "loadgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, opts *options, out io.Writer) error {
+	if err := validate(opts); err != nil {
+		return err
+	}
+
+	source, closeSource, err := newSource(opts)
+	if err != nil {
+		return err
+	}
+	defer closeSource()
+
+	client, err := newClient(opts)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	st := newStats()
+	tr := newTracker(client, st, opts)
+
+	loadCtx := ctx
+	if opts.duration > 0 {
+		var cancel context.CancelFunc
+		loadCtx, cancel = context.WithTimeout(ctx, opts.duration)
+
+		defer cancel()
+	}
+
+	requests := make(chan *gen.PlaceOrderRequest)
+	produced := make(chan error, 1)
+
+	go func() {
+		produced <- produce(loadCtx, source, opts, requests)
+	}()
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for request := range requests {
+				// an order sent before the end of the run gets its answer, only an interrupt cancels it
+				callCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+				sentAt := time.Now()
+
+				orderID, err := client.PlaceOrder(callCtx, request)
+
+				cancel()
+				st.placed(time.Since(sentAt), err)
+
+				if err == nil && opts.track > 0 && tr.sample() {
+					tr.follow(ctx, orderID)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+
+	if opts.track > 0 {
+		fmt.Fprintln(out, "waiting for the followed orders to be fulfilled")
+	}
+
+	tr.wait()
+
+	if err := st.report(out, elapsed, opts.track > 0); err != nil {
+		return err
+	}
+
+	// the orders before a broken line of a replay were sent and are in the report
+	return <-produced
+}
+
+func validate(opts *options) error {
+	switch {
+	case opts.rate < 0:
+		return errors.New("-rate can't be negative")
+	case opts.concurrency < 1:
+		return errors.New("-concurrency must be at least 1")
+	case opts.track < 0 || opts.track > 1:
+		return errors.New("-track is a fraction from 0 to 1")
+	case opts.track > 0 && opts.pollInterval <= 0:
+		return errors.New("-poll-interval must be positive")
+	case opts.duration == 0 && opts.orders == 0 && opts.replay == "":
+		return errors.New("a run needs a -duration, a number of -orders or a -replay file to end")
+	}
+
+	if opts.token != "" && opts.apiKey != "" {
+		return errors.New("-token and -api-key can't be used together")
+	}
+
+	if opts.addr == "" {
+		opts.addr = _defaultGatewayAddr
+		if opts.transport == transportGRPC {
+			opts.addr = _defaultCounterAddr
+		}
+	}
+
+	return nil
+}
+
+func newSource(opts *options) (orderSource, func(), error) {
+	switch opts.replay {
+	case "":
+		source, err := newSyntheticOrders(opts)
+
+		return source, func() {}, err
+	case "-":
+		return newReplayedOrders(os.Stdin), func() {}, nil
+	default:
+		f, err := os.Open(opts.replay)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "os.Open")
+		}
+
+		return newReplayedOrders(f), func() { f.Close() }, nil
+	}
+}
+
+// produce hands the orders of source to the workers at the rate of the run until loadCtx is done.
+// A worker which is still busy at a tick makes the run fall behind the rate instead of queueing the orders up.
+func produce(loadCtx context.Context, source orderSource, opts *options, requests chan<- *gen.PlaceOrderRequest) error {
+	defer close(requests)
+
+	var tick <-chan time.Time
+
+	if opts.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+		defer ticker.Stop()
+
+		tick = ticker.C
+	}
+
+	for sent := 0; opts.orders == 0 || sent < opts.orders; sent++ {
+		if tick != nil {
+			select {
+			case <-loadCtx.Done():
+				return nil
+			case <-tick:
+			}
+		}
+
+		request, ok, err := source.Next()
+		if err != nil {
+			return errors.Wrap(err, "source.Next")
+		}
+
+		if !ok {
+			return nil
+		}
+
+		select {
+		case <-loadCtx.Done():
+			return nil
+		case requests <- request:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// _maxLine is the longest order a replay file can have.
+const _maxLine = 1 << 20
+
+// orderSource hands out the orders of a run, ok is false when it has no more.
+type orderSource interface {
+	Next() (request *gen.PlaceOrderRequest, ok bool, err error)
+}
+
+// weighted picks one of its values in proportion to their weights.
+type weighted struct {
+	values  []int32
+	weights []int
+	total   int
+}
+
+// parseWeights reads a mix like "LATTE=3,CROISSANT" of enum names or numbers, a value without a weight counts once.
+// An empty spec mixes all the names evenly.
+func parseWeights(spec string, names map[string]int32) (*weighted, error) {
+	w := &weighted{}
+
+	if strings.TrimSpace(spec) == "" {
+		values := make([]int32, 0, len(names))
+		for _, value := range names {
+			values = append(values, value)
+		}
+
+		// the order of a map changes from run to run, a seed has to give the same orders every time
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+
+		for _, value := range values {
+			w.add(value, 1)
+		}
+
+		return w, nil
+	}
+
+	for _, part := range strings.Split(spec, ",") {
+		name, weightText, hasWeight := strings.Cut(part, "=")
+		name, weightText = strings.TrimSpace(name), strings.TrimSpace(weightText)
+
+		value, ok := names[strings.ToUpper(name)]
+		if !ok {
+			number, err := strconv.ParseInt(name, 10, 32)
+			if err != nil {
+				return nil, errors.Errorf("%q is not a known name or a number", name)
+			}
+
+			value = int32(number)
+		}
+
+		weight := 1
+
+		if hasWeight {
+			var err error
+			if weight, err = strconv.Atoi(weightText); err != nil || weight < 0 {
+				return nil, errors.Errorf("the weight of %s must be a whole number, not %q", name, weightText)
+			}
+		}
+
+		w.add(value, weight)
+	}
+
+	if w.total == 0 {
+		return nil, errors.Errorf("%q has no weight", spec)
+	}
+
+	return w, nil
+}
+
+func (w *weighted) add(value int32, weight int) {
+	w.values = append(w.values, value)
+	w.weights = append(w.weights, weight)
+	w.total += weight
+}
+
+func (w *weighted) pick(rnd *rand.Rand) int32 {
+	n := rnd.Intn(w.total)
+
+	for i, weight := range w.weights {
+		if n < weight {
+			return w.values[i]
+		}
+
+		n -= weight
+	}
+
+	return w.values[len(w.values)-1]
+}
+
+// syntheticOrders makes up orders from a mix of items and locations, for a new loyalty member each.
+type syntheticOrders struct {
+	rnd       *rand.Rand
+	items     *weighted
+	locations *weighted
+	minItems  int
+	maxItems  int
+}
+
+func newSyntheticOrders(opts *options) (*syntheticOrders, error) {
+	items, err := parseWeights(opts.items, gen.ItemType_value)
+	if err != nil {
+		return nil, errors.Wrap(err, "-items")
+	}
+
+	locations, err := parseWeights(opts.locations, gen.Location_value)
+	if err != nil {
+		return nil, errors.Wrap(err, "-locations")
+	}
+
+	minItems, maxItems, err := parseRange(opts.itemsPerOrder)
+	if err != nil {
+		return nil, errors.Wrap(err, "-items-per-order")
+	}
+
+	return &syntheticOrders{
+		rnd:       rand.New(rand.NewSource(opts.seed)),
+		items:     items,
+		locations: locations,
+		minItems:  minItems,
+		maxItems:  maxItems,
+	}, nil
+}
+
+func (s *syntheticOrders) Next() (*gen.PlaceOrderRequest, bool, error) {
+	request := &gen.PlaceOrderRequest{
+		CommandType:     int32(shared.CommandTypePlaceOrder),
+		OrderSource:     int32(shared.OrderSourceCounter),
+		Location:        s.locations.pick(s.rnd),
+		LoyaltyMemberId: uuid.NewString(),
+		Timestamp:       timestamppb.New(time.Now()),
+	}
+
+	count := s.minItems + s.rnd.Intn(s.maxItems-s.minItems+1)
+
+	for i := 0; i < count; i++ {
+		itemType := s.items.pick(s.rnd)
+		item := &gen.CommandItem{ItemType: itemType}
+
+		if isBaristaItem(itemType) {
+			request.BaristaItems = append(request.BaristaItems, item)
+		} else {
+			request.KitchenItems = append(request.KitchenItems, item)
+		}
+	}
+
+	return request, true, nil
+}
+
+// isBaristaItem tells the drinks from the food of the standard catalog,
+// item types past it go to the kitchen.
+func isBaristaItem(itemType int32) bool {
+	return itemType >= int32(shared.ItemTypeCappuccino) && itemType <= int32(shared.ItemTypeLatte)
+}
+
+// parseRange reads "2" or "1-3".
+func parseRange(spec string) (low, high int, err error) {
+	lowText, highText, isRange := strings.Cut(spec, "-")
+	if !isRange {
+		highText = lowText
+	}
+
+	if low, err = strconv.Atoi(strings.TrimSpace(lowText)); err != nil {
+		return 0, 0, errors.Errorf("%q is not a number or a range like 1-3", spec)
+	}
+
+	if high, err = strconv.Atoi(strings.TrimSpace(highText)); err != nil {
+		return 0, 0, errors.Errorf("%q is not a number or a range like 1-3", spec)
+	}
+
+	if low < 1 || high < low {
+		return 0, 0, errors.Errorf("%q must be at least 1 and go up", spec)
+	}
+
+	return low, high, nil
+}
+
+// replayedOrders reads one PlaceOrderRequest per line, in the JSON the gateway takes.
+// Blank lines and lines starting with # are skipped.
+type replayedOrders struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newReplayedOrders(r io.Reader) *replayedOrders {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, _maxLine)
+
+	return &replayedOrders{scanner: scanner}
+}
+
+func (r *replayedOrders) Next() (*gen.PlaceOrderRequest, bool, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		request := &gen.PlaceOrderRequest{}
+		if err := protojson.Unmarshal([]byte(line), request); err != nil {
+			return nil, false, errors.Wrapf(err, "line %d", r.line)
+		}
+
+		return request, true, nil
+	}
+
+	return nil, false, errors.Wrap(r.scanner.Err(), "bufio.Scanner")
+}
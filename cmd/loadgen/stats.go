@@ -0,0 +1,263 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const _barWidth = 40
+
+// _buckets are the upper bounds of the histograms, the last one takes everything slower.
+var _buckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// stats collects the outcome of a run, the workers and the trackers record into it concurrently.
+type stats struct {
+	mu sync.Mutex
+
+	placeLatencies []time.Duration
+	errors         map[string]int
+	failed         int
+
+	tracked             int
+	fulfilmentLatencies []time.Duration
+	unfulfilled         int
+	pollErrors          map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		errors:     map[string]int{},
+		pollErrors: map[string]int{},
+	}
+}
+
+// placed records one PlaceOrder call, failed ones count by the kind of error.
+func (s *stats) placed(latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.failed++
+		s.errors[errorKind(err)]++
+
+		return
+	}
+
+	s.placeLatencies = append(s.placeLatencies, latency)
+}
+
+func (s *stats) trackStarted() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracked++
+}
+
+func (s *stats) fulfilled(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fulfilmentLatencies = append(s.fulfilmentLatencies, latency)
+}
+
+func (s *stats) notFulfilled() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unfulfilled++
+}
+
+func (s *stats) pollFailed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pollErrors[errorKind(err)]++
+}
+
+// report writes the summary of a run which sent its orders during elapsed.
+func (s *stats) report(out io.Writer, elapsed time.Duration, tracking bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	ok := len(s.placeLatencies)
+	sent := ok + s.failed
+
+	fmt.Fprintf(w, "orders\t%d sent in %s, %.1f/s\n", sent, elapsed.Round(time.Millisecond), perSecond(sent, elapsed))
+	fmt.Fprintf(w, "placed\t%d, %.1f/s\n", ok, perSecond(ok, elapsed))
+	fmt.Fprintf(w, "failed\t%d, %.2f%%\n", s.failed, percent(s.failed, sent))
+	writeCounts(w, s.errors)
+
+	fmt.Fprintln(w, "\nplace order latency")
+	writeLatencies(w, s.placeLatencies)
+
+	if tracking {
+		fmt.Fprintln(w, "\nfulfilment latency, from the order being placed to its last item completed")
+		fmt.Fprintf(w, "tracked\t%d\n", s.tracked)
+		fmt.Fprintf(w, "fulfilled\t%d, %.2f%%\n", len(s.fulfilmentLatencies), percent(len(s.fulfilmentLatencies), s.tracked))
+		fmt.Fprintf(w, "not fulfilled\t%d\n", s.unfulfilled)
+
+		if len(s.pollErrors) > 0 {
+			fmt.Fprintln(w, "poll errors")
+			writeCounts(w, s.pollErrors)
+		}
+
+		writeLatencies(w, s.fulfilmentLatencies)
+	}
+
+	return w.Flush()
+}
+
+func writeCounts(w io.Writer, counts map[string]int) {
+	kinds := make([]string, 0, len(counts))
+	for kind := range counts {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		fmt.Fprintf(w, "  %s\t%d\n", kind, counts[kind])
+	}
+}
+
+func writeLatencies(w io.Writer, latencies []time.Duration) {
+	if len(latencies) == 0 {
+		fmt.Fprintln(w, "  no samples")
+
+		return
+	}
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	fmt.Fprintf(w, "  min %s\tp50 %s\tp90 %s\tp95 %s\tp99 %s\tmax %s\n",
+		round(sorted[0]),
+		round(quantile(sorted, 0.5)),
+		round(quantile(sorted, 0.9)),
+		round(quantile(sorted, 0.95)),
+		round(quantile(sorted, 0.99)),
+		round(sorted[len(sorted)-1]),
+	)
+
+	counts := histogram(sorted)
+
+	largest := 0
+	for _, count := range counts {
+		if count > largest {
+			largest = count
+		}
+	}
+
+	// the buckets outside of the samples would only add empty lines
+	first, last := firstNonZero(counts), lastNonZero(counts)
+
+	for i := first; i <= last; i++ {
+		label := "> " + _buckets[len(_buckets)-1].String()
+		if i < len(_buckets) {
+			label = "<= " + _buckets[i].String()
+		}
+
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", label, counts[i], strings.Repeat("#", counts[i]*_barWidth/largest))
+	}
+}
+
+// histogram counts sorted latencies into _buckets and one more for the slower ones.
+func histogram(sorted []time.Duration) []int {
+	counts := make([]int, len(_buckets)+1)
+
+	bucket := 0
+	for _, latency := range sorted {
+		for bucket < len(_buckets) && latency > _buckets[bucket] {
+			bucket++
+		}
+
+		counts[bucket]++
+	}
+
+	return counts
+}
+
+// quantile is the nearest-rank q quantile of sorted.
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+func firstNonZero(counts []int) int {
+	for i, count := range counts {
+		if count > 0 {
+			return i
+		}
+	}
+
+	return len(counts)
+}
+
+func lastNonZero(counts []int) int {
+	for i := len(counts) - 1; i >= 0; i-- {
+		if counts[i] > 0 {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
+
+func perSecond(n int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(n) / elapsed.Seconds()
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return 100 * float64(n) / float64(total)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+)
+
+// tracker follows placed orders through their timelines until every item is completed.
+// The polls of all orders share the concurrency of the run, so following them can't load the counter more than
+// placing them.
+type tracker struct {
+	client   counterClient
+	stats    *stats
+	fraction float64
+	interval time.Duration
+	timeout  time.Duration
+	callTime time.Duration
+
+	polls chan struct{}
+	wg    sync.WaitGroup
+
+	mu     sync.Mutex
+	placed int
+}
+
+func newTracker(client counterClient, stats *stats, opts *options) *tracker {
+	return &tracker{
+		client:   client,
+		stats:    stats,
+		fraction: opts.track,
+		interval: opts.pollInterval,
+		timeout:  opts.fulfilmentTimeout,
+		callTime: opts.timeout,
+		polls:    make(chan struct{}, opts.concurrency),
+	}
+}
+
+// sample tells whether the next placed order is followed, a fraction of 0.25 takes every fourth one.
+func (t *tracker) sample() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.placed++
+
+	return int(float64(t.placed)*t.fraction) > int(float64(t.placed-1)*t.fraction)
+}
+
+// follow polls the timeline of the order in the background until it is fulfilled, timed out or ctx is done.
+func (t *tracker) follow(ctx context.Context, orderID string) {
+	t.stats.trackStarted()
+	t.wg.Add(1)
+
+	go func() {
+		defer t.wg.Done()
+
+		deadline := time.Now().Add(t.timeout)
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				t.stats.notFulfilled()
+
+				return
+			case <-ticker.C:
+			}
+
+			if latency, ok := t.poll(ctx, orderID); ok {
+				t.stats.fulfilled(latency)
+
+				return
+			}
+
+			if time.Now().After(deadline) {
+				t.stats.notFulfilled()
+
+				return
+			}
+		}
+	}()
+}
+
+// wait returns once every followed order has an outcome.
+func (t *tracker) wait() {
+	t.wg.Wait()
+}
+
+func (t *tracker) poll(ctx context.Context, orderID string) (time.Duration, bool) {
+	select {
+	case <-ctx.Done():
+		return 0, false
+	case t.polls <- struct{}{}:
+	}
+
+	defer func() { <-t.polls }()
+
+	ctx, cancel := context.WithTimeout(ctx, t.callTime)
+	defer cancel()
+
+	entries, err := t.client.GetOrderTimeline(ctx, orderID)
+	if err != nil {
+		t.stats.pollFailed(err)
+
+		return 0, false
+	}
+
+	return fulfilledIn(entries)
+}
+
+// fulfilledIn is the time from the order being placed to its last item completed, by the clock of the counter.
+// ok is false while an item isn't completed.
+func fulfilledIn(entries []*gen.TimelineEntryDto) (latency time.Duration, ok bool) {
+	var placed, last time.Time
+
+	items := map[string]bool{}
+
+	for _, entry := range entries {
+		if _, seen := items[entry.GetLineItemId()]; !seen {
+			items[entry.GetLineItemId()] = false
+		}
+
+		at := entry.GetOccurredAt().AsTime()
+
+		switch domain.TimelineStage(entry.GetStage()) {
+		case domain.TimelineStagePlaced:
+			if placed.IsZero() || at.Before(placed) {
+				placed = at
+			}
+		case domain.TimelineStageCompleted:
+			items[entry.GetLineItemId()] = true
+
+			if at.After(last) {
+				last = at
+			}
+		}
+	}
+
+	if len(items) == 0 || placed.IsZero() {
+		return 0, false
+	}
+
+	for _, completed := range items {
+		if !completed {
+			return 0, false
+		}
+	}
+
+	return last.Sub(placed), true
+}
@@ -0,0 +1,183 @@
+// Migrate applies the embedded migrations of one service to its database.
+//
+//	migrate -service counter up [N]
+//	migrate -service counter down N | -all
+//	migrate -service counter goto V
+//	migrate -service counter version
+//	migrate -service counter force V
+//
+// The database defaults to PG_DSN_URL and the driver to PG_DRIVER, the same variables the services read.
+// With -driver sqlite the database is a file and the SQLite set of the service is applied.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+const _defaultDsnURL = "host=127.0.0.1 user=postgres password=P@ssw0rd dbname=postgres sslmode=disable"
+
+var errUsage = errors.New("usage")
+
+func main() {
+	service := flag.String("service", "", "the migration set to run: "+strings.Join(migrations.Services(), ", "))
+	dsnURL := flag.String("database", lookupEnv("PG_DSN_URL", _defaultDsnURL), "the postgres connection string or the sqlite file")
+	driver := flag.String("driver", lookupEnv("PG_DRIVER", postgres.DriverPostgres), "the database driver: postgres, pgx or sqlite")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(*driver, *service, postgres.DBConnString(*dsnURL), flag.Args()); err != nil {
+		if errors.Is(err, errUsage) {
+			usage()
+			os.Exit(2)
+		}
+
+		fmt.Fprintf(os.Stderr, "migrate %s: %v\n", *service, err)
+		os.Exit(1)
+	}
+}
+
+func run(driver, service string, dsnURL postgres.DBConnString, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	// the source is resolved first, so a wrong service fails before connecting
+	if _, err := migrations.Source(service); err != nil {
+		return err
+	}
+
+	m, closeMigrate, err := newMigrate(driver, service, dsnURL)
+	if err != nil {
+		return err
+	}
+	defer closeMigrate()
+
+	switch cmd, rest := args[0], args[1:]; cmd {
+	case "up":
+		if len(rest) == 0 {
+			err = m.Up()
+		} else {
+			err = steps(m, rest, 1)
+		}
+	case "down":
+		if len(rest) == 1 && rest[0] == "-all" {
+			err = m.Down()
+		} else {
+			// no default here, "down" alone would drop the whole schema
+			err = steps(m, rest, -1)
+		}
+	case "goto":
+		var v uint64
+		if v, err = parseArg(rest); err == nil {
+			err = m.Migrate(uint(v))
+		}
+	case "force":
+		var v uint64
+		if v, err = parseArg(rest); err == nil {
+			err = m.Force(int(v))
+		}
+	case "version":
+	default:
+		return errUsage
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		fmt.Println("no change")
+	} else if err != nil {
+		return err
+	}
+
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("no migration applied")
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: version %d, dirty %t\n", service, version, dirty)
+
+	return nil
+}
+
+// newMigrate connects over one connection, the returned func closes the migrate, then the database
+// and unregisters the metrics of its pool.
+func newMigrate(driver, service string, dsnURL postgres.DBConnString) (*migrate.Migrate, func(), error) {
+	newMigrateOf, newDB := migrations.New, postgres.NewPostgresDB
+	if driver == postgres.DriverSQLite {
+		newMigrateOf, newDB = migrations.NewSQLite, postgres.NewSQLiteDB
+	}
+
+	pg, err := newDB(dsnURL, postgres.MaxOpenConns(1), postgres.Name("migrate"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := newMigrateOf(service, pg.GetDB())
+	if err != nil {
+		pg.Close()
+
+		return nil, nil, err
+	}
+
+	return m, func() {
+		m.Close()
+		pg.Close()
+	}, nil
+}
+
+func steps(m *migrate.Migrate, args []string, sign int) error {
+	n, err := parseArg(args)
+	if err != nil {
+		return err
+	}
+
+	return m.Steps(sign * int(n))
+}
+
+func parseArg(args []string) (uint64, error) {
+	if len(args) != 1 {
+		return 0, errUsage
+	}
+
+	n, err := strconv.ParseUint(args[0], 10, 32)
+	if err != nil {
+		return 0, errUsage
+	}
+
+	return n, nil
+}
+
+func lookupEnv(key, fallback string) string {
+	if v, ok := os.LookupEnv(key); ok && v != "" {
+		return v
+	}
+
+	return fallback
+}
+
+func usage() {
+	fmt.Fprintf(flag.CommandLine.Output(), `usage: migrate -service <name> [-driver <driver>] [-database <dsn>] <command>
+
+commands:
+  up [N]       apply all or the next N migrations
+  down N|-all  revert the last N or all migrations
+  goto V       migrate up or down to version V
+  version      print the current version
+  force V      set the version without running anything, to recover a dirty database
+
+flags:
+`)
+	flag.PrintDefaults()
+}
@@ -0,0 +1,35 @@
+// Probe asks a health endpoint of a service and exits with 0 when it answers 200.
+// The images have no shell or curl, the container health checks run it instead.
+//
+//	probe http://localhost:9100/readyz
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+const _timeout = 3 * time.Second
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: probe <url>")
+		os.Exit(2)
+	}
+
+	client := &http.Client{Timeout: _timeout}
+
+	res, err := client.Get(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		fmt.Fprintln(os.Stderr, res.Status)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+)
+
+const (
+	_defaultProductAddr = "localhost:5001"
+	_commandTimeout     = 30 * time.Second
+)
+
+// runCommand runs the catalog subcommands against a running product service:
+//
+//	product import -file menu.csv [-format csv|json] [-dry-run] [-replace] [-addr host:port] [TLS flags] [-token jwt]
+//	product export [-format csv|json] [-out menu.csv] [-addr host:port] [TLS flags] [-token jwt]
+//
+// The TLS flags are -tls, -ca-file, -cert-file, -key-file and -server-name, setting any of them connects with TLS,
+// -tls alone verifies the product service against the system roots.
+// The commands need a manager when the product service checks its callers, -token or -api-key tells who calls.
+func runCommand(name string, args []string) int {
+	switch name {
+	case "import":
+		return runImport(args)
+	case "export":
+		return runExport(args)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, expected import or export\n", name)
+
+		return 2
+	}
+}
+
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	addr := fs.String("addr", _defaultProductAddr, "address of the product service")
+	tls := tlsFlags(fs)
+	creds := credentialsFlags(fs)
+	file := fs.String("file", "", "catalog file to import")
+	format := fs.String("format", "", "csv or json, taken from the file extension when empty")
+	dryRun := fs.Bool("dry-run", false, "only show the changes against the current catalog")
+	replace := fs.Bool("replace", false, "remove the items which are not in the file")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "import: -file is required")
+
+		return 2
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %s\n", err)
+
+		return 1
+	}
+
+	client, closeConn, err := newProductClient(*addr, tls)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %s\n", err)
+
+		return 1
+	}
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), _commandTimeout)
+	defer cancel()
+
+	ctx = auth.WithCredentials(ctx, creds.credentials())
+
+	res, err := client.ImportCatalog(ctx, &gen.ImportCatalogRequest{
+		Format:  *format,
+		Data:    string(data),
+		DryRun:  *dryRun,
+		Replace: *replace,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import: %s\n", err)
+
+		return 1
+	}
+
+	for _, change := range res.Changes {
+		fmt.Println(formatChange(change))
+	}
+
+	for _, rowErr := range res.Errors {
+		fmt.Fprintf(os.Stderr, "row %d: %s: %s\n", rowErr.Row, rowErr.Field, rowErr.Message)
+	}
+
+	switch {
+	case len(res.Errors) > 0:
+		fmt.Fprintf(os.Stderr, "%d invalid rows, nothing was imported\n", len(res.Errors))
+
+		return 1
+	case res.Applied:
+		fmt.Printf("%d changes imported\n", len(res.Changes))
+	default:
+		fmt.Printf("%d changes (dry run)\n", len(res.Changes))
+	}
+
+	return 0
+}
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	addr := fs.String("addr", _defaultProductAddr, "address of the product service")
+	tls := tlsFlags(fs)
+	creds := credentialsFlags(fs)
+	format := fs.String("format", "csv", "csv or json")
+	out := fs.String("out", "", "file to write, stdout when empty")
+	_ = fs.Parse(args)
+
+	client, closeConn, err := newProductClient(*addr, tls)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+
+		return 1
+	}
+	defer closeConn()
+
+	ctx, cancel := context.WithTimeout(context.Background(), _commandTimeout)
+	defer cancel()
+
+	ctx = auth.WithCredentials(ctx, creds.credentials())
+
+	res, err := client.ExportCatalog(ctx, &gen.ExportCatalogRequest{Format: *format})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export: %s\n", err)
+
+			return 1
+		}
+		defer f.Close()
+
+		w = f
+	}
+
+	if _, err = w.Write(res.Data); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %s\n", err)
+
+		return 1
+	}
+
+	return 0
+}
+
+func newProductClient(addr string, tls *configs.TLS) (gen.ProductServiceClient, func(), error) {
+	tls.Enabled = tls.Enabled || tls.CAFile != "" || tls.CertFile != "" || tls.KeyFile != "" || tls.ServerName != ""
+
+	conn, err := grpcx.Dial(addr, grpcx.ClientTLS(*tls))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return gen.NewProductServiceClient(conn), func() { conn.Close() }, nil
+}
+
+// tlsFlags are the TLS settings of the connection to the product service, TLS is used when any of them is set.
+func tlsFlags(fs *flag.FlagSet) *configs.TLS {
+	tls := &configs.TLS{}
+
+	fs.BoolVar(&tls.Enabled, "tls", false, "connect with TLS, implied by the other TLS flags")
+	fs.StringVar(&tls.CAFile, "ca-file", "", "CA certificate the product service is verified against")
+	fs.StringVar(&tls.CertFile, "cert-file", "", "client certificate, when the product service requires one")
+	fs.StringVar(&tls.KeyFile, "key-file", "", "key of the client certificate")
+	fs.StringVar(&tls.ServerName, "server-name", "", "name in the certificate of the product service, the host of -addr when empty")
+
+	return tls
+}
+
+type credentials struct {
+	token  string
+	apiKey string
+}
+
+// credentialsFlags tell the product service who calls, when it checks its callers.
+func credentialsFlags(fs *flag.FlagSet) *credentials {
+	creds := &credentials{}
+
+	fs.StringVar(&creds.token, "token", "", "JWT of a manager, sent as a bearer token")
+	fs.StringVar(&creds.apiKey, "api-key", "", "API key with the manager role")
+
+	return creds
+}
+
+func (c *credentials) credentials() auth.Credentials {
+	creds := auth.Credentials{APIKey: c.apiKey}
+	if c.token != "" {
+		creds.Authorization = "Bearer " + c.token
+	}
+
+	return creds
+}
+
+func formatChange(change *gen.CatalogChangeDto) string {
+	item := change.After
+	if item == nil {
+		item = change.Before
+	}
+
+	line := fmt.Sprintf("%-6s %s %s", change.Action, change.Name, formatItem(item))
+
+	if change.Before != nil && change.After != nil {
+		line += fmt.Sprintf(" (was %s)", formatItem(change.Before))
+	}
+
+	return line
+}
+
+func formatItem(item *gen.CatalogItemDto) string {
+	return fmt.Sprintf("sku=%s price=%g station=%s image=%s available=%t category=%s tags=%s allergens=%s",
+		item.Sku, item.Price, item.Station, item.Image, item.Available, item.Category,
+		strings.Join(item.Tags, ";"), strings.Join(item.Allergens, ";"))
+}
@@ -11,9 +11,42 @@ import (
 
 type (
 	Config struct {
-		configs.App  `yaml:"app"`
-		configs.HTTP `yaml:"http"`
-		configs.Log  `yaml:"logger"`
+		configs.App     `yaml:"app"`
+		configs.HTTP    `yaml:"http"`
+		configs.GRPC    `yaml:"grpc"`
+		configs.Log     `yaml:"logger"`
+		configs.Tracing `yaml:"tracing"`
+		configs.Admin   `yaml:"admin"`
+		configs.Auth    `yaml:"auth"`
+		RabbitMQ        `yaml:"rabbitmq"`
+		Images          `yaml:"images"`
+		Menu            `yaml:"menu"`
+		Catalog         `yaml:"catalog"`
+	}
+
+	RabbitMQ struct {
+		URL string `env-required:"true" yaml:"url" env:"RABBITMQ_URL"`
+	}
+
+	Images struct {
+		// Dir is where the local blob store keeps the uploaded images and their thumbnails.
+		Dir string `env-default:"images" yaml:"dir" env:"IMAGES_DIR"`
+		// BaseURL is the public address of the proxy, item images are served below it.
+		BaseURL        string `env-default:"http://localhost:5000" yaml:"base_url" env:"IMAGES_BASE_URL"`
+		ThumbnailWidth int    `env-default:"160" yaml:"thumbnail_width" env:"IMAGES_THUMBNAIL_WIDTH"`
+		MaxBytes       int    `env-default:"2097152" yaml:"max_bytes" env:"IMAGES_MAX_BYTES"`
+		// MaxPixels bounds the memory an image takes once decoded, whatever its size compressed.
+		MaxPixels int `env-default:"16777216" yaml:"max_pixels" env:"IMAGES_MAX_PIXELS"`
+	}
+
+	Menu struct {
+		// SchedulesFile holds the time-based menus and prices, empty sells every item at its catalog price all day.
+		SchedulesFile string `env-default:"" yaml:"schedules_file" env:"MENU_SCHEDULES_FILE"`
+	}
+
+	Catalog struct {
+		// File keeps the imported catalog and the item images across restarts, empty keeps them in memory only.
+		File string `env-default:"" yaml:"file" env:"CATALOG_FILE"`
 	}
 )
 
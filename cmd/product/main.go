@@ -7,28 +7,48 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+
+	// the price schedules are in the time zone of each location, the scratch image has no tzdata
+	_ "time/tzdata"
 
 	"github.com/sirupsen/logrus"
 	"github.com/thangchung/go-coffeeshop/cmd/product/config"
 	"github.com/thangchung/go-coffeeshop/internal/product/app"
+	"github.com/thangchung/go-coffeeshop/internal/product/app/router"
+	"github.com/thangchung/go-coffeeshop/pkg/admin"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcvalidate"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
 	"github.com/thangchung/go-coffeeshop/pkg/logger"
+	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
 	"go.uber.org/automaxprocs/maxprocs"
 	"golang.org/x/exp/slog"
-	"google.golang.org/grpc"
 )
 
+// _readinessInterval is how often the gRPC health service is brought in line with the readiness checks.
+const _readinessInterval = 10 * time.Second
+
 func main() {
+	// `product import` and `product export` manage the catalog of a running service
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
 	// set GOMAXPROCS
 	_, err := maxprocs.Set()
 	if err != nil {
-		slog.Error("failed set max procs", err)
+		slog.Error("failed set max procs", "error", err)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
 	cfg, err := config.NewConfig()
 	if err != nil {
-		slog.Error("failed get config", err)
+		slog.Error("failed get config", "error", err)
 	}
 
 	slog.Info("⚡ init app", "name", cfg.Name, "version", cfg.Version)
@@ -41,26 +61,89 @@ func main() {
 	// integrate Logrus with the slog logger
 	slog.New(logger.NewLogrusHandler(logrus.StandardLogger()))
 
-	server := grpc.NewServer()
+	shutdownTracing, err := tracing.Init(ctx, cfg.App, cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to init tracing", "error", err)
+		cancel()
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush the spans", "error", err)
+		}
+	}()
+
+	checker := health.NewChecker()
+
+	go admin.Serve(ctx, cfg.Admin, checker)
+
+	validation, err := grpcvalidate.UnaryServerInterceptor(cfg.Name)
+	if err != nil {
+		slog.Error("failed to load the validation rules", "error", err)
+		cancel()
+
+		return
+	}
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		slog.Error("failed to load the auth keys", "error", err)
+		cancel()
+
+		return
+	}
+
+	// the callers are checked before anything else runs for them,
+	// the error mapping comes next, so it passes the statuses of the validation on as they are
+	server, healthServer, err := grpcx.NewServer(
+		grpcx.ServerConfig(cfg.GRPC),
+		grpcx.UnaryInterceptors(
+			auth.UnaryServerInterceptor(cfg.Name, authn),
+			grpcerror.UnaryServerInterceptor(cfg.Name, router.ErrorRules...),
+			validation,
+		),
+		grpcx.StreamInterceptors(auth.StreamServerInterceptor(cfg.Name, authn)),
+	)
+	if err != nil {
+		slog.Error("failed to create the gRPC server", "error", err)
+		cancel()
+
+		return
+	}
 
 	go func() {
 		defer server.GracefulStop()
 		<-ctx.Done()
+		healthServer.Shutdown()
 	}()
 
-	_, err = app.InitApp(cfg, server)
+	a, cleanup, err := app.InitApp(cfg, rabbitmq.RabbitMQConnStr(cfg.RabbitMQ.URL), server)
 	if err != nil {
-		slog.Error("failed init app", err)
+		slog.Error("failed init app", "error", err)
 		cancel()
+
+		return
 	}
 
+	defer cleanup()
+
+	a.ConfigureRoutes()
+
+	grpcx.InitializeMetrics(server)
+
+	a.RegisterChecks(checker)
+
+	go checker.Watch(ctx, _readinessInterval, healthServer)
+
 	// gRPC Server.
 	address := fmt.Sprintf("%s:%d", cfg.HTTP.Host, cfg.HTTP.Port)
 	network := "tcp"
 
 	l, err := net.Listen(network, address)
 	if err != nil {
-		slog.Error("failed to listen to address", err, "network", network, "address", address)
+		slog.Error("failed to listen to address", "error", err, "network", network, "address", address)
 		cancel()
 	}
 
@@ -68,13 +151,13 @@ func main() {
 
 	defer func() {
 		if err1 := l.Close(); err != nil {
-			slog.Error("failed to close", err1, "network", network, "address", address)
+			slog.Error("failed to close", "error", err1, "network", network, "address", address)
 		}
 	}()
 
 	err = server.Serve(l)
 	if err != nil {
-		slog.Error("failed start gRPC server", err, "network", network, "address", address)
+		slog.Error("failed start gRPC server", "error", err, "network", network, "address", address)
 		cancel()
 	}
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	configs "github.com/thangchung/go-coffeeshop/pkg/config"
@@ -11,10 +12,13 @@ import (
 
 type (
 	Config struct {
-		configs.App  `yaml:"app"`
-		configs.HTTP `yaml:"http"`
-		GRPC         `yaml:"grpc"`
-		configs.Log  `yaml:"logger"`
+		configs.App     `yaml:"app"`
+		configs.HTTP    `yaml:"http"`
+		GRPC            `yaml:"grpc"`
+		configs.Log     `yaml:"logger"`
+		configs.Tracing `yaml:"tracing"`
+		configs.Auth    `yaml:"auth"`
+		configs.CORS    `yaml:"cors"`
 	}
 
 	GRPC struct {
@@ -22,6 +26,9 @@ type (
 		ProductPort int    `env-required:"true" yaml:"product_port" env:"GRPC_PRODUCT_PORT"`
 		CounterHost string `env-required:"true" yaml:"counter_host" env:"GRPC_COUNTER_HOST"`
 		CounterPort int    `env-required:"true" yaml:"counter_port" env:"GRPC_COUNTER_PORT"`
+		// Timeout is the deadline of the calls to the services.
+		Timeout time.Duration `env-default:"30s" yaml:"timeout" env:"GRPC_TIMEOUT"`
+		TLS     configs.TLS   `yaml:"tls" env-prefix:"GRPC_TLS_"`
 	}
 )
 
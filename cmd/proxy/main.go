@@ -6,75 +6,64 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"strings"
 
 	"github.com/golang/glog"
 	gwruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/sirupsen/logrus"
 	"github.com/thangchung/go-coffeeshop/cmd/proxy/config"
+	"github.com/thangchung/go-coffeeshop/internal/gateway"
+	"github.com/thangchung/go-coffeeshop/pkg/admin"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
 	"github.com/thangchung/go-coffeeshop/pkg/logger"
-	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
 	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
+// newGateway proxies to the services over connections of its own, so the readiness checks probe the same ones.
 func newGateway(
 	ctx context.Context,
 	cfg *config.Config,
+	checker *health.Checker,
 	opts []gwruntime.ServeMuxOption,
 ) (http.Handler, error) {
 	productEndpoint := fmt.Sprintf("%s:%d", cfg.ProductHost, cfg.ProductPort)
 	counterEndpoint := fmt.Sprintf("%s:%d", cfg.CounterHost, cfg.CounterPort)
 
-	mux := gwruntime.NewServeMux(opts...)
-	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-
-	err := gen.RegisterProductServiceHandlerFromEndpoint(ctx, mux, productEndpoint, dialOpts)
+	productConn, err := dial(ctx, cfg, productEndpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	err = gen.RegisterCounterServiceHandlerFromEndpoint(ctx, mux, counterEndpoint, dialOpts)
+	counterConn, err := dial(ctx, cfg, counterEndpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	return mux, nil
-}
-
-func allowCORS(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if origin := r.Header.Get("Origin"); origin != "" {
-			w.Header().Set("Access-Control-Allow-Origin", origin)
-			if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
-				preflightHandler(w, r)
+	checker.Register("product", health.GRPC(productConn))
+	checker.Register("counter", health.GRPC(counterConn))
 
-				return
-			}
-		}
-		h.ServeHTTP(w, r)
-	})
+	return gateway.New(ctx, productConn, counterConn, opts...)
 }
 
-func preflightHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-
-	headers := []string{"*"}
-	w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ","))
-
-	methods := []string{"GET", "HEAD", "POST", "PUT", "DELETE"}
-	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ","))
+// dial connects to a service until ctx is done.
+func dial(ctx context.Context, cfg *config.Config, endpoint string) (*grpc.ClientConn, error) {
+	conn, err := grpcx.Dial(endpoint, grpcx.ClientTLS(cfg.GRPC.TLS), grpcx.CallTimeout(cfg.GRPC.Timeout))
+	if err != nil {
+		return nil, err
+	}
 
-	slog.Info("preflight request", "http_path", r.URL.Path)
-}
+	go func() {
+		<-ctx.Done()
 
-func withLogger(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		slog.Info("Run request", "http_method", r.Method, "http_url", r.URL)
+		if err := conn.Close(); err != nil {
+			slog.Error("failed to close the connection", "error", err, "endpoint", endpoint)
+		}
+	}()
 
-		h.ServeHTTP(w, r)
-	})
+	return conn, nil
 }
 
 func main() {
@@ -96,18 +85,49 @@ func main() {
 	// integrate Logrus with the slog logger
 	slog.New(logger.NewLogrusHandler(logrus.StandardLogger()))
 
-	mux := http.NewServeMux()
+	shutdownTracing, err := tracing.Init(ctx, cfg.App, cfg.Tracing)
+	if err != nil {
+		slog.Error("failed to init tracing", "error", err)
+		cancel()
+
+		return
+	}
+
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to flush the spans", "error", err)
+		}
+	}()
 
-	gw, err := newGateway(ctx, cfg, nil)
+	authn, err := auth.NewAuthenticator(cfg.Auth)
 	if err != nil {
-		slog.Error("failed to create a new gateway", err)
+		slog.Error("failed to load the auth keys", "error", err)
+		cancel()
+
+		return
 	}
 
-	mux.Handle("/", gw)
+	checker := health.NewChecker()
+
+	gw, err := newGateway(ctx, cfg, checker, nil)
+	if err != nil {
+		slog.Error("failed to create a new gateway", "error", err)
+	}
+
+	// the probes and the scrapes are neither logged nor traced
+	mux := http.NewServeMux()
+	admin.Register(mux, checker)
+
+	mux.Handle("/", gateway.Handler(
+		cfg.Name,
+		gw,
+		gateway.AllowedOrigins(cfg.CORS.AllowedOrigins...),
+		gateway.Authenticator(authn),
+	))
 
 	s := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Handler: allowCORS(withLogger(mux)),
+		Handler: mux,
 	}
 
 	go func() {
@@ -115,13 +135,13 @@ func main() {
 		slog.Info("shutting down the http server")
 
 		if err := s.Shutdown(context.Background()); err != nil {
-			slog.Error("failed to shutdown http server", err)
+			slog.Error("failed to shutdown http server", "error", err)
 		}
 	}()
 
 	slog.Info("start listening...", "address", fmt.Sprintf("%s:%d", cfg.Host, cfg.Port))
 
 	if err := s.ListenAndServe(); errors.Is(err, http.ErrServerClosed) {
-		slog.Error("failed to listen and serve", err)
+		slog.Error("failed to listen and serve", "error", err)
 	}
 }
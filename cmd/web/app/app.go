@@ -0,0 +1,26 @@
+// Package app is the web UI, cmd/web and cmd/coffeeshop serve it.
+package app
+
+import (
+	"embed"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed index.html favicon.ico css js img sound data
+var Files embed.FS
+
+type URLModel struct {
+	URL string `json:"url"`
+}
+
+// Register serves the UI from fsys, the UI asks for the reverse proxy URL to find the REST API.
+func Register(e *echo.Echo, fsys http.FileSystem, reverseProxyURL string) {
+	assetHandler := http.FileServer(fsys)
+	e.GET("/", echo.WrapHandler(assetHandler))
+	e.GET("/static/*", echo.WrapHandler(http.StripPrefix("/static/", assetHandler)))
+	e.GET("/reverse-proxy-url", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, URLModel{URL: reverseProxyURL})
+	})
+}
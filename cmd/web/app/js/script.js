@@ -6,9 +6,13 @@ function initApp() {
     moneys: [2000, 5000, 10000, 20000, 50000, 100000],
     itemTypes: [],
     keyword: "",
+    category: "",
+    categories: ["beverages", "food", "seasonal"],
+    searchResults: null,
     cart: [],
     orders: [],
     lineItems: [],
+    timelines: {},
     cash: 0,
     change: 0,
     isProductPage: true,
@@ -31,11 +35,32 @@ function initApp() {
     async loadOrders() {
       this.orders = [];
       this.lineItems = [];
+      this.timelines = {};
       const response = await fetch(`${this.url}/v1/fulfillment-orders`)
       const data = await response.json();
       this.orders = data.orders;
       console.log("orders loaded", this.orders);
     },
+    async loadTimeline(orderId) {
+      const response = await fetch(`${this.url}/v1/api/orders/${orderId}/timeline`)
+      const data = await response.json();
+      this.timelines[orderId] = data.entries || [];
+      console.log("timeline loaded", orderId, this.timelines[orderId]);
+    },
+    toggleTimeline(orderId) {
+      if (this.timelines[orderId]) {
+        delete this.timelines[orderId];
+        return;
+      }
+      this.loadTimeline(orderId);
+    },
+    timelineFor(orderId, lineItemId) {
+      return (this.timelines[orderId] || []).filter((e) => e.lineItemId === lineItemId);
+    },
+    timeFormat(value) {
+      const formatter = new Intl.DateTimeFormat('id', { timeStyle: 'medium' });
+      return formatter.format(new Date(value));
+    },
     async createOrder(order) {
       const response = await fetch(`${this.url}/v1/api/orders`, {
         method: 'POST',
@@ -48,16 +73,30 @@ function initApp() {
       const data = await response.json();
       console.log("orders created", data);
     },
+    async searchProducts() {
+      if (!this.keyword && !this.category) {
+        this.searchResults = null;
+        return;
+      }
+      const params = new URLSearchParams({ query: this.keyword, category: this.category, page_size: 100 });
+      const response = await fetch(`${this.url}/v1/api/item-types/search?${params}`)
+      const data = await response.json();
+      this.searchResults = data.items || [];
+      console.log("items found", data.total);
+    },
+    selectCategory(category) {
+      this.category = this.category === category ? "" : category;
+      this.searchProducts();
+    },
     filteredProducts() {
-      const rg = this.keyword ? new RegExp(this.keyword, "gi") : null;
-      return this.itemTypes.filter((p) => !rg || p.name.match(rg));
+      return this.searchResults || this.itemTypes;
     },
     addToCart(product) {
       const index = this.findCartIndex(product);
       if (index === -1) {
         this.cart.push({
           productType: product.type,
-          image: product.image,
+          image: product.thumbnail || product.image,
           name: product.name,
           price: product.price,
           qty: 1,
@@ -135,6 +174,10 @@ function initApp() {
       return number ? `${this.numberFormat(number)}$` : `0$`;
     },
     resolveImage(image) {
+      // item images are URLs served by the product service, other images ship with the web app
+      if (/^https?:\/\//.test(image)) {
+        return image;
+      }
       return `static/${image}`;
     },
     changeToProductPage() {
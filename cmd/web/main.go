@@ -1,20 +1,16 @@
 package main
 
 import (
-	"embed"
 	"fmt"
-	"io/fs"
 	"log"
 	"net/http"
 	"os"
 
 	"github.com/golang/glog"
 	"github.com/labstack/echo/v4"
+	"github.com/thangchung/go-coffeeshop/cmd/web/app"
 )
 
-//go:embed app
-var embededFiles embed.FS
-
 func getFileSystem(useOS bool) http.FileSystem {
 	if useOS {
 		log.Print("using live mode")
@@ -24,16 +20,7 @@ func getFileSystem(useOS bool) http.FileSystem {
 
 	log.Print("using embed mode")
 
-	fsys, err := fs.Sub(embededFiles, "app")
-	if err != nil {
-		panic(err)
-	}
-
-	return http.FS(fsys)
-}
-
-type UrlModel struct {
-	Url string `json:"url"`
+	return http.FS(app.Files)
 }
 
 func main() {
@@ -50,12 +37,7 @@ func main() {
 	e := echo.New()
 
 	useOS := len(os.Args) > 1 && os.Args[1] == "live"
-	assetHandler := http.FileServer(getFileSystem(useOS))
-	e.GET("/", echo.WrapHandler(assetHandler))
-	e.GET("/static/*", echo.WrapHandler(http.StripPrefix("/static/", assetHandler)))
-	e.GET("/reverse-proxy-url", func(c echo.Context) error {
-		return c.JSON(http.StatusOK, UrlModel{Url: reverseProxyURL})
-	})
+	app.Register(e, getFileSystem(useOS), reverseProxyURL)
 
 	e.Logger.Fatal(e.Start(fmt.Sprintf(":%v", webPort)))
 }
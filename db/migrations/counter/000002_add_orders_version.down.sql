@@ -0,0 +1,5 @@
+START TRANSACTION;
+
+ALTER TABLE "order".orders DROP COLUMN IF EXISTS version;
+
+COMMIT;
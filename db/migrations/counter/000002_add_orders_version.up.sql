@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+ALTER TABLE "order".orders
+ADD
+    COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 0;
+
+COMMIT;
@@ -0,0 +1,7 @@
+START TRANSACTION;
+
+DROP TABLE IF EXISTS "order".order_snapshots;
+
+DROP TABLE IF EXISTS "order".order_events;
+
+COMMIT;
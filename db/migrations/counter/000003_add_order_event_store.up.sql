@@ -0,0 +1,28 @@
+START TRANSACTION;
+
+CREATE TABLE
+    IF NOT EXISTS "order".order_events (
+        id bigserial NOT NULL,
+        order_id uuid NOT NULL,
+        version integer NOT NULL,
+        event_type text NOT NULL,
+        payload jsonb NOT NULL,
+        created timestamp
+        with
+            time zone NOT NULL DEFAULT (now()),
+            CONSTRAINT pk_order_events PRIMARY KEY (id),
+            CONSTRAINT uq_order_events_order_id_version UNIQUE (order_id, version)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS "order".order_snapshots (
+        order_id uuid NOT NULL,
+        version integer NOT NULL,
+        payload jsonb NOT NULL,
+        created timestamp
+        with
+            time zone NOT NULL DEFAULT (now()),
+            CONSTRAINT pk_order_snapshots PRIMARY KEY (order_id, version)
+    );
+
+COMMIT;
@@ -0,0 +1,5 @@
+START TRANSACTION;
+
+DROP TABLE IF EXISTS "order".line_item_timeline;
+
+COMMIT;
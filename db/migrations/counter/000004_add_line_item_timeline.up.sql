@@ -0,0 +1,27 @@
+START TRANSACTION;
+
+CREATE TABLE
+    IF NOT EXISTS "order".line_item_timeline (
+        id bigserial NOT NULL,
+        order_id uuid NOT NULL,
+        line_item_id uuid NOT NULL,
+        stage text NOT NULL,
+        station text NOT NULL,
+        actor text NOT NULL DEFAULT (''),
+        occurred_at timestamp
+        with
+            time zone NOT NULL,
+            created timestamp
+        with
+            time zone NOT NULL DEFAULT (now()),
+            CONSTRAINT pk_line_item_timeline PRIMARY KEY (id)
+    );
+
+CREATE INDEX
+    IF NOT EXISTS ix_line_item_timeline_order_id ON "order".line_item_timeline (order_id);
+
+-- a stage is recorded once per line item, redelivered events must not duplicate it
+CREATE UNIQUE INDEX
+    IF NOT EXISTS ix_line_item_timeline_line_item_id_stage ON "order".line_item_timeline (line_item_id, stage);
+
+COMMIT;
@@ -0,0 +1,13 @@
+START TRANSACTION;
+
+DROP FUNCTION IF EXISTS "order".refresh_reports();
+
+DROP MATERIALIZED VIEW IF EXISTS "order".line_item_prep_times;
+
+DROP MATERIALIZED VIEW IF EXISTS "order".daily_item_sales;
+
+DROP MATERIALIZED VIEW IF EXISTS "order".daily_sales;
+
+ALTER TABLE "order".orders DROP COLUMN IF EXISTS location;
+
+COMMIT;
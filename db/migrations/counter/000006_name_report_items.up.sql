@@ -0,0 +1,75 @@
+START TRANSACTION;
+
+-- the orders placed from now on without a location are reported under unknown.
+-- 000005 has already filled the location of the older orders with 0, those stay under ATLANTA
+ALTER TABLE "order".orders ALTER COLUMN location DROP NOT NULL;
+
+ALTER TABLE "order".orders ALTER COLUMN location DROP DEFAULT;
+
+DROP MATERIALIZED VIEW IF EXISTS "order".daily_item_sales;
+
+DROP MATERIALIZED VIEW IF EXISTS "order".daily_sales;
+
+DROP MATERIALIZED VIEW IF EXISTS "order".line_item_prep_times;
+
+-- one row per day and store, used for sales totals and the average ticket
+CREATE MATERIALIZED VIEW
+    IF NOT EXISTS "order".daily_sales AS
+SELECT
+    CAST(date_trunc('day', l.created) AS date) AS sales_date,
+    o.location,
+    CAST(count(DISTINCT o.id) AS bigint) AS orders,
+    CAST(count(l.id) AS bigint) AS items,
+    CAST(sum(l.price) AS numeric) AS revenue
+FROM "order".orders o
+    INNER JOIN "order".line_items l ON o.id = l.order_id
+GROUP BY 1, o.location;
+
+CREATE UNIQUE INDEX
+    IF NOT EXISTS ix_daily_sales_sales_date_location ON "order".daily_sales (sales_date, location);
+
+-- one row per day, store and item type, used for the item mix.
+-- item_name is the catalog name of the line items, the types added by a catalog import have no other
+CREATE MATERIALIZED VIEW
+    IF NOT EXISTS "order".daily_item_sales AS
+SELECT
+    CAST(date_trunc('day', l.created) AS date) AS sales_date,
+    o.location,
+    l.item_type,
+    CAST(max(l.name) AS text) AS item_name,
+    CAST(count(l.id) AS bigint) AS items,
+    CAST(sum(l.price) AS numeric) AS revenue
+FROM "order".orders o
+    INNER JOIN "order".line_items l ON o.id = l.order_id
+GROUP BY 1, o.location, l.item_type;
+
+CREATE UNIQUE INDEX
+    IF NOT EXISTS ix_daily_item_sales_sales_date_location_item_type ON "order".daily_item_sales (sales_date, location, item_type);
+
+-- one row per completed line item with the time it spent at the station and since it was placed
+CREATE MATERIALIZED VIEW
+    IF NOT EXISTS "order".line_item_prep_times AS
+SELECT
+    c.line_item_id,
+    CAST(date_trunc('day', c.occurred_at) AS date) AS completed_date,
+    o.location,
+    l.item_type,
+    l.name AS item_name,
+    c.station,
+    c.actor,
+    CAST(extract(epoch FROM c.occurred_at - s.occurred_at) AS double precision) AS prep_seconds,
+    CAST(extract(epoch FROM c.occurred_at - p.occurred_at) AS double precision) AS total_seconds
+FROM "order".line_item_timeline c
+    INNER JOIN "order".line_item_timeline s ON s.line_item_id = c.line_item_id AND s.stage = 'started'
+    INNER JOIN "order".line_item_timeline p ON p.line_item_id = c.line_item_id AND p.stage = 'placed'
+    INNER JOIN "order".line_items l ON l.id = c.line_item_id
+    INNER JOIN "order".orders o ON o.id = c.order_id
+WHERE c.stage = 'completed';
+
+CREATE UNIQUE INDEX
+    IF NOT EXISTS ix_line_item_prep_times_line_item_id ON "order".line_item_prep_times (line_item_id);
+
+CREATE INDEX
+    IF NOT EXISTS ix_line_item_prep_times_completed_date ON "order".line_item_prep_times (completed_date);
+
+COMMIT;
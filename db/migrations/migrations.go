@@ -0,0 +1,178 @@
+// Package migrations embeds the SQL migrations of every service that owns a database schema.
+// Each service has its own set and its own version table, so they move independently
+// even when they share one database. The sets under sqlite/ create the same tables on SQLite.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+	"path"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	Counter = "counter"
+	Barista = "barista"
+	Kitchen = "kitchen"
+)
+
+var (
+	ErrUnknownService = errors.New("unknown service")
+	ErrLegacyDirty    = errors.New("the legacy schema_migrations is dirty")
+)
+
+// _legacyTable tracked one set of all the services before each of them got its own.
+const _legacyTable = "schema_migrations"
+
+// legacyVersions are the versions which the migrations of every service had in the legacy set, in their order.
+var legacyVersions = map[string][]uint{
+	Counter: {1, 4, 5, 6, 7},
+	Barista: {2},
+	Kitchen: {3},
+}
+
+const _sqliteDir = "sqlite"
+
+//go:embed counter/*.sql barista/*.sql kitchen/*.sql sqlite/counter/*.sql sqlite/barista/*.sql sqlite/kitchen/*.sql
+var sets embed.FS
+
+// Services lists the services that have a migration set.
+func Services() []string {
+	return []string{Counter, Barista, Kitchen}
+}
+
+// Source returns the PostgreSQL migration files of the service.
+func Source(service string) (fs.FS, error) {
+	return source(service, service)
+}
+
+// SQLiteSource returns the SQLite migration files of the service.
+func SQLiteSource(service string) (fs.FS, error) {
+	return source(service, path.Join(_sqliteDir, service))
+}
+
+func source(service, dir string) (fs.FS, error) {
+	if !lo.Contains(Services(), service) {
+		return nil, errors.Wrap(ErrUnknownService, service)
+	}
+
+	return fs.Sub(sets, dir)
+}
+
+// Table is where the version of the service's migrations is tracked.
+func Table(service string) string {
+	return service + "_schema_migrations"
+}
+
+// LegacyVersion is the version of the service's own set that a database at version of the legacy set is at,
+// 0 when none of the service's migrations had run.
+func LegacyVersion(service string, version uint) uint {
+	return uint(len(lo.Filter(legacyVersions[service], func(v uint, _ int) bool {
+		return v <= version
+	})))
+}
+
+// New prepares the PostgreSQL migrations of the service against db, closing the returned migrate closes db.
+// A database migrated with the legacy set starts the service's table at the version it has already reached.
+func New(service string, db *sql.DB) (*migrate.Migrate, error) {
+	source, err := Source(service)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithInstance(db, &postgres.Config{MigrationsTable: Table(service)})
+	if err != nil {
+		return nil, errors.Wrap(err, "postgres.WithInstance")
+	}
+
+	m, err := newMigrate(source, "postgres", driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = baseline(service, db, m); err != nil {
+		m.Close()
+
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// baseline forces the version of the legacy set onto the service's table while the latter has none,
+// so the migrations which ran before the sets were split don't run again.
+func baseline(service string, db *sql.DB, m *migrate.Migrate) error {
+	if _, _, err := m.Version(); !errors.Is(err, migrate.ErrNilVersion) {
+		return nil
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT to_regclass($1) IS NOT NULL", _legacyTable).Scan(&exists); err != nil {
+		return errors.Wrap(err, "to_regclass")
+	}
+
+	if !exists {
+		return nil
+	}
+
+	var (
+		version uint
+		dirty   bool
+	)
+
+	err := db.QueryRow("SELECT version, dirty FROM "+_legacyTable+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "select "+_legacyTable)
+	}
+
+	if dirty {
+		return errors.Wrapf(ErrLegacyDirty, "at version %d", version)
+	}
+
+	if v := LegacyVersion(service, version); v > 0 {
+		return errors.Wrap(m.Force(int(v)), "m.Force")
+	}
+
+	return nil
+}
+
+// NewSQLite prepares the SQLite migrations of the service against db, closing the returned migrate closes db.
+func NewSQLite(service string, db *sql.DB) (*migrate.Migrate, error) {
+	source, err := SQLiteSource(service)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{MigrationsTable: Table(service)})
+	if err != nil {
+		return nil, errors.Wrap(err, "sqlite.WithInstance")
+	}
+
+	return newMigrate(source, "sqlite", driver)
+}
+
+func newMigrate(source fs.FS, databaseName string, driver database.Driver) (*migrate.Migrate, error) {
+	src, err := iofs.New(source, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "iofs.New")
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, databaseName, driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "migrate.NewWithInstance")
+	}
+
+	return m, nil
+}
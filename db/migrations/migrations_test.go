@@ -0,0 +1,96 @@
+package migrations_test
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+func TestEverySetStartsAtVersionOneWithPairedFiles(t *testing.T) {
+	t.Parallel()
+
+	for _, service := range migrations.Services() {
+		for _, sourceOf := range []func(string) (fs.FS, error){migrations.Source, migrations.SQLiteSource} {
+			assertPairedFromVersionOne(t, service, sourceOf)
+		}
+	}
+}
+
+func assertPairedFromVersionOne(t *testing.T, service string, sourceOf func(string) (fs.FS, error)) {
+	t.Helper()
+
+	source, err := sourceOf(service)
+	require.NoError(t, err, service)
+
+	src, err := iofs.New(source, ".")
+	require.NoError(t, err, service)
+
+	version, err := src.First()
+	require.NoError(t, err, service)
+	assert.Equal(t, uint(1), version, service)
+
+	for err == nil {
+		_, _, upErr := src.ReadUp(version)
+		assert.NoError(t, upErr, "%s up %d", service, version)
+
+		_, _, downErr := src.ReadDown(version)
+		assert.NoError(t, downErr, "%s down %d", service, version)
+
+		version, err = src.Next(version)
+	}
+}
+
+func TestSQLiteSetsGoUpAndDownInOneFile(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "coffeeshop.db")
+
+	for _, service := range migrations.Services() {
+		for _, apply := range []func(*migrate.Migrate) error{(*migrate.Migrate).Up, (*migrate.Migrate).Down} {
+			// closing the migrate closes the database, so every run opens the file again
+			pg, err := postgres.NewSQLiteDB(postgres.DBConnString(file), postgres.Name("migrate-test"))
+			require.NoError(t, err, service)
+
+			m, err := migrations.NewSQLite(service, pg.GetDB())
+			require.NoError(t, err, service)
+
+			assert.NoError(t, apply(m), service)
+
+			srcErr, dbErr := m.Close()
+			require.NoError(t, srcErr, service)
+			require.NoError(t, dbErr, service)
+		}
+	}
+}
+
+func TestSourceRejectsAnUnknownService(t *testing.T) {
+	t.Parallel()
+
+	_, err := migrations.Source("product")
+	assert.ErrorIs(t, err, migrations.ErrUnknownService)
+
+	_, err = migrations.SQLiteSource("product")
+	assert.ErrorIs(t, err, migrations.ErrUnknownService)
+}
+
+func TestLegacyVersionCountsTheMigrationsOfTheServiceInTheSharedSet(t *testing.T) {
+	t.Parallel()
+
+	// the baseline tree stopped at the kitchen's init
+	assert.Equal(t, uint(1), migrations.LegacyVersion(migrations.Counter, 3))
+	assert.Equal(t, uint(1), migrations.LegacyVersion(migrations.Barista, 3))
+	assert.Equal(t, uint(1), migrations.LegacyVersion(migrations.Kitchen, 3))
+
+	assert.Equal(t, uint(0), migrations.LegacyVersion(migrations.Kitchen, 2))
+	assert.Equal(t, uint(3), migrations.LegacyVersion(migrations.Counter, 5))
+	assert.Equal(t, uint(5), migrations.LegacyVersion(migrations.Counter, 7))
+	assert.Equal(t, uint(0), migrations.LegacyVersion(migrations.Counter, 0))
+}
@@ -0,0 +1,10 @@
+CREATE TABLE
+    IF NOT EXISTS barista_orders (
+        id uuid NOT NULL,
+        item_type integer NOT NULL,
+        item_name text NOT NULL,
+        time_up timestamp NOT NULL,
+        created timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+        updated timestamp,
+        CONSTRAINT pk_barista_orders PRIMARY KEY (id)
+    );
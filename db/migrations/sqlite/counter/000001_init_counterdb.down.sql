@@ -0,0 +1,11 @@
+DROP VIEW IF EXISTS line_item_prep_times;
+
+DROP VIEW IF EXISTS daily_item_sales;
+
+DROP VIEW IF EXISTS daily_sales;
+
+DROP TABLE IF EXISTS line_item_timeline;
+
+DROP TABLE IF EXISTS line_items;
+
+DROP TABLE IF EXISTS orders;
@@ -0,0 +1,93 @@
+-- SQLite has no schemas, the tables of the services differ by name in a shared file
+CREATE TABLE
+    IF NOT EXISTS orders (
+        id uuid NOT NULL,
+        order_source integer NOT NULL,
+        loyalty_member_id uuid NOT NULL,
+        order_status integer NOT NULL,
+        location integer NOT NULL DEFAULT 0,
+        version integer NOT NULL DEFAULT 0,
+        updated timestamp,
+        CONSTRAINT pk_orders PRIMARY KEY (id)
+    );
+
+CREATE TABLE
+    IF NOT EXISTS line_items (
+        id uuid NOT NULL,
+        item_type integer NOT NULL,
+        name text NOT NULL,
+        price real NOT NULL,
+        item_status integer NOT NULL,
+        is_barista_order boolean NOT NULL,
+        order_id uuid,
+        created timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+        updated timestamp,
+        CONSTRAINT pk_line_items PRIMARY KEY (id),
+        CONSTRAINT fk_line_items_orders_order_id FOREIGN KEY (order_id) REFERENCES orders (id)
+    );
+
+CREATE INDEX IF NOT EXISTS ix_line_items_order_id ON line_items (order_id);
+
+CREATE TABLE
+    IF NOT EXISTS line_item_timeline (
+        id integer NOT NULL,
+        order_id uuid NOT NULL,
+        line_item_id uuid NOT NULL,
+        stage text NOT NULL,
+        station text NOT NULL,
+        actor text NOT NULL DEFAULT (''),
+        occurred_at timestamp NOT NULL,
+        created timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+        CONSTRAINT pk_line_item_timeline PRIMARY KEY (id)
+    );
+
+CREATE INDEX
+    IF NOT EXISTS ix_line_item_timeline_order_id ON line_item_timeline (order_id);
+
+-- a stage is recorded once per line item, redelivered events must not duplicate it
+CREATE UNIQUE INDEX
+    IF NOT EXISTS ix_line_item_timeline_line_item_id_stage ON line_item_timeline (line_item_id, stage);
+
+-- the reports are plain views, SQLite has no materialized ones, so they never lag behind the orders.
+-- The days are the UTC dates of the timestamps, formatted 2006-01-02.
+CREATE VIEW
+    IF NOT EXISTS daily_sales AS
+SELECT
+    date(l.created) AS sales_date,
+    o.location,
+    count(DISTINCT o.id) AS orders,
+    count(l.id) AS items,
+    sum(l.price) AS revenue
+FROM orders o
+    INNER JOIN line_items l ON o.id = l.order_id
+GROUP BY 1, o.location;
+
+CREATE VIEW
+    IF NOT EXISTS daily_item_sales AS
+SELECT
+    date(l.created) AS sales_date,
+    o.location,
+    l.item_type,
+    count(l.id) AS items,
+    sum(l.price) AS revenue
+FROM orders o
+    INNER JOIN line_items l ON o.id = l.order_id
+GROUP BY 1, o.location, l.item_type;
+
+CREATE VIEW
+    IF NOT EXISTS line_item_prep_times AS
+SELECT
+    c.line_item_id,
+    date(c.occurred_at) AS completed_date,
+    o.location,
+    l.item_type,
+    c.station,
+    c.actor,
+    (julianday(c.occurred_at) - julianday(s.occurred_at)) * 86400 AS prep_seconds,
+    (julianday(c.occurred_at) - julianday(p.occurred_at)) * 86400 AS total_seconds
+FROM line_item_timeline c
+    INNER JOIN line_item_timeline s ON s.line_item_id = c.line_item_id AND s.stage = 'started'
+    INNER JOIN line_item_timeline p ON p.line_item_id = c.line_item_id AND p.stage = 'placed'
+    INNER JOIN line_items l ON l.id = c.line_item_id
+    INNER JOIN orders o ON o.id = c.order_id
+WHERE c.stage = 'completed';
@@ -0,0 +1,33 @@
+DROP VIEW IF EXISTS line_item_prep_times;
+
+DROP VIEW IF EXISTS daily_item_sales;
+
+CREATE VIEW
+    IF NOT EXISTS daily_item_sales AS
+SELECT
+    date(l.created) AS sales_date,
+    o.location,
+    l.item_type,
+    count(l.id) AS items,
+    sum(l.price) AS revenue
+FROM orders o
+    INNER JOIN line_items l ON o.id = l.order_id
+GROUP BY 1, o.location, l.item_type;
+
+CREATE VIEW
+    IF NOT EXISTS line_item_prep_times AS
+SELECT
+    c.line_item_id,
+    date(c.occurred_at) AS completed_date,
+    o.location,
+    l.item_type,
+    c.station,
+    c.actor,
+    (julianday(c.occurred_at) - julianday(s.occurred_at)) * 86400 AS prep_seconds,
+    (julianday(c.occurred_at) - julianday(p.occurred_at)) * 86400 AS total_seconds
+FROM line_item_timeline c
+    INNER JOIN line_item_timeline s ON s.line_item_id = c.line_item_id AND s.stage = 'started'
+    INNER JOIN line_item_timeline p ON p.line_item_id = c.line_item_id AND p.stage = 'placed'
+    INNER JOIN line_items l ON l.id = c.line_item_id
+    INNER JOIN orders o ON o.id = c.order_id
+WHERE c.stage = 'completed';
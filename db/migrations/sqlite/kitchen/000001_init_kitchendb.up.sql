@@ -0,0 +1,11 @@
+CREATE TABLE
+    IF NOT EXISTS kitchen_orders (
+        id uuid NOT NULL,
+        order_id uuid NOT NULL,
+        item_type integer NOT NULL,
+        item_name text NOT NULL,
+        time_up timestamp NOT NULL,
+        created timestamp NOT NULL DEFAULT (CURRENT_TIMESTAMP),
+        updated timestamp,
+        CONSTRAINT pk_kitchen_orders PRIMARY KEY (id)
+    );
@@ -4,24 +4,22 @@ import (
 	"context"
 	"encoding/json"
 
-	"github.com/rabbitmq/amqp091-go"
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/thangchung/go-coffeeshop/cmd/barista/config"
 	"github.com/thangchung/go-coffeeshop/internal/barista/eventhandlers"
 	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"golang.org/x/exp/slog"
 )
 
 type App struct {
-	Cfg      *config.Config
-	PG       postgres.DBEngine
-	AMQPConn *amqp.Connection
+	Cfg    *config.Config
+	PG     postgres.DBEngine
+	Broker messaging.Broker
 
-	CounterOrderPub pkgPublisher.EventPublisher
-	Consumer        pkgConsumer.EventConsumer
+	CounterOrderPub messaging.Publisher
+	Consumer        messaging.Subscriber
 
 	handler eventhandlers.BaristaOrderedEventHandler
 }
@@ -29,15 +27,15 @@ type App struct {
 func New(
 	cfg *config.Config,
 	pg postgres.DBEngine,
-	amqpConn *amqp.Connection,
-	counterOrderPub pkgPublisher.EventPublisher,
-	consumer pkgConsumer.EventConsumer,
+	broker messaging.Broker,
+	counterOrderPub messaging.Publisher,
+	consumer messaging.Subscriber,
 	handler eventhandlers.BaristaOrderedEventHandler,
 ) *App {
 	return &App{
-		Cfg:      cfg,
-		PG:       pg,
-		AMQPConn: amqpConn,
+		Cfg:    cfg,
+		PG:     pg,
+		Broker: broker,
 
 		CounterOrderPub: counterOrderPub,
 		Consumer:        consumer,
@@ -46,8 +44,33 @@ func New(
 	}
 }
 
-func (c *App) Worker(ctx context.Context, messages <-chan amqp091.Delivery) {
+// RegisterChecks adds the dependencies the app can't work without to the readiness checks.
+func (c *App) RegisterChecks(checker *health.Checker) {
+	checker.Register("postgres", health.SQL(c.PG.GetDB()))
+	checker.Register("broker", c.Broker.Check)
+	checker.Register("consumer", c.Consumer.Check)
+}
+
+// ConfigureRoutes points the consumer at the barista orders and the publisher at the counter.
+func (c *App) ConfigureRoutes() {
+	c.CounterOrderPub.Configure(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.BindingKey("counter-order-routing-key"),
+		messaging.MessageTypeName("barista-order-updated"),
+	)
+
+	c.Consumer.Configure(
+		messaging.ExchangeName("barista-order-exchange"),
+		messaging.QueueName("barista-order-queue"),
+		messaging.BindingKey("barista-order-routing-key"),
+		messaging.ConsumerTag("barista-order-consumer"),
+	)
+}
+
+func (c *App) Worker(ctx context.Context, messages <-chan messaging.Delivery) {
 	for delivery := range messages {
+		deliveryCtx, span := messaging.StartConsumeSpan(ctx, delivery)
+
 		slog.Info("processDeliveries", "delivery_tag", delivery.DeliveryTag)
 		slog.Info("received", "delivery_type", delivery.Type)
 
@@ -57,26 +80,28 @@ func (c *App) Worker(ctx context.Context, messages <-chan amqp091.Delivery) {
 
 			err := json.Unmarshal(delivery.Body, &payload)
 			if err != nil {
-				slog.Error("failed to Unmarshal", err)
+				slog.Error("failed to Unmarshal", "error", err)
 			}
 
-			err = c.handler.Handle(ctx, payload)
+			err = c.handler.Handle(deliveryCtx, payload)
 
 			if err != nil {
 				if err = delivery.Reject(false); err != nil {
-					slog.Error("failed to delivery.Reject", err)
+					slog.Error("failed to delivery.Reject", "error", err)
 				}
 
-				slog.Error("failed to process delivery", err)
+				slog.Error("failed to process delivery", "error", err)
 			} else {
 				err = delivery.Ack(false)
 				if err != nil {
-					slog.Error("failed to acknowledge delivery", err)
+					slog.Error("failed to acknowledge delivery", "error", err)
 				}
 			}
 		default:
 			slog.Info("default")
 		}
+
+		span.End()
 	}
 
 	slog.Info("Deliveries channel closed")
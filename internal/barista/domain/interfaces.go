@@ -0,0 +1,11 @@
+package domain
+
+import (
+	"context"
+)
+
+type (
+	OrderRepo interface {
+		Create(context.Context, *BaristaOrder) error
+	}
+)
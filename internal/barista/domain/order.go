@@ -18,17 +18,24 @@ type BaristaOrder struct {
 	Updated  time.Time
 }
 
-func NewBaristaOrder(e event.BaristaOrdered) BaristaOrder {
+// NewBaristaOrder makes the item, it takes the time of the item type multiplied by timeScale.
+func NewBaristaOrder(e event.BaristaOrdered, timeScale float64) BaristaOrder {
 	timeIn := time.Now()
 
-	delay := calculateDelay(e.ItemType)
+	delay := time.Duration(float64(calculateDelay(e.ItemType)) * timeScale)
 	time.Sleep(delay) // simulate the delay when makes the drink
 
 	timeUp := time.Now().Add(delay)
 
+	// the messages of older counters have no name, the item types they know have one in this build
+	name := e.Name
+	if name == "" {
+		name = e.ItemType.String()
+	}
+
 	order := BaristaOrder{
 		ID:       e.ItemLineID,
-		ItemName: e.ItemType.String(),
+		ItemName: name,
 		ItemType: e.ItemType,
 		TimeUp:   timeUp,
 		Created:  time.Now(),
@@ -38,7 +45,7 @@ func NewBaristaOrder(e event.BaristaOrdered) BaristaOrder {
 	orderUpdatedEvent := event.BaristaOrderUpdated{
 		OrderID:    e.OrderID,
 		ItemLineID: e.ItemLineID,
-		Name:       e.ItemType.String(),
+		Name:       name,
 		ItemType:   e.ItemType,
 		MadeBy:     "teesee",
 		TimeIn:     timeIn,
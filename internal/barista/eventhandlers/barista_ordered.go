@@ -2,16 +2,15 @@ package eventhandlers
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/cmd/barista/config"
 	"github.com/thangchung/go-coffeeshop/internal/barista/domain"
-	"github.com/thangchung/go-coffeeshop/internal/barista/infras/postgresql"
 	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"golang.org/x/exp/slog"
 )
 
@@ -20,13 +19,22 @@ var _ BaristaOrderedEventHandler = (*baristaOrderedEventHandler)(nil)
 var BaristaOrderedEventHandlerSet = wire.NewSet(NewBaristaOrderedEventHandler)
 
 type baristaOrderedEventHandler struct {
-	pg         postgres.DBEngine
-	counterPub publisher.EventPublisher
+	cfg        *config.Config
+	uow        postgres.UnitOfWork
+	repo       domain.OrderRepo
+	counterPub messaging.Publisher
 }
 
-func NewBaristaOrderedEventHandler(pg postgres.DBEngine, counterPub publisher.EventPublisher) BaristaOrderedEventHandler {
+func NewBaristaOrderedEventHandler(
+	cfg *config.Config,
+	uow postgres.UnitOfWork,
+	repo domain.OrderRepo,
+	counterPub messaging.Publisher,
+) BaristaOrderedEventHandler {
 	return &baristaOrderedEventHandler{
-		pg:         pg,
+		cfg:        cfg,
+		uow:        uow,
+		repo:       repo,
 		counterPub: counterPub,
 	}
 }
@@ -34,46 +42,29 @@ func NewBaristaOrderedEventHandler(pg postgres.DBEngine, counterPub publisher.Ev
 func (h *baristaOrderedEventHandler) Handle(ctx context.Context, e event.BaristaOrdered) error {
 	slog.Info("received event", "event.BaristaOrdered", e)
 
-	order := domain.NewBaristaOrder(e)
+	order := domain.NewBaristaOrder(e, h.cfg.Preparation.TimeScale)
 
-	db := h.pg.GetDB()
-	querier := postgresql.New(db)
+	return h.uow.WithinTx(ctx, func(ctx context.Context) error {
+		if err := h.repo.Create(ctx, &order); err != nil {
+			slog.Info("failed to call to repo", "error", err)
 
-	tx, err := db.Begin()
-	if err != nil {
-		return errors.Wrap(err, "baristaOrderedEventHandler.Handle")
-	}
-
-	qtx := querier.WithTx(tx)
-
-	_, err = qtx.CreateOrder(ctx, postgresql.CreateOrderParams{
-		ID:       order.ID,
-		ItemType: int32(order.ItemType),
-		ItemName: order.ItemName,
-		TimeUp:   order.TimeUp,
-		Created:  order.Created,
-		Updated: sql.NullTime{
-			Time:  order.Updated,
-			Valid: true,
-		},
-	})
-	if err != nil {
-		slog.Info("failed to call to repo", "error", err)
-
-		return errors.Wrap(err, "baristaOrderedEventHandler-querier.CreateOrder")
-	}
-
-	// todo: it might cause dual-write problem, but we accept it temporary
-	for _, event := range order.DomainEvents() {
-		eventBytes, err := json.Marshal(event)
-		if err != nil {
-			return errors.Wrap(err, "json.Marshal[event]")
+			return errors.Wrap(err, "repo.Create")
 		}
 
-		if err := h.counterPub.Publish(ctx, eventBytes, "text/plain"); err != nil {
-			return errors.Wrap(err, "counterPub.Publish")
-		}
-	}
-
-	return tx.Commit()
+		// publish once the order is stored, a failure here still loses the events (no outbox yet)
+		return postgres.AfterCommit(ctx, func(ctx context.Context) error {
+			for _, event := range order.DomainEvents() {
+				eventBytes, err := json.Marshal(event)
+				if err != nil {
+					return errors.Wrap(err, "json.Marshal[event]")
+				}
+
+				if err := h.counterPub.Publish(ctx, eventBytes, "text/plain"); err != nil {
+					return errors.Wrap(err, "counterPub.Publish")
+				}
+			}
+
+			return nil
+		})
+	})
 }
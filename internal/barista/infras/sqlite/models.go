@@ -0,0 +1,21 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BaristaOrder struct {
+	ID       uuid.UUID    `json:"id"`
+	ItemType int64        `json:"item_type"`
+	ItemName string       `json:"item_name"`
+	TimeUp   time.Time    `json:"time_up"`
+	Created  time.Time    `json:"created"`
+	Updated  sql.NullTime `json:"updated"`
+}
@@ -0,0 +1,49 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: query.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createOrder = `-- name: CreateOrder :exec
+
+INSERT INTO
+    barista_orders (
+        id,
+        item_type,
+        item_name,
+        time_up,
+        created,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateOrderParams struct {
+	ID       uuid.UUID    `json:"id"`
+	ItemType int64        `json:"item_type"`
+	ItemName string       `json:"item_name"`
+	TimeUp   time.Time    `json:"time_up"`
+	Created  time.Time    `json:"created"`
+	Updated  sql.NullTime `json:"updated"`
+}
+
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error {
+	_, err := q.db.ExecContext(ctx, createOrder,
+		arg.ID,
+		arg.ItemType,
+		arg.ItemName,
+		arg.TimeUp,
+		arg.Created,
+		arg.Updated,
+	)
+	return err
+}
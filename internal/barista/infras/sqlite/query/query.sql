@@ -0,0 +1,12 @@
+-- name: CreateOrder :exec
+
+INSERT INTO
+    barista_orders (
+        id,
+        item_type,
+        item_name,
+        time_up,
+        created,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?);
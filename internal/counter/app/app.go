@@ -3,76 +3,149 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"time"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/thangchung/go-coffeeshop/cmd/counter/config"
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
 	"github.com/thangchung/go-coffeeshop/internal/counter/events"
 	ordersUC "github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	reportsUC "github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
 	shared "github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"github.com/thangchung/go-coffeeshop/proto/gen"
 	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
 )
 
 type App struct {
-	Cfg       *config.Config
-	PG        postgres.DBEngine
-	AMQPConn  *amqp.Connection
-	Publisher pkgPublisher.EventPublisher
-	Consumer  pkgConsumer.EventConsumer
+	Cfg      *config.Config
+	PG       postgres.DBEngine
+	Broker   messaging.Broker
+	Consumer messaging.Subscriber
 
 	BaristaOrderPub ordersUC.BaristaEventPublisher
 	KitchenOrderPub ordersUC.KitchenEventPublisher
 
+	ProductConn       *grpc.ClientConn
 	ProductDomainSvc  domain.ProductDomainService
 	UC                ordersUC.UseCase
 	CounterGRPCServer gen.CounterServiceServer
+	ReportUC          reportsUC.UseCase
+	ReportGRPCServer  gen.ReportServiceServer
 
 	baristaHandler events.BaristaOrderUpdatedEventHandler
 	kitchenHandler events.KitchenOrderUpdatedEventHandler
+	catalogHandler events.CatalogChangedEventHandler
 }
 
 func New(
 	cfg *config.Config,
 	pg postgres.DBEngine,
-	amqpConn *amqp.Connection,
-	publisher pkgPublisher.EventPublisher,
-	consumer pkgConsumer.EventConsumer,
+	broker messaging.Broker,
+	consumer messaging.Subscriber,
 
 	baristaOrderPub ordersUC.BaristaEventPublisher,
 	kitchenOrderPub ordersUC.KitchenEventPublisher,
+	productConn *grpc.ClientConn,
 	productDomainSvc domain.ProductDomainService,
 	uc ordersUC.UseCase,
 	counterGRPCServer gen.CounterServiceServer,
+	reportUC reportsUC.UseCase,
+	reportGRPCServer gen.ReportServiceServer,
 
 	baristaHandler events.BaristaOrderUpdatedEventHandler,
 	kitchenHandler events.KitchenOrderUpdatedEventHandler,
+	catalogHandler events.CatalogChangedEventHandler,
 ) *App {
 	return &App{
 		Cfg: cfg,
 
-		PG:        pg,
-		AMQPConn:  amqpConn,
-		Publisher: publisher,
-		Consumer:  consumer,
+		PG:       pg,
+		Broker:   broker,
+		Consumer: consumer,
 
 		BaristaOrderPub: baristaOrderPub,
 		KitchenOrderPub: kitchenOrderPub,
 
+		ProductConn:       productConn,
 		ProductDomainSvc:  productDomainSvc,
 		UC:                uc,
 		CounterGRPCServer: counterGRPCServer,
+		ReportUC:          reportUC,
+		ReportGRPCServer:  reportGRPCServer,
 
 		baristaHandler: baristaHandler,
 		kitchenHandler: kitchenHandler,
+		catalogHandler: catalogHandler,
 	}
 }
 
-func (a *App) Worker(ctx context.Context, messages <-chan amqp.Delivery) {
+// RegisterChecks adds the dependencies of the app to the readiness checks.
+// The product service is optional, the orders are priced from the cache while it is down.
+func (a *App) RegisterChecks(checker *health.Checker) {
+	checker.Register("postgres", health.SQL(a.PG.GetDB()))
+	checker.Register("broker", a.Broker.Check)
+	checker.Register("consumer", a.Consumer.Check)
+	checker.RegisterOptional("product", health.GRPC(a.ProductConn))
+}
+
+// ConfigureRoutes points the publishers at the stations and the consumer at their updates.
+func (a *App) ConfigureRoutes() {
+	a.BaristaOrderPub.Configure(
+		messaging.ExchangeName("barista-order-exchange"),
+		messaging.BindingKey("barista-order-routing-key"),
+		messaging.MessageTypeName("barista-order-created"),
+	)
+
+	a.KitchenOrderPub.Configure(
+		messaging.ExchangeName("kitchen-order-exchange"),
+		messaging.BindingKey("kitchen-order-routing-key"),
+		messaging.MessageTypeName("kitchen-order-created"),
+	)
+
+	a.Consumer.Configure(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.QueueName("counter-order-queue"),
+		messaging.BindingKey("counter-order-routing-key"),
+		messaging.ConsumerTag("counter-order-consumer"),
+	)
+}
+
+// NewCatalogConsumer subscribes to the catalog changes. Every instance drops its own product cache,
+// so each of them gets its own queue.
+func (a *App) NewCatalogConsumer() (messaging.Subscriber, error) {
+	return a.Broker.NewSubscriber(
+		messaging.ExchangeName("product-catalog-exchange"),
+		messaging.BindingKey("product-catalog-routing-key"),
+		messaging.ConsumerTag("counter-catalog-consumer"),
+		messaging.WorkerPoolSize(1),
+		messaging.TransientQueue(),
+	)
+}
+
+// RefreshReports rebuilds the report views every interval until ctx is done.
+func (a *App) RefreshReports(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.ReportUC.RefreshReports(ctx); err != nil {
+				slog.Error("failed to refresh reports", "error", err)
+			}
+		}
+	}
+}
+
+func (a *App) Worker(ctx context.Context, messages <-chan messaging.Delivery) {
 	for delivery := range messages {
+		deliveryCtx, span := messaging.StartConsumeSpan(ctx, delivery)
+
 		slog.Info("processDeliveries", "delivery_tag", delivery.DeliveryTag)
 		slog.Info("received", "delivery_type", delivery.Type)
 
@@ -82,21 +155,21 @@ func (a *App) Worker(ctx context.Context, messages <-chan amqp.Delivery) {
 
 			err := json.Unmarshal(delivery.Body, &payload)
 			if err != nil {
-				slog.Error("failed to Unmarshal message", err)
+				slog.Error("failed to Unmarshal message", "error", err)
 			}
 
-			err = a.baristaHandler.Handle(ctx, &payload)
+			err = a.baristaHandler.Handle(deliveryCtx, &payload)
 
 			if err != nil {
 				if err = delivery.Reject(false); err != nil {
-					slog.Error("failed to delivery.Reject", err)
+					slog.Error("failed to delivery.Reject", "error", err)
 				}
 
-				slog.Error("failed to process delivery", err)
+				slog.Error("failed to process delivery", "error", err)
 			} else {
 				err = delivery.Ack(false)
 				if err != nil {
-					slog.Error("failed to acknowledge delivery", err)
+					slog.Error("failed to acknowledge delivery", "error", err)
 				}
 			}
 		case "kitchen-order-updated":
@@ -104,26 +177,50 @@ func (a *App) Worker(ctx context.Context, messages <-chan amqp.Delivery) {
 
 			err := json.Unmarshal(delivery.Body, &payload)
 			if err != nil {
-				slog.Error("failed to Unmarshal message", err)
+				slog.Error("failed to Unmarshal message", "error", err)
+			}
+
+			err = a.kitchenHandler.Handle(deliveryCtx, &payload)
+
+			if err != nil {
+				if err = delivery.Reject(false); err != nil {
+					slog.Error("failed to delivery.Reject", "error", err)
+				}
+
+				slog.Error("failed to process delivery", "error", err)
+			} else {
+				err = delivery.Ack(false)
+				if err != nil {
+					slog.Error("failed to acknowledge delivery", "error", err)
+				}
+			}
+		case "catalog-changed":
+			var payload shared.CatalogChanged
+
+			err := json.Unmarshal(delivery.Body, &payload)
+			if err != nil {
+				slog.Error("failed to Unmarshal message", "error", err)
 			}
 
-			err = a.kitchenHandler.Handle(ctx, &payload)
+			err = a.catalogHandler.Handle(deliveryCtx, &payload)
 
 			if err != nil {
 				if err = delivery.Reject(false); err != nil {
-					slog.Error("failed to delivery.Reject", err)
+					slog.Error("failed to delivery.Reject", "error", err)
 				}
 
-				slog.Error("failed to process delivery", err)
+				slog.Error("failed to process delivery", "error", err)
 			} else {
 				err = delivery.Ack(false)
 				if err != nil {
-					slog.Error("failed to acknowledge delivery", err)
+					slog.Error("failed to acknowledge delivery", "error", err)
 				}
 			}
 		default:
 			slog.Info("default")
 		}
+
+		span.End()
 	}
 
 	slog.Info("Deliveries channel closed")
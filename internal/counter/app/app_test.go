@@ -0,0 +1,160 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/internal/counter/app"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/events/handlers"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+)
+
+type memOrderRepo struct {
+	mu     sync.Mutex
+	orders map[uuid.UUID]*domain.Order
+}
+
+func (r *memOrderRepo) GetAll(context.Context) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (r *memOrderRepo) GetByID(_ context.Context, id uuid.UUID) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.orders[id], nil
+}
+
+func (r *memOrderRepo) Create(context.Context, *domain.Order) error {
+	return nil
+}
+
+func (r *memOrderRepo) Update(_ context.Context, order *domain.Order) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.orders[order.ID] = order
+
+	return order, nil
+}
+
+// inlineUoW runs fn on the ctx it gets, the fake repos have no transactions.
+type inlineUoW struct{}
+
+func (inlineUoW) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopTimelineRepo struct{}
+
+func (nopTimelineRepo) Append(context.Context, ...*domain.TimelineEntry) error {
+	return nil
+}
+
+func (nopTimelineRepo) GetByOrderID(context.Context, uuid.UUID) ([]*domain.TimelineEntry, error) {
+	return nil, nil
+}
+
+type nopOrderMetrics struct{}
+
+func (nopOrderMetrics) OrderPlaced(*domain.Order) {}
+
+func (nopOrderMetrics) OrderFulfilled(*domain.Order, time.Time) {}
+
+type countingCache struct {
+	mu            sync.Mutex
+	invalidations int
+}
+
+func (c *countingCache) Invalidate() {
+	c.mu.Lock()
+	c.invalidations++
+	c.mu.Unlock()
+}
+
+func TestWorkerHandlesTheStationUpdatesWithoutABroker(t *testing.T) {
+	t.Parallel()
+
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	latte := domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true)
+	order.LineItems = []*domain.LineItem{latte}
+
+	repo := &memOrderRepo{orders: map[uuid.UUID]*domain.Order{order.ID: order}}
+	cache := &countingCache{}
+
+	bus := inmem.NewBus()
+	defer bus.Close()
+
+	consumer, err := bus.NewSubscriber()
+	require.NoError(t, err)
+
+	a := app.New(nil, nil, bus, consumer, nil, nil, nil, nil, nil, nil, nil, nil,
+		handlers.NewBaristaOrderUpdatedEventHandler(inlineUoW{}, repo, nopTimelineRepo{}, nopOrderMetrics{}),
+		handlers.NewKitchenOrderUpdatedEventHandler(inlineUoW{}, repo, nopTimelineRepo{}, nopOrderMetrics{}),
+		handlers.NewCatalogChangedEventHandler(cache),
+	)
+
+	a.Consumer.Configure(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.QueueName("counter-order-queue"),
+		messaging.BindingKey("counter-order-routing-key"),
+	)
+
+	go func() {
+		assert.NoError(t, consumer.Subscribe(a.Worker))
+	}()
+
+	baristaPub, err := bus.NewPublisher(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.BindingKey("counter-order-routing-key"),
+		messaging.MessageTypeName("barista-order-updated"),
+	)
+	require.NoError(t, err)
+
+	publish := func(e any) {
+		body, err := json.Marshal(e)
+		require.NoError(t, err)
+		require.NoError(t, baristaPub.Publish(context.Background(), body, "text/plain"))
+	}
+
+	publish(event.BaristaOrderUpdated{
+		OrderID:    order.ID,
+		ItemLineID: latte.ID,
+		Name:       latte.Name,
+		ItemType:   latte.ItemType,
+		MadeBy:     "barista",
+		TimeIn:     time.Now(),
+		TimeUp:     time.Now(),
+	})
+	// an unknown order is rejected instead of blocking the queue
+	publish(event.BaristaOrderUpdated{OrderID: uuid.New(), ItemLineID: uuid.New()})
+
+	catalogPub, err := bus.NewPublisher(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.BindingKey("counter-order-routing-key"),
+		messaging.MessageTypeName("catalog-changed"),
+	)
+	require.NoError(t, err)
+	require.NoError(t, catalogPub.Publish(context.Background(), []byte(`{}`), "text/plain"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, bus.WaitIdle(ctx))
+
+	fulfilled, err := repo.GetByID(context.Background(), order.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, shared.StatusFulfilled, fulfilled.OrderStatus)
+	assert.Equal(t, 1, cache.invalidations)
+}
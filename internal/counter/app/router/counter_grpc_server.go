@@ -12,10 +12,13 @@ import (
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
 	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
 	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
 	gen "github.com/thangchung/go-coffeeshop/proto/gen"
 	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type counterGRPCServer struct {
@@ -63,7 +66,7 @@ func (g *counterGRPCServer) GetListOrderFulfillment(
 			Id:              entity.ID.String(),
 			OrderSource:     int32(entity.OrderSource),
 			OrderStatus:     int32(entity.OrderStatus),
-			Localtion:       int32(entity.Location),
+			Localtion:       locationDto(entity.Location),
 			LoyaltyMemberId: entity.LoyaltyMemberID.String(),
 			LineItems: lo.Map(entity.LineItems, func(item *domain.LineItem, _ int) *gen.LineItemDto {
 				return &gen.LineItemDto{
@@ -89,7 +92,7 @@ func (g *counterGRPCServer) PlaceOrder(
 
 	loyaltyMemberID, err := uuid.Parse(request.LoyaltyMemberId)
 	if err != nil {
-		return nil, errors.Wrap(err, "uuid.Parse")
+		return nil, grpcerror.BadRequest(g.cfg.Name, grpcerror.FieldViolation("loyalty_member_id", "must be a UUID"))
 	}
 
 	model := domain.PlaceOrderModel{
@@ -112,12 +115,83 @@ func (g *counterGRPCServer) PlaceOrder(
 		})
 	}
 
-	err = g.uc.PlaceOrder(ctx, &model)
+	id, err := g.uc.PlaceOrder(ctx, &model)
 	if err != nil {
+		var unorderable *domain.UnorderableItemsError
+		if errors.As(err, &unorderable) {
+			return nil, unorderableItemsError(g.cfg.Name, request, unorderable)
+		}
+
 		return nil, errors.Wrap(err, "uc.PlaceOrder")
 	}
 
-	res := gen.PlaceOrderResponse{}
+	res := gen.PlaceOrderResponse{
+		Id: id.String(),
+	}
+
+	return &res, nil
+}
+
+func (g *counterGRPCServer) GetOrderTimeline(
+	ctx context.Context,
+	request *gen.GetOrderTimelineRequest,
+) (*gen.GetOrderTimelineResponse, error) {
+	slog.Info("GET: GetOrderTimeline", "order_id", request.OrderId)
+
+	orderID, err := uuid.Parse(request.OrderId)
+	if err != nil {
+		return nil, grpcerror.BadRequest(g.cfg.Name, grpcerror.FieldViolation("order_id", "must be a UUID"))
+	}
+
+	entries, err := g.uc.GetOrderTimeline(ctx, orderID)
+	if err != nil {
+		return nil, errors.Wrap(err, "uc.GetOrderTimeline")
+	}
+
+	res := gen.GetOrderTimelineResponse{
+		Entries: lo.Map(entries, func(entry *domain.TimelineEntry, _ int) *gen.TimelineEntryDto {
+			return &gen.TimelineEntryDto{
+				LineItemId: entry.LineItemID.String(),
+				ItemType:   int32(entry.ItemType),
+				Name:       entry.Name,
+				Stage:      string(entry.Stage),
+				Station:    string(entry.Station),
+				Actor:      entry.Actor,
+				OccurredAt: timestamppb.New(entry.OccurredAt),
+			}
+		}),
+	}
 
 	return &res, nil
 }
+
+// unorderableItemsError points to each rejected item of the request, e.g. barista_items[1].item_type.
+func unorderableItemsError(domainName string, request *gen.PlaceOrderRequest, unorderable *domain.UnorderableItemsError) error {
+	reasons := lo.SliceToMap(unorderable.Items, func(item *domain.MissingItem) (int32, string) {
+		return int32(item.ItemType), fmt.Sprintf("%s is %s", item.ItemType, item.Reason)
+	})
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(unorderable.Items))
+
+	collect := func(field string, items []*gen.CommandItem) {
+		for i, item := range items {
+			if reason, ok := reasons[item.ItemType]; ok {
+				violations = append(violations, grpcerror.FieldViolation(fmt.Sprintf("%s[%d].item_type", field, i), reason))
+			}
+		}
+	}
+
+	collect("barista_items", request.BaristaItems)
+	collect("kitchen_items", request.KitchenItems)
+
+	return grpcerror.BadRequest(domainName, violations...)
+}
+
+// locationDto leaves the location of the orders without one unset, the Location enum has no value for them.
+func locationDto(location shared.Location) *int32 {
+	if location == shared.LocationUnknown {
+		return nil
+	}
+
+	return lo.ToPtr(int32(location))
+}
@@ -0,0 +1,16 @@
+package router
+
+import (
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrorRules are the statuses of the counter service errors, anything else is an internal error.
+var ErrorRules = []grpcerror.Rule{
+	{Target: domain.ErrOrderNotFound, Code: codes.NotFound, Reason: "ORDER_NOT_FOUND"},
+	{Target: domain.ErrItemNotFound, Code: codes.NotFound, Reason: "ITEM_NOT_FOUND"},
+	{Target: domain.ErrOrderVersionConflict, Code: codes.Aborted, Reason: "ORDER_VERSION_CONFLICT"},
+	{Target: domain.ErrInvalidReportGroup, Code: codes.InvalidArgument, Reason: "INVALID_REPORT_GROUP"},
+	{Target: domain.ErrInvalidReportRange, Code: codes.InvalidArgument, Reason: "INVALID_REPORT_RANGE"},
+}
@@ -0,0 +1,220 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"strconv"
+
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/api/httpbody"
+	"google.golang.org/grpc"
+)
+
+const _csvContentType = "text/csv"
+
+type reportGRPCServer struct {
+	gen.UnimplementedReportServiceServer
+	uc reports.UseCase
+}
+
+var _ gen.ReportServiceServer = (*reportGRPCServer)(nil)
+
+var ReportGRPCServerSet = wire.NewSet(NewGRPCReportServer)
+
+func NewGRPCReportServer(
+	grpcServer *grpc.Server,
+	uc reports.UseCase,
+) gen.ReportServiceServer {
+	svc := reportGRPCServer{
+		uc: uc,
+	}
+
+	gen.RegisterReportServiceServer(grpcServer, &svc)
+
+	return &svc
+}
+
+func (g *reportGRPCServer) GetSalesReport(
+	ctx context.Context,
+	request *gen.GetSalesReportRequest,
+) (*gen.GetSalesReportResponse, error) {
+	slog.Info("GET: GetSalesReport", "from", request.FromDate, "to", request.ToDate, "group_by", request.GroupBy)
+
+	rows, err := g.salesReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := gen.GetSalesReportResponse{
+		Rows: lo.Map(rows, func(row *domain.SalesReportRow, _ int) *gen.SalesReportRowDto {
+			return &gen.SalesReportRowDto{
+				Day:           row.Key.Day,
+				Location:      row.Key.Location,
+				ItemType:      row.Key.ItemType,
+				Orders:        row.Orders,
+				Items:         row.Items,
+				Revenue:       row.Revenue,
+				AverageTicket: row.AverageTicket,
+			}
+		}),
+	}
+
+	return &res, nil
+}
+
+func (g *reportGRPCServer) GetPrepTimeReport(
+	ctx context.Context,
+	request *gen.GetPrepTimeReportRequest,
+) (*gen.GetPrepTimeReportResponse, error) {
+	slog.Info("GET: GetPrepTimeReport", "from", request.FromDate, "to", request.ToDate, "group_by", request.GroupBy)
+
+	rows, err := g.prepTimeReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	res := gen.GetPrepTimeReportResponse{
+		Rows: lo.Map(rows, func(row *domain.PrepTimeReportRow, _ int) *gen.PrepTimeReportRowDto {
+			return &gen.PrepTimeReportRowDto{
+				Day:             row.Key.Day,
+				Location:        row.Key.Location,
+				ItemType:        row.Key.ItemType,
+				Station:         row.Key.Station,
+				Actor:           row.Key.Actor,
+				Items:           row.Items,
+				AvgPrepSeconds:  row.AvgPrepSeconds,
+				P50PrepSeconds:  row.P50PrepSeconds,
+				P90PrepSeconds:  row.P90PrepSeconds,
+				P95PrepSeconds:  row.P95PrepSeconds,
+				AvgTotalSeconds: row.AvgTotalSeconds,
+			}
+		}),
+	}
+
+	return &res, nil
+}
+
+func (g *reportGRPCServer) ExportSalesReport(
+	ctx context.Context,
+	request *gen.GetSalesReportRequest,
+) (*httpbody.HttpBody, error) {
+	slog.Info("GET: ExportSalesReport", "from", request.FromDate, "to", request.ToDate, "group_by", request.GroupBy)
+
+	rows, err := g.salesReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{{"day", "location", "item_type", "orders", "items", "revenue", "average_ticket"}}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Key.Day,
+			row.Key.Location,
+			row.Key.ItemType,
+			strconv.FormatInt(row.Orders, 10),
+			strconv.FormatInt(row.Items, 10),
+			formatFloat(row.Revenue),
+			formatFloat(row.AverageTicket),
+		})
+	}
+
+	return csvBody(records)
+}
+
+func (g *reportGRPCServer) ExportPrepTimeReport(
+	ctx context.Context,
+	request *gen.GetPrepTimeReportRequest,
+) (*httpbody.HttpBody, error) {
+	slog.Info("GET: ExportPrepTimeReport", "from", request.FromDate, "to", request.ToDate, "group_by", request.GroupBy)
+
+	rows, err := g.prepTimeReport(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	records := [][]string{{
+		"day", "location", "item_type", "station", "actor", "items",
+		"avg_prep_seconds", "p50_prep_seconds", "p90_prep_seconds", "p95_prep_seconds", "avg_total_seconds",
+	}}
+	for _, row := range rows {
+		records = append(records, []string{
+			row.Key.Day,
+			row.Key.Location,
+			row.Key.ItemType,
+			row.Key.Station,
+			row.Key.Actor,
+			strconv.FormatInt(row.Items, 10),
+			formatFloat(row.AvgPrepSeconds),
+			formatFloat(row.P50PrepSeconds),
+			formatFloat(row.P90PrepSeconds),
+			formatFloat(row.P95PrepSeconds),
+			formatFloat(row.AvgTotalSeconds),
+		})
+	}
+
+	return csvBody(records)
+}
+
+func (g *reportGRPCServer) salesReport(
+	ctx context.Context,
+	request *gen.GetSalesReportRequest,
+) ([]*domain.SalesReportRow, error) {
+	reportRange, err := domain.NewReportRange(request.FromDate, request.ToDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.NewReportRange")
+	}
+
+	rows, err := g.uc.GetSalesReport(ctx, reportRange, toReportGroups(request.GroupBy))
+	if err != nil {
+		return nil, errors.Wrap(err, "uc.GetSalesReport")
+	}
+
+	return rows, nil
+}
+
+func (g *reportGRPCServer) prepTimeReport(
+	ctx context.Context,
+	request *gen.GetPrepTimeReportRequest,
+) ([]*domain.PrepTimeReportRow, error) {
+	reportRange, err := domain.NewReportRange(request.FromDate, request.ToDate)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.NewReportRange")
+	}
+
+	rows, err := g.uc.GetPrepTimeReport(ctx, reportRange, toReportGroups(request.GroupBy))
+	if err != nil {
+		return nil, errors.Wrap(err, "uc.GetPrepTimeReport")
+	}
+
+	return rows, nil
+}
+
+func toReportGroups(groupBy []string) []domain.ReportGroupBy {
+	return lo.Map(groupBy, func(g string, _ int) domain.ReportGroupBy {
+		return domain.ReportGroupBy(g)
+	})
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+func csvBody(records [][]string) (*httpbody.HttpBody, error) {
+	var buf bytes.Buffer
+
+	if err := csv.NewWriter(&buf).WriteAll(records); err != nil {
+		return nil, errors.Wrap(err, "csv.WriteAll")
+	}
+
+	return &httpbody.HttpBody{
+		ContentType: _csvContentType,
+		Data:        buf.Bytes(),
+	}, nil
+}
@@ -13,10 +13,10 @@ import (
 	infrasGRPC "github.com/thangchung/go-coffeeshop/internal/counter/infras/grpc"
 	"github.com/thangchung/go-coffeeshop/internal/counter/infras/repo"
 	ordersUC "github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	reportsUC "github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"google.golang.org/grpc"
 )
 
@@ -29,23 +29,59 @@ func InitApp(
 	panic(wire.Build(
 		New,
 		dbEngineFunc,
+		postgres.UnitOfWorkSet,
 		rabbitMQFunc,
-		pkgPublisher.EventPublisherSet,
-		pkgConsumer.EventConsumerSet,
+		rabbitmq.BrokerSet,
+		messaging.SubscriberSet,
 
 		infras.BaristaEventPublisherSet,
 		infras.KitchenEventPublisherSet,
+		infras.OrderMetricsSet,
 		infrasGRPC.ProductGRPCClientSet,
 		router.CounterGRPCServerSet,
+		router.ReportGRPCServerSet,
 		repo.RepositorySet,
+		repo.ReportRepositorySet,
 		ordersUC.UseCaseSet,
+		reportsUC.UseCaseSet,
 		handlers.BaristaOrderUpdatedEventHandlerSet,
 		handlers.KitchenOrderUpdatedEventHandlerSet,
+		handlers.CatalogChangedEventHandlerSet,
 	))
 }
 
-func dbEngineFunc(url postgres.DBConnString) (postgres.DBEngine, func(), error) {
-	db, err := postgres.NewPostgresDB(url)
+// InitInProcessApp builds the app on a database, a broker and a connection to the product service
+// shared with the other services of the process.
+func InitInProcessApp(
+	cfg *config.Config,
+	pg postgres.DBEngine,
+	broker messaging.Broker,
+	productConn *grpc.ClientConn,
+	grpcServer *grpc.Server,
+) (*App, error) {
+	panic(wire.Build(
+		New,
+		postgres.UnitOfWorkSet,
+		messaging.SubscriberSet,
+
+		infras.BaristaEventPublisherSet,
+		infras.KitchenEventPublisherSet,
+		infras.OrderMetricsSet,
+		infrasGRPC.ProductClientSet,
+		router.CounterGRPCServerSet,
+		router.ReportGRPCServerSet,
+		repo.RepositorySet,
+		repo.ReportRepositorySet,
+		ordersUC.UseCaseSet,
+		reportsUC.UseCaseSet,
+		handlers.BaristaOrderUpdatedEventHandlerSet,
+		handlers.KitchenOrderUpdatedEventHandlerSet,
+		handlers.CatalogChangedEventHandlerSet,
+	))
+}
+
+func dbEngineFunc(cfg *config.Config, url postgres.DBConnString) (postgres.DBEngine, func(), error) {
+	db, err := postgres.New(cfg.PG.Driver, url, postgres.Pool(cfg.PG), postgres.Name(cfg.Name))
 	if err != nil {
 		return nil, nil, err
 	}
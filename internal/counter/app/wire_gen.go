@@ -15,17 +15,17 @@ import (
 	grpc2 "github.com/thangchung/go-coffeeshop/internal/counter/infras/grpc"
 	"github.com/thangchung/go-coffeeshop/internal/counter/infras/repo"
 	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"google.golang.org/grpc"
 )
 
 // Injectors from wire.go:
 
 func InitApp(cfg *config.Config, dbConnStr postgres.DBConnString, rabbitMQConnStr rabbitmq.RabbitMQConnStr, grpcServer *grpc.Server) (*App, func(), error) {
-	dbEngine, cleanup, err := dbEngineFunc(dbConnStr)
+	dbEngine, cleanup, err := dbEngineFunc(cfg, dbConnStr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -34,42 +34,97 @@ func InitApp(cfg *config.Config, dbConnStr postgres.DBConnString, rabbitMQConnSt
 		cleanup()
 		return nil, nil, err
 	}
-	eventPublisher, err := publisher.NewPublisher(connection)
+	broker := rabbitmq.NewBroker(connection)
+	subscriber, err := messaging.NewSubscriber(broker)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	eventConsumer, err := consumer.NewConsumer(connection)
+	baristaEventPublisher, err := infras.NewBaristaEventPublisher(broker)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	baristaEventPublisher := infras.NewBaristaEventPublisher(eventPublisher)
-	kitchenEventPublisher := infras.NewKitchenEventPublisher(eventPublisher)
-	productDomainService, err := grpc2.NewGRPCProductClient(cfg)
+	kitchenEventPublisher, err := infras.NewKitchenEventPublisher(broker)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	orderRepo := repo.NewOrderRepo(dbEngine)
-	useCase := orders.NewUseCase(orderRepo, productDomainService, baristaEventPublisher, kitchenEventPublisher)
+	clientConn, cleanup3, err := grpc2.NewProductConn(cfg)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	cachingProductClient := grpc2.NewProductClient(cfg, clientConn)
+	unitOfWork := postgres.NewUnitOfWork(dbEngine)
+	orderRepo, err := repo.NewOrderRepoFromConfig(cfg, dbEngine, unitOfWork)
+	if err != nil {
+		cleanup3()
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	timelineRepo := repo.NewTimelineRepoFromConfig(cfg, dbEngine)
+	orderMetrics := infras.NewOrderMetrics()
+	useCase := orders.NewUseCase(unitOfWork, orderRepo, timelineRepo, cachingProductClient, baristaEventPublisher, kitchenEventPublisher, orderMetrics)
 	counterServiceServer := router.NewGRPCCounterServer(grpcServer, cfg, useCase)
-	baristaOrderUpdatedEventHandler := handlers.NewBaristaOrderUpdatedEventHandler(orderRepo)
-	kitchenOrderUpdatedEventHandler := handlers.NewKitchenOrderUpdatedEventHandler(orderRepo)
-	app := New(cfg, dbEngine, connection, eventPublisher, eventConsumer, baristaEventPublisher, kitchenEventPublisher, productDomainService, useCase, counterServiceServer, baristaOrderUpdatedEventHandler, kitchenOrderUpdatedEventHandler)
+	reportRepo := repo.NewReportRepoFromConfig(cfg, dbEngine)
+	reportsUseCase := reports.NewUseCase(reportRepo)
+	reportServiceServer := router.NewGRPCReportServer(grpcServer, reportsUseCase)
+	baristaOrderUpdatedEventHandler := handlers.NewBaristaOrderUpdatedEventHandler(unitOfWork, orderRepo, timelineRepo, orderMetrics)
+	kitchenOrderUpdatedEventHandler := handlers.NewKitchenOrderUpdatedEventHandler(unitOfWork, orderRepo, timelineRepo, orderMetrics)
+	catalogChangedEventHandler := handlers.NewCatalogChangedEventHandler(cachingProductClient)
+	app := New(cfg, dbEngine, broker, subscriber, baristaEventPublisher, kitchenEventPublisher, clientConn, cachingProductClient, useCase, counterServiceServer, reportsUseCase, reportServiceServer, baristaOrderUpdatedEventHandler, kitchenOrderUpdatedEventHandler, catalogChangedEventHandler)
 	return app, func() {
+		cleanup3()
 		cleanup2()
 		cleanup()
 	}, nil
 }
 
+// InitInProcessApp builds the app on a database, a broker and a connection to the product service
+// shared with the other services of the process.
+func InitInProcessApp(cfg *config.Config, pg postgres.DBEngine, broker messaging.Broker, productConn *grpc.ClientConn, grpcServer *grpc.Server) (*App, error) {
+	subscriber, err := messaging.NewSubscriber(broker)
+	if err != nil {
+		return nil, err
+	}
+	baristaEventPublisher, err := infras.NewBaristaEventPublisher(broker)
+	if err != nil {
+		return nil, err
+	}
+	kitchenEventPublisher, err := infras.NewKitchenEventPublisher(broker)
+	if err != nil {
+		return nil, err
+	}
+	cachingProductClient := grpc2.NewProductClient(cfg, productConn)
+	unitOfWork := postgres.NewUnitOfWork(pg)
+	orderRepo, err := repo.NewOrderRepoFromConfig(cfg, pg, unitOfWork)
+	if err != nil {
+		return nil, err
+	}
+	timelineRepo := repo.NewTimelineRepoFromConfig(cfg, pg)
+	orderMetrics := infras.NewOrderMetrics()
+	useCase := orders.NewUseCase(unitOfWork, orderRepo, timelineRepo, cachingProductClient, baristaEventPublisher, kitchenEventPublisher, orderMetrics)
+	counterServiceServer := router.NewGRPCCounterServer(grpcServer, cfg, useCase)
+	reportRepo := repo.NewReportRepoFromConfig(cfg, pg)
+	reportsUseCase := reports.NewUseCase(reportRepo)
+	reportServiceServer := router.NewGRPCReportServer(grpcServer, reportsUseCase)
+	baristaOrderUpdatedEventHandler := handlers.NewBaristaOrderUpdatedEventHandler(unitOfWork, orderRepo, timelineRepo, orderMetrics)
+	kitchenOrderUpdatedEventHandler := handlers.NewKitchenOrderUpdatedEventHandler(unitOfWork, orderRepo, timelineRepo, orderMetrics)
+	catalogChangedEventHandler := handlers.NewCatalogChangedEventHandler(cachingProductClient)
+	app := New(cfg, pg, broker, subscriber, baristaEventPublisher, kitchenEventPublisher, productConn, cachingProductClient, useCase, counterServiceServer, reportsUseCase, reportServiceServer, baristaOrderUpdatedEventHandler, kitchenOrderUpdatedEventHandler, catalogChangedEventHandler)
+	return app, nil
+}
+
 // wire.go:
 
-func dbEngineFunc(url postgres.DBConnString) (postgres.DBEngine, func(), error) {
-	db, err := postgres.NewPostgresDB(url)
+func dbEngineFunc(cfg *config.Config, url postgres.DBConnString) (postgres.DBEngine, func(), error) {
+	db, err := postgres.New(cfg.PG.Driver, url, postgres.Pool(cfg.PG), postgres.Name(cfg.Name))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1,7 +1,28 @@
 package domain
 
-import "github.com/pkg/errors"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
 
 var (
-	ErrItemNotFound = errors.New("item not found")
+	ErrItemNotFound         = errors.New("item not found")
+	ErrOrderNotFound        = errors.New("order not found")
+	ErrOrderVersionConflict = errors.New("order version conflict")
+	ErrInvalidReportGroup   = errors.New("invalid report group")
+	ErrInvalidReportRange   = errors.New("invalid report range")
 )
+
+// UnorderableItemsError rejects an order with items which are unknown or not on the menu.
+type UnorderableItemsError struct {
+	Items []*MissingItem
+}
+
+func (e *UnorderableItemsError) Error() string {
+	return "items can't be ordered: " + strings.Join(lo.Map(e.Items, func(item *MissingItem, _ int) string {
+		return fmt.Sprintf("%s (%s)", item.ItemType, item.Reason)
+	}), ", ")
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+// The events below describe every state change of the Order aggregate.
+// They are kept next to the aggregate because only counter persists and replays them;
+// the integration events published to barista/kitchen live in internal/pkg/event.
+
+type OrderPlaced struct {
+	OrderID         uuid.UUID          `json:"orderId"`
+	OrderSource     shared.OrderSource `json:"orderSource"`
+	LoyaltyMemberID uuid.UUID          `json:"loyaltyMemberId"`
+	OrderStatus     shared.Status      `json:"orderStatus"`
+	Location        shared.Location    `json:"location"`
+	Created         time.Time          `json:"created"`
+}
+
+func (e *OrderPlaced) Identity() string {
+	return "OrderPlaced"
+}
+
+func (e *OrderPlaced) CreateAt() time.Time {
+	return e.Created
+}
+
+type LineItemAdded struct {
+	OrderID        uuid.UUID       `json:"orderId"`
+	ItemLineID     uuid.UUID       `json:"itemLineId"`
+	ItemType       shared.ItemType `json:"itemType"`
+	Name           string          `json:"name"`
+	Price          float32         `json:"price"`
+	ItemStatus     shared.Status   `json:"itemStatus"`
+	IsBaristaOrder bool            `json:"isBaristaOrder"`
+	Created        time.Time       `json:"created"`
+}
+
+func (e *LineItemAdded) Identity() string {
+	return "LineItemAdded"
+}
+
+func (e *LineItemAdded) CreateAt() time.Time {
+	return e.Created
+}
+
+type LineItemFulfilled struct {
+	OrderID    uuid.UUID `json:"orderId"`
+	ItemLineID uuid.UUID `json:"itemLineId"`
+	MadeBy     string    `json:"madeBy"`
+	TimeUp     time.Time `json:"timeUp"`
+	Created    time.Time `json:"created"`
+}
+
+func (e *LineItemFulfilled) Identity() string {
+	return "LineItemFulfilled"
+}
+
+func (e *LineItemFulfilled) CreateAt() time.Time {
+	return e.Created
+}
+
+type OrderFulfilled struct {
+	OrderID uuid.UUID `json:"orderId"`
+	Created time.Time `json:"created"`
+}
+
+func (e *OrderFulfilled) Identity() string {
+	return "OrderFulfilled"
+}
+
+func (e *OrderFulfilled) CreateAt() time.Time {
+	return e.Created
+}
@@ -2,10 +2,25 @@ package domain
 
 import (
 	"context"
+	"time"
+
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
 )
 
 type (
 	ProductDomainService interface {
-		GetItemsByType(context.Context, *PlaceOrderModel, bool) ([]*ItemModel, error)
+		// LookupItems prices the item types on the menu of the location at the given time,
+		// the ones which can't be ordered are returned as missing.
+		LookupItems(
+			ctx context.Context,
+			location shared.Location,
+			itemTypes []shared.ItemType,
+			at time.Time,
+		) ([]*ItemModel, []*MissingItem, error)
+	}
+
+	// ProductCatalogCache is a local copy of the product catalog, it goes stale when the catalog changes.
+	ProductCatalogCache interface {
+		Invalidate()
 	}
 )
@@ -23,5 +23,23 @@ type OrderItemModel struct {
 
 type ItemModel struct {
 	ItemType shared.ItemType
-	Price    float64
+	// Name is the name of the item in the catalog.
+	Name  string
+	Price float64
+	// ValidUntil is when the menu next changes the price, zero when it never does.
+	ValidUntil time.Time
+}
+
+const (
+	MissingReasonUnknown     = "unknown"
+	MissingReasonUnavailable = "unavailable"
+)
+
+// MissingItem is an item of an order which can't be ordered, Reason is unknown or unavailable.
+// ValidUntil is when an unavailable item may come on the menu, zero when it never does.
+type MissingItem struct {
+	ItemType   shared.ItemType
+	Name       string
+	Reason     string
+	ValidUntil time.Time
 }
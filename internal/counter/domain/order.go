@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/samber/lo"
@@ -17,6 +18,7 @@ type Order struct {
 	OrderStatus     shared.Status
 	Location        shared.Location
 	LineItems       []*LineItem
+	Version         int32
 }
 
 func NewOrder(
@@ -39,71 +41,69 @@ func CreateOrderFrom(
 	request *PlaceOrderModel,
 	productDomainSvc ProductDomainService,
 ) (*Order, error) {
-	order := NewOrder(request.OrderSource, request.LoyaltyMemberID, shared.StatusInProcess, request.Location)
-
-	numberOfBaristaItems := len(request.BaristaItems) > 0
-	numberOfKitchenItems := len(request.KitchenItems) > 0
-
-	if numberOfBaristaItems {
-		itemTypesRes, err := productDomainSvc.GetItemsByType(ctx, request, true)
-		if err != nil {
-			return nil, err
-		}
-
-		lo.ForEach(request.BaristaItems, func(item *OrderItemModel, _ int) {
-			find, ok := lo.Find(itemTypesRes, func(i *ItemModel) bool {
-				return i.ItemType == item.ItemType
-			})
+	// the items are priced when the order is placed, later changes to the menu don't touch it
+	placedAt := time.Now()
+
+	order := &Order{}
+	order.raise(&OrderPlaced{
+		OrderID:         uuid.New(),
+		OrderSource:     request.OrderSource,
+		LoyaltyMemberID: request.LoyaltyMemberID,
+		OrderStatus:     shared.StatusInProcess,
+		Location:        request.Location,
+		Created:         placedAt,
+	})
 
-			if ok {
-				lineItem := NewLineItem(item.ItemType, item.ItemType.String(), float32(find.Price), shared.StatusInProcess, true)
+	toItemType := func(item *OrderItemModel, _ int) shared.ItemType { return item.ItemType }
+	itemTypes := append(lo.Map(request.BaristaItems, toItemType), lo.Map(request.KitchenItems, toItemType)...)
 
-				event := events.BaristaOrdered{
-					OrderID:    order.ID,
-					ItemLineID: lineItem.ID,
-					ItemType:   item.ItemType,
-				}
+	if len(itemTypes) == 0 {
+		return order, nil
+	}
 
-				order.ApplyDomain(event)
+	items, missing, err := productDomainSvc.LookupItems(ctx, request.Location, itemTypes, placedAt)
+	if err != nil {
+		return nil, err
+	}
 
-				order.LineItems = append(order.LineItems, lineItem)
-			}
-		})
+	found := lo.KeyBy(items, func(item *ItemModel) shared.ItemType {
+		return item.ItemType
+	})
 
-		if err != nil {
-			return nil, err
+	// the product service answers for every type, one it skipped is unknown to it
+	for _, itemType := range itemTypes {
+		if _, ok := found[itemType]; !ok {
+			missing = append(missing, &MissingItem{ItemType: itemType, Reason: MissingReasonUnknown})
 		}
 	}
 
-	if numberOfKitchenItems {
-		itemTypesRes, err := productDomainSvc.GetItemsByType(ctx, request, false)
-		if err != nil {
-			return nil, err
-		}
-
-		lo.ForEach(request.KitchenItems, func(item *OrderItemModel, index int) {
-			find, ok := lo.Find(itemTypesRes, func(i *ItemModel) bool {
-				return i.ItemType == item.ItemType
-			})
+	// the whole order is rejected rather than placed without the items it can't price
+	if len(missing) > 0 {
+		return nil, &UnorderableItemsError{Items: lo.UniqBy(missing, func(item *MissingItem) shared.ItemType {
+			return item.ItemType
+		})}
+	}
 
-			if ok {
-				lineItem := NewLineItem(item.ItemType, item.ItemType.String(), float32(find.Price), shared.StatusInProcess, false)
+	for _, item := range request.BaristaItems {
+		lineItem := order.addLineItem(found[item.ItemType], true)
 
-				event := events.KitchenOrdered{
-					OrderID:    order.ID,
-					ItemLineID: lineItem.ID,
-					ItemType:   item.ItemType,
-				}
+		order.ApplyDomain(events.BaristaOrdered{
+			OrderID:    order.ID,
+			ItemLineID: lineItem.ID,
+			ItemType:   item.ItemType,
+			Name:       lineItem.Name,
+		})
+	}
 
-				order.ApplyDomain(event)
+	for _, item := range request.KitchenItems {
+		lineItem := order.addLineItem(found[item.ItemType], false)
 
-				order.LineItems = append(order.LineItems, lineItem)
-			}
+		order.ApplyDomain(events.KitchenOrdered{
+			OrderID:    order.ID,
+			ItemLineID: lineItem.ID,
+			ItemType:   item.ItemType,
+			Name:       lineItem.Name,
 		})
-
-		if err != nil {
-			return nil, err
-		}
 	}
 
 	return order, nil
@@ -114,23 +114,111 @@ func (o *Order) Apply(event *events.OrderUp) error {
 		return nil // we dont do anything
 	}
 
-	_, index, ok := lo.FindIndexOf(o.LineItems, func(i *LineItem) bool {
-		return i.ItemType == event.ItemType
+	item, ok := lo.Find(o.LineItems, func(i *LineItem) bool {
+		return i.ID == event.ItemLineID
 	})
 
 	if !ok {
 		return ErrItemNotFound
 	}
 
-	o.LineItems[index].ItemStatus = shared.StatusFulfilled
+	if item.ItemStatus == shared.StatusFulfilled {
+		return nil // the station has already reported it, e.g. a redelivered message
+	}
 
-	if checkFulfilledStatus(o.LineItems) {
-		o.OrderStatus = shared.StatusFulfilled
+	o.raise(&LineItemFulfilled{
+		OrderID:    o.ID,
+		ItemLineID: event.ItemLineID,
+		MadeBy:     event.MadeBy,
+		TimeUp:     event.TimeUp,
+		Created:    time.Now(),
+	})
+
+	if o.OrderStatus != shared.StatusFulfilled && checkFulfilledStatus(o.LineItems) {
+		o.raise(&OrderFulfilled{
+			OrderID: o.ID,
+			Created: time.Now(),
+		})
 	}
 
 	return nil
 }
 
+// JustFulfilled tells whether the changes made since the order was loaded fulfilled it.
+func (o *Order) JustFulfilled() bool {
+	return lo.ContainsBy(o.DomainEvents(), func(e shared.DomainEvent) bool {
+		_, ok := e.(*OrderFulfilled)
+
+		return ok
+	})
+}
+
+// Replay rebuilds the state of the order from its history, it is used by the event-sourced repository.
+// Events which do not change the state of the order (e.g. integration events) are skipped.
+func (o *Order) Replay(history []shared.DomainEvent) {
+	for _, e := range history {
+		o.when(e)
+	}
+}
+
+// addLineItem adds the item under its name in the catalog, the item types added by an import have no other.
+func (o *Order) addLineItem(item *ItemModel, isBarista bool) *LineItem {
+	name := item.Name
+	if name == "" {
+		name = item.ItemType.String()
+	}
+
+	e := &LineItemAdded{
+		OrderID:        o.ID,
+		ItemLineID:     uuid.New(),
+		ItemType:       item.ItemType,
+		Name:           name,
+		Price:          float32(item.Price),
+		ItemStatus:     shared.StatusInProcess,
+		IsBaristaOrder: isBarista,
+		Created:        time.Now(),
+	}
+
+	o.raise(e)
+
+	return o.LineItems[len(o.LineItems)-1]
+}
+
+// raise records the event as a new domain event and mutates the state of the order accordingly.
+func (o *Order) raise(e shared.DomainEvent) {
+	o.when(e)
+	o.ApplyDomain(e)
+}
+
+func (o *Order) when(e shared.DomainEvent) {
+	switch e := e.(type) {
+	case *OrderPlaced:
+		o.ID = e.OrderID
+		o.OrderSource = e.OrderSource
+		o.LoyaltyMemberID = e.LoyaltyMemberID
+		o.OrderStatus = e.OrderStatus
+		o.Location = e.Location
+	case *LineItemAdded:
+		o.LineItems = append(o.LineItems, &LineItem{
+			ID:             e.ItemLineID,
+			ItemType:       e.ItemType,
+			Name:           e.Name,
+			Price:          e.Price,
+			ItemStatus:     e.ItemStatus,
+			IsBaristaOrder: e.IsBaristaOrder,
+			OrderID:        e.OrderID,
+		})
+	case *LineItemFulfilled:
+		for _, item := range o.LineItems {
+			if item.ID == e.ItemLineID {
+				item.ItemStatus = shared.StatusFulfilled
+			}
+		}
+	case *OrderFulfilled:
+		o.OrderStatus = shared.StatusFulfilled
+	}
+}
+
 func checkFulfilledStatus(lineItems []*LineItem) bool {
 	for _, item := range lineItems {
 		if item.ItemStatus != shared.StatusFulfilled {
@@ -0,0 +1,118 @@
+package domain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+type fixedPriceProductSvc struct{}
+
+func (fixedPriceProductSvc) LookupItems(
+	_ context.Context,
+	_ shared.Location,
+	itemTypes []shared.ItemType,
+	_ time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	results := make([]*domain.ItemModel, 0, len(itemTypes))
+	missing := make([]*domain.MissingItem, 0)
+
+	for _, itemType := range itemTypes {
+		if itemType > shared.ItemTypeCroissantChocolate {
+			missing = append(missing, &domain.MissingItem{ItemType: itemType, Reason: domain.MissingReasonUnknown})
+
+			continue
+		}
+
+		results = append(results, &domain.ItemModel{ItemType: itemType, Price: 3})
+	}
+
+	return results, missing, nil
+}
+
+func TestReplayRebuildsOrder(t *testing.T) {
+	t.Parallel()
+
+	order, err := domain.CreateOrderFrom(context.Background(), &domain.PlaceOrderModel{
+		OrderSource:     shared.OrderSourceWeb,
+		Location:        shared.LocationRaleigh,
+		LoyaltyMemberID: uuid.New(),
+		BaristaItems:    []*domain.OrderItemModel{{ItemType: shared.ItemTypeLatte}, {ItemType: shared.ItemTypeLatte}},
+		KitchenItems:    []*domain.OrderItemModel{{ItemType: shared.ItemTypeMuffin}},
+	}, fixedPriceProductSvc{})
+	assert.NoError(t, err)
+	assert.Len(t, order.LineItems, 3)
+
+	// both lattes have the same item type, only the line item ID tells them apart
+	for _, item := range order.LineItems[:2] {
+		err = order.Apply(&event.OrderUp{OrderID: order.ID, ItemLineID: item.ID, ItemType: item.ItemType})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, shared.StatusInProcess, order.OrderStatus)
+
+	err = order.Apply(&event.OrderUp{OrderID: order.ID, ItemLineID: order.LineItems[2].ID})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.StatusFulfilled, order.OrderStatus)
+
+	rebuilt := &domain.Order{}
+	rebuilt.Replay(order.DomainEvents())
+
+	assert.Equal(t, order.ID, rebuilt.ID)
+	assert.Equal(t, order.OrderSource, rebuilt.OrderSource)
+	assert.Equal(t, order.LoyaltyMemberID, rebuilt.LoyaltyMemberID)
+	assert.Equal(t, order.Location, rebuilt.Location)
+	assert.Equal(t, order.OrderStatus, rebuilt.OrderStatus)
+	assert.Equal(t, order.LineItems, rebuilt.LineItems)
+}
+
+func TestCreateOrderRejectsUnorderableItems(t *testing.T) {
+	t.Parallel()
+
+	_, err := domain.CreateOrderFrom(context.Background(), &domain.PlaceOrderModel{
+		BaristaItems: []*domain.OrderItemModel{{ItemType: shared.ItemTypeLatte}, {ItemType: 42}},
+		KitchenItems: []*domain.OrderItemModel{{ItemType: 42}},
+	}, fixedPriceProductSvc{})
+
+	var unorderable *domain.UnorderableItemsError
+	assert.ErrorAs(t, err, &unorderable)
+	assert.Equal(t, []*domain.MissingItem{{ItemType: 42, Reason: domain.MissingReasonUnknown}}, unorderable.Items)
+	assert.EqualError(t, err, "items can't be ordered: 42 (unknown)")
+}
+
+func TestApplyUnknownLineItem(t *testing.T) {
+	t.Parallel()
+
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	order.LineItems = append(order.LineItems,
+		domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true))
+
+	err := order.Apply(&event.OrderUp{OrderID: order.ID, ItemLineID: uuid.New()})
+	assert.ErrorIs(t, err, domain.ErrItemNotFound)
+}
+
+func TestJustFulfilledOnlyByTheLastItem(t *testing.T) {
+	t.Parallel()
+
+	latte := domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true)
+	croissant := domain.NewLineItem(shared.ItemTypeCroissant, "CROISSANT", 3.25, shared.StatusInProcess, false)
+
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	order.LineItems = []*domain.LineItem{latte, croissant}
+
+	assert.NoError(t, order.Apply(&event.OrderUp{OrderID: order.ID, ItemLineID: latte.ID}))
+	assert.False(t, order.JustFulfilled())
+
+	// the repo loads the order again for the next update, without the events of the last one
+	reloaded := domain.NewOrder(order.OrderSource, order.LoyaltyMemberID, order.OrderStatus, order.Location)
+	reloaded.LineItems = order.LineItems
+
+	assert.NoError(t, reloaded.Apply(&event.OrderUp{OrderID: order.ID, ItemLineID: croissant.ID}))
+	assert.True(t, reloaded.JustFulfilled())
+}
@@ -0,0 +1,281 @@
+package domain
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+const _reportDateLayout = "2006-01-02"
+
+type ReportGroupBy string
+
+const (
+	ReportGroupByDay      ReportGroupBy = "day"
+	ReportGroupByLocation ReportGroupBy = "location"
+	ReportGroupByItemType ReportGroupBy = "item_type"
+	ReportGroupByStation  ReportGroupBy = "station"
+	ReportGroupByActor    ReportGroupBy = "actor"
+)
+
+// ReportRange is a range of whole days, From and To are both included.
+type ReportRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func NewReportRange(from, to string) (ReportRange, error) {
+	fromDate, err := time.Parse(_reportDateLayout, from)
+	if err != nil {
+		return ReportRange{}, errors.Wrapf(ErrInvalidReportRange, "from %q", from)
+	}
+
+	toDate, err := time.Parse(_reportDateLayout, to)
+	if err != nil {
+		return ReportRange{}, errors.Wrapf(ErrInvalidReportRange, "to %q", to)
+	}
+
+	if toDate.Before(fromDate) {
+		return ReportRange{}, errors.Wrapf(ErrInvalidReportRange, "%s is before %s", to, from)
+	}
+
+	return ReportRange{From: fromDate, To: toDate}, nil
+}
+
+// Until is the first day after the range, the queries use it as an exclusive bound.
+func (r ReportRange) Until() time.Time {
+	return r.To.AddDate(0, 0, 1)
+}
+
+// ReportKey holds the value of each dimension a report row is grouped by, the others are empty.
+type ReportKey struct {
+	Day      string
+	Location string
+	ItemType string
+	Station  string
+	Actor    string
+}
+
+type DailySales struct {
+	Date     time.Time
+	Location shared.Location
+	Orders   int64
+	Items    int64
+	Revenue  float64
+}
+
+type DailyItemSales struct {
+	Date     time.Time
+	Location shared.Location
+	ItemType shared.ItemType
+	// ItemName is the catalog name the items were sold under.
+	ItemName string
+	Items    int64
+	Revenue  float64
+}
+
+type PrepTime struct {
+	Date         time.Time
+	Location     shared.Location
+	ItemType     shared.ItemType
+	ItemName     string
+	Station      Station
+	Actor        string
+	PrepSeconds  float64
+	TotalSeconds float64
+}
+
+type SalesReportRow struct {
+	Key           ReportKey
+	Orders        int64
+	Items         int64
+	Revenue       float64
+	AverageTicket float64
+}
+
+type PrepTimeReportRow struct {
+	Key             ReportKey
+	Items           int64
+	AvgPrepSeconds  float64
+	P50PrepSeconds  float64
+	P90PrepSeconds  float64
+	P95PrepSeconds  float64
+	AvgTotalSeconds float64
+}
+
+// BuildSalesReport rolls the daily aggregates up to the requested groups.
+// The item mix needs the per item type aggregate, which can't tell the number of orders,
+// so Orders and AverageTicket are only filled when the report is not grouped by item type.
+func BuildSalesReport(
+	daily []*DailySales,
+	dailyItems []*DailyItemSales,
+	groupBy []ReportGroupBy,
+) ([]*SalesReportRow, error) {
+	if err := validateGroups(groupBy, ReportGroupByDay, ReportGroupByLocation, ReportGroupByItemType); err != nil {
+		return nil, err
+	}
+
+	rows := make(map[ReportKey]*SalesReportRow)
+	keys := make([]ReportKey, 0)
+
+	row := func(key ReportKey) *SalesReportRow {
+		r, ok := rows[key]
+		if !ok {
+			r = &SalesReportRow{Key: key}
+			rows[key] = r
+			keys = append(keys, key)
+		}
+
+		return r
+	}
+
+	if lo.Contains(groupBy, ReportGroupByItemType) {
+		for _, s := range dailyItems {
+			r := row(keyOf(groupBy, s.Date, s.Location, itemName(s.ItemType, s.ItemName), "", ""))
+			r.Items += s.Items
+			r.Revenue += s.Revenue
+		}
+	} else {
+		for _, s := range daily {
+			r := row(keyOf(groupBy, s.Date, s.Location, "", "", ""))
+			r.Orders += s.Orders
+			r.Items += s.Items
+			r.Revenue += s.Revenue
+		}
+	}
+
+	results := make([]*SalesReportRow, 0, len(keys))
+
+	for _, key := range keys {
+		r := rows[key]
+		if r.Orders > 0 {
+			r.AverageTicket = r.Revenue / float64(r.Orders)
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// BuildPrepTimeReport groups the prep times of completed line items and computes their percentiles.
+func BuildPrepTimeReport(times []*PrepTime, groupBy []ReportGroupBy) ([]*PrepTimeReportRow, error) {
+	err := validateGroups(groupBy,
+		ReportGroupByDay, ReportGroupByLocation, ReportGroupByItemType, ReportGroupByStation, ReportGroupByActor)
+	if err != nil {
+		return nil, err
+	}
+
+	prepSeconds := make(map[ReportKey][]float64)
+	totalSeconds := make(map[ReportKey]float64)
+	keys := make([]ReportKey, 0)
+
+	for _, t := range times {
+		key := keyOf(groupBy, t.Date, t.Location, itemName(t.ItemType, t.ItemName), t.Station, t.Actor)
+		if _, ok := prepSeconds[key]; !ok {
+			keys = append(keys, key)
+		}
+
+		prepSeconds[key] = append(prepSeconds[key], t.PrepSeconds)
+		totalSeconds[key] += t.TotalSeconds
+	}
+
+	results := make([]*PrepTimeReportRow, 0, len(keys))
+
+	for _, key := range keys {
+		values := prepSeconds[key]
+		sort.Float64s(values)
+
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+
+		count := float64(len(values))
+
+		results = append(results, &PrepTimeReportRow{
+			Key:             key,
+			Items:           int64(len(values)),
+			AvgPrepSeconds:  sum / count,
+			P50PrepSeconds:  Percentile(values, 50),
+			P90PrepSeconds:  Percentile(values, 90),
+			P95PrepSeconds:  Percentile(values, 95),
+			AvgTotalSeconds: totalSeconds[key] / count,
+		})
+	}
+
+	return results, nil
+}
+
+// Percentile returns the p-th percentile of sorted values using the nearest-rank method.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func keyOf(
+	groupBy []ReportGroupBy,
+	date time.Time,
+	location shared.Location,
+	itemName string,
+	station Station,
+	actor string,
+) ReportKey {
+	key := ReportKey{}
+
+	for _, g := range groupBy {
+		switch g {
+		case ReportGroupByDay:
+			key.Day = date.Format(_reportDateLayout)
+		case ReportGroupByLocation:
+			key.Location = location.Name()
+		case ReportGroupByItemType:
+			key.ItemType = itemName
+		case ReportGroupByStation:
+			key.Station = string(station)
+		case ReportGroupByActor:
+			key.Actor = actor
+		}
+	}
+
+	return key
+}
+
+// itemName is the name the items of a report row are shown under, the item types added by a catalog import
+// are only known by the name in their line items.
+func itemName(itemType shared.ItemType, name string) string {
+	if name == "" {
+		return itemType.String()
+	}
+
+	return name
+}
+
+func validateGroups(groupBy []ReportGroupBy, allowed ...ReportGroupBy) error {
+	for _, g := range groupBy {
+		if !lo.Contains(allowed, g) {
+			return errors.Wrapf(ErrInvalidReportGroup, "%q, expected one of %s", g, joinGroups(allowed))
+		}
+	}
+
+	return nil
+}
+
+func joinGroups(groups []ReportGroupBy) string {
+	return strings.Join(lo.Map(groups, func(g ReportGroupBy, _ int) string {
+		return string(g)
+	}), ", ")
+}
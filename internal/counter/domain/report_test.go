@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+func TestBuildSalesReport(t *testing.T) {
+	t.Parallel()
+
+	day1 := time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	daily := []*domain.DailySales{
+		{Date: day1, Location: shared.LocationAtlanta, Orders: 2, Items: 3, Revenue: 10},
+		{Date: day1, Location: shared.LocationRaleigh, Orders: 1, Items: 1, Revenue: 5},
+		{Date: day2, Location: shared.LocationAtlanta, Orders: 1, Items: 2, Revenue: 6},
+		// an order placed before the location was recorded
+		{Date: day1, Location: shared.LocationUnknown, Orders: 1, Items: 1, Revenue: 4},
+	}
+
+	rows, err := domain.BuildSalesReport(daily, nil, []domain.ReportGroupBy{domain.ReportGroupByLocation})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "ATLANTA", rows[0].Key.Location)
+	assert.Equal(t, "RALEIGH", rows[1].Key.Location)
+	assert.Equal(t, "unknown", rows[2].Key.Location)
+	assert.Equal(t, int64(3), rows[0].Orders)
+	assert.Equal(t, float64(16), rows[0].Revenue)
+	assert.InDelta(t, 16.0/3, rows[0].AverageTicket, 0.001)
+
+	_, err = domain.BuildSalesReport(daily, nil, []domain.ReportGroupBy{domain.ReportGroupByActor})
+	assert.ErrorIs(t, err, domain.ErrInvalidReportGroup)
+}
+
+func TestBuildPrepTimeReportPercentiles(t *testing.T) {
+	t.Parallel()
+
+	times := make([]*domain.PrepTime, 0, 10)
+	for i := 10; i >= 1; i-- {
+		times = append(times, &domain.PrepTime{
+			Station:      domain.StationBarista,
+			PrepSeconds:  float64(i),
+			TotalSeconds: float64(i + 1),
+		})
+	}
+
+	rows, err := domain.BuildPrepTimeReport(times, []domain.ReportGroupBy{domain.ReportGroupByStation})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+	assert.Equal(t, string(domain.StationBarista), rows[0].Key.Station)
+	assert.Equal(t, int64(10), rows[0].Items)
+	assert.Equal(t, 5.5, rows[0].AvgPrepSeconds)
+	assert.Equal(t, float64(5), rows[0].P50PrepSeconds)
+	assert.Equal(t, float64(9), rows[0].P90PrepSeconds)
+	assert.Equal(t, float64(10), rows[0].P95PrepSeconds)
+	assert.Equal(t, 6.5, rows[0].AvgTotalSeconds)
+}
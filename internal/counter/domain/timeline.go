@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+type TimelineStage string
+
+const (
+	TimelineStagePlaced        TimelineStage = "placed"
+	TimelineStageSentToStation TimelineStage = "sent_to_station"
+	TimelineStageStarted       TimelineStage = "started"
+	TimelineStageCompleted     TimelineStage = "completed"
+)
+
+type Station string
+
+const (
+	StationCounter Station = "counter"
+	StationBarista Station = "barista"
+	StationKitchen Station = "kitchen"
+)
+
+// TimelineEntry records when a line item reached a stage, at which station and by whom.
+type TimelineEntry struct {
+	OrderID    uuid.UUID
+	LineItemID uuid.UUID
+	ItemType   shared.ItemType
+	Name       string
+	Stage      TimelineStage
+	Station    Station
+	Actor      string
+	OccurredAt time.Time
+}
+
+func NewTimelineEntry(
+	orderID, lineItemID uuid.UUID,
+	stage TimelineStage,
+	station Station,
+	actor string,
+	occurredAt time.Time,
+) *TimelineEntry {
+	return &TimelineEntry{
+		OrderID:    orderID,
+		LineItemID: lineItemID,
+		Stage:      stage,
+		Station:    station,
+		Actor:      actor,
+		OccurredAt: occurredAt,
+	}
+}
+
+// StationOf returns the station which prepares the line item.
+func StationOf(item *LineItem) Station {
+	if item.IsBaristaOrder {
+		return StationBarista
+	}
+
+	return StationKitchen
+}
@@ -5,31 +5,39 @@ import (
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
 	"github.com/thangchung/go-coffeeshop/internal/counter/events"
 	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
 	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 )
 
 type baristaOrderUpdatedEventHandler struct {
-	orderRepo orders.OrderRepo
+	uow          postgres.UnitOfWork
+	orderRepo    orders.OrderRepo
+	timelineRepo orders.TimelineRepo
+	metrics      orders.OrderMetrics
 }
 
 var _ events.BaristaOrderUpdatedEventHandler = (*baristaOrderUpdatedEventHandler)(nil)
 
 var BaristaOrderUpdatedEventHandlerSet = wire.NewSet(NewBaristaOrderUpdatedEventHandler)
 
-func NewBaristaOrderUpdatedEventHandler(orderRepo orders.OrderRepo) events.BaristaOrderUpdatedEventHandler {
+func NewBaristaOrderUpdatedEventHandler(
+	uow postgres.UnitOfWork,
+	orderRepo orders.OrderRepo,
+	timelineRepo orders.TimelineRepo,
+	metrics orders.OrderMetrics,
+) events.BaristaOrderUpdatedEventHandler {
 	return &baristaOrderUpdatedEventHandler{
-		orderRepo: orderRepo,
+		uow:          uow,
+		orderRepo:    orderRepo,
+		timelineRepo: timelineRepo,
+		metrics:      metrics,
 	}
 }
 
 func (h *baristaOrderUpdatedEventHandler) Handle(ctx context.Context, e *event.BaristaOrderUpdated) error {
-	order, err := h.orderRepo.GetByID(ctx, e.OrderID)
-	if err != nil {
-		return errors.Wrap(err, "orderRepo.GetByID")
-	}
-
 	orderUp := event.OrderUp{
 		OrderID:    e.OrderID,
 		ItemLineID: e.ItemLineID,
@@ -39,13 +47,44 @@ func (h *baristaOrderUpdatedEventHandler) Handle(ctx context.Context, e *event.B
 		MadeBy:     e.MadeBy,
 	}
 
-	if err = order.Apply(&orderUp); err != nil {
-		return errors.Wrap(err, "order.Apply")
-	}
+	var fulfilled *domain.Order
+
+	err := retryOnConflict(ctx, func() error {
+		fulfilled = nil
+
+		return h.uow.WithinTx(ctx, func(ctx context.Context) error {
+			order, err := h.orderRepo.GetByID(ctx, e.OrderID)
+			if err != nil {
+				return errors.Wrap(err, "orderRepo.GetByID")
+			}
 
-	_, err = h.orderRepo.Update(ctx, order)
+			if order == nil {
+				return errors.Wrapf(domain.ErrOrderNotFound, "orderRepo.GetByID(%s)", e.OrderID)
+			}
+
+			if err = order.Apply(&orderUp); err != nil {
+				return errors.Wrap(err, "order.Apply")
+			}
+
+			_, err = h.orderRepo.Update(ctx, order)
+			if err != nil {
+				return errors.Wrap(err, "orderRepo.Update")
+			}
+
+			if order.JustFulfilled() {
+				fulfilled = order
+			}
+
+			// the timeline is written in the same transaction, so a failure leaves neither behind
+			return recordStationWork(ctx, h.timelineRepo, e.OrderID, e.ItemLineID, domain.StationBarista, e.MadeBy, e.TimeIn, e.TimeUp)
+		})
+	})
 	if err != nil {
-		return errors.Wrap(err, "orderRepo.Update")
+		return err
+	}
+
+	if fulfilled != nil {
+		observeFulfillment(ctx, h.timelineRepo, h.metrics, fulfilled)
 	}
 
 	return nil
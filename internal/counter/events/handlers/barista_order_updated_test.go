@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/events/handlers"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+// versionedOrderRepo keeps a single order and rejects stale updates like the postgres repo does.
+type versionedOrderRepo struct {
+	mu    sync.Mutex
+	order domain.Order
+}
+
+func (r *versionedOrderRepo) GetAll(context.Context) ([]*domain.Order, error) {
+	return nil, nil
+}
+
+func (r *versionedOrderRepo) GetByID(_ context.Context, _ uuid.UUID) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	order := r.order
+	order.LineItems = make([]*domain.LineItem, 0, len(r.order.LineItems))
+
+	for _, item := range r.order.LineItems {
+		copied := *item
+		order.LineItems = append(order.LineItems, &copied)
+	}
+
+	return &order, nil
+}
+
+func (r *versionedOrderRepo) Create(context.Context, *domain.Order) error {
+	return nil
+}
+
+func (r *versionedOrderRepo) Update(_ context.Context, order *domain.Order) (*domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if order.Version != r.order.Version {
+		return nil, domain.ErrOrderVersionConflict
+	}
+
+	order.Version++
+	r.order = *order
+
+	return order, nil
+}
+
+// inlineUoW runs fn on the ctx it gets, the fake repos have no transactions.
+type inlineUoW struct{}
+
+func (inlineUoW) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopTimelineRepo struct{}
+
+func (nopTimelineRepo) Append(context.Context, ...*domain.TimelineEntry) error {
+	return nil
+}
+
+func (nopTimelineRepo) GetByOrderID(context.Context, uuid.UUID) ([]*domain.TimelineEntry, error) {
+	return nil, nil
+}
+
+type nopOrderMetrics struct{}
+
+func (nopOrderMetrics) OrderPlaced(*domain.Order) {}
+
+func (nopOrderMetrics) OrderFulfilled(*domain.Order, time.Time) {}
+
+func TestConcurrentStationUpdatesConverge(t *testing.T) {
+	t.Parallel()
+
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	latte := domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true)
+	espresso := domain.NewLineItem(shared.ItemTypeEspresso, "ESPRESSO", 3.5, shared.StatusInProcess, true)
+	croissant := domain.NewLineItem(shared.ItemTypeCroissant, "CROISSANT", 3.25, shared.StatusInProcess, false)
+	order.LineItems = []*domain.LineItem{latte, espresso, croissant}
+
+	repo := &versionedOrderRepo{order: *order}
+	baristaHandler := handlers.NewBaristaOrderUpdatedEventHandler(inlineUoW{}, repo, nopTimelineRepo{}, nopOrderMetrics{})
+	kitchenHandler := handlers.NewKitchenOrderUpdatedEventHandler(inlineUoW{}, repo, nopTimelineRepo{}, nopOrderMetrics{})
+
+	var wg sync.WaitGroup
+
+	for _, item := range []*domain.LineItem{latte, espresso} {
+		wg.Add(1)
+
+		go func(item *domain.LineItem) {
+			defer wg.Done()
+
+			err := baristaHandler.Handle(context.Background(), &event.BaristaOrderUpdated{
+				OrderID:    order.ID,
+				ItemLineID: item.ID,
+				ItemType:   item.ItemType,
+			})
+			assert.NoError(t, err)
+		}(item)
+	}
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		err := kitchenHandler.Handle(context.Background(), &event.KitchenOrderUpdated{
+			OrderID:    order.ID,
+			ItemLineID: croissant.ID,
+			ItemType:   croissant.ItemType,
+		})
+		assert.NoError(t, err)
+	}()
+
+	wg.Wait()
+
+	result, err := repo.GetByID(context.Background(), order.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.StatusFulfilled, result.OrderStatus)
+	assert.Equal(t, int32(3), result.Version)
+
+	for _, item := range result.LineItems {
+		assert.Equal(t, shared.StatusFulfilled, item.ItemStatus)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/events"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"golang.org/x/exp/slog"
+)
+
+type catalogChangedEventHandler struct {
+	cache domain.ProductCatalogCache
+}
+
+var _ events.CatalogChangedEventHandler = (*catalogChangedEventHandler)(nil)
+
+var CatalogChangedEventHandlerSet = wire.NewSet(NewCatalogChangedEventHandler)
+
+func NewCatalogChangedEventHandler(cache domain.ProductCatalogCache) events.CatalogChangedEventHandler {
+	return &catalogChangedEventHandler{
+		cache: cache,
+	}
+}
+
+func (h *catalogChangedEventHandler) Handle(ctx context.Context, e *event.CatalogChanged) error {
+	slog.Info("catalog changed, dropping the product cache", "items", e.Items)
+
+	h.cache.Invalidate()
+
+	return nil
+}
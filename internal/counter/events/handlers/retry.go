@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	_maxConflictRetries = 10
+	_conflictBackOff    = 20 * time.Millisecond
+)
+
+// retryOnConflict re-runs fn while it fails with domain.ErrOrderVersionConflict,
+// so every attempt re-reads the order and applies the change on top of the latest version.
+func retryOnConflict(ctx context.Context, fn func() error) error {
+	var err error
+
+	for attempt := 1; attempt <= _maxConflictRetries; attempt++ {
+		err = fn()
+		if !errors.Is(err, domain.ErrOrderVersionConflict) {
+			return err
+		}
+
+		slog.Info("order version conflict, retrying", "attempt", attempt)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * _conflictBackOff):
+		}
+	}
+
+	return err
+}
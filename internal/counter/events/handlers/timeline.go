@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	"golang.org/x/exp/slog"
+)
+
+// recordStationWork stores when a station started and completed a line item, and who made it.
+// Redelivered events are ignored by the repo, so the handler can safely record them again.
+func recordStationWork(
+	ctx context.Context,
+	timelineRepo orders.TimelineRepo,
+	orderID, itemLineID uuid.UUID,
+	station domain.Station,
+	madeBy string,
+	timeIn, timeUp time.Time,
+) error {
+	err := timelineRepo.Append(ctx,
+		domain.NewTimelineEntry(orderID, itemLineID, domain.TimelineStageStarted, station, madeBy, timeIn),
+		domain.NewTimelineEntry(orderID, itemLineID, domain.TimelineStageCompleted, station, madeBy, timeUp),
+	)
+	if err != nil {
+		return errors.Wrap(err, "timelineRepo.Append")
+	}
+
+	return nil
+}
+
+// observeFulfillment measures the time from placing the order to fulfilling it, the timeline tells when it was placed.
+// The order is already updated at this point, so a failure is only logged.
+func observeFulfillment(
+	ctx context.Context,
+	timelineRepo orders.TimelineRepo,
+	metrics orders.OrderMetrics,
+	order *domain.Order,
+) {
+	entries, err := timelineRepo.GetByOrderID(ctx, order.ID)
+	if err != nil {
+		slog.Error("failed to get the timeline of the fulfilled order", "error", err, "order_id", order.ID)
+
+		return
+	}
+
+	placed, ok := lo.Find(entries, func(entry *domain.TimelineEntry) bool {
+		return entry.Stage == domain.TimelineStagePlaced
+	})
+	if !ok {
+		return
+	}
+
+	metrics.OrderFulfilled(order, placed.OccurredAt)
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/events/handlers"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+// recordingTimelineRepo keeps the appended entries in the order they came.
+type recordingTimelineRepo struct {
+	mu      sync.Mutex
+	entries []*domain.TimelineEntry
+}
+
+func (r *recordingTimelineRepo) Append(_ context.Context, entries ...*domain.TimelineEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entries...)
+
+	return nil
+}
+
+func (r *recordingTimelineRepo) GetByOrderID(_ context.Context, orderID uuid.UUID) ([]*domain.TimelineEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := make([]*domain.TimelineEntry, 0, len(r.entries))
+
+	for _, entry := range r.entries {
+		if entry.OrderID == orderID {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func TestStationUpdatesAppendToTheTimeline(t *testing.T) {
+	t.Parallel()
+
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	latte := domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true)
+	croissant := domain.NewLineItem(shared.ItemTypeCroissant, "CROISSANT", 3.25, shared.StatusInProcess, false)
+	order.LineItems = []*domain.LineItem{latte, croissant}
+
+	repo := &versionedOrderRepo{order: *order}
+	timelineRepo := &recordingTimelineRepo{}
+	baristaHandler := handlers.NewBaristaOrderUpdatedEventHandler(inlineUoW{}, repo, timelineRepo, nopOrderMetrics{})
+	kitchenHandler := handlers.NewKitchenOrderUpdatedEventHandler(inlineUoW{}, repo, timelineRepo, nopOrderMetrics{})
+
+	timeIn := time.Date(2022, 7, 4, 8, 0, 0, 0, time.UTC)
+
+	err := baristaHandler.Handle(context.Background(), &event.BaristaOrderUpdated{
+		OrderID:    order.ID,
+		ItemLineID: latte.ID,
+		ItemType:   latte.ItemType,
+		MadeBy:     "teesee",
+		TimeIn:     timeIn,
+		TimeUp:     timeIn.Add(2 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	err = kitchenHandler.Handle(context.Background(), &event.KitchenOrderUpdated{
+		OrderID:    order.ID,
+		ItemLineID: croissant.ID,
+		ItemType:   croissant.ItemType,
+		MadeBy:     "remy",
+		TimeIn:     timeIn.Add(time.Minute),
+		TimeUp:     timeIn.Add(4 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []*domain.TimelineEntry{
+		domain.NewTimelineEntry(order.ID, latte.ID, domain.TimelineStageStarted, domain.StationBarista, "teesee", timeIn),
+		domain.NewTimelineEntry(order.ID, latte.ID, domain.TimelineStageCompleted, domain.StationBarista, "teesee", timeIn.Add(2*time.Minute)),
+		domain.NewTimelineEntry(order.ID, croissant.ID, domain.TimelineStageStarted, domain.StationKitchen, "remy", timeIn.Add(time.Minute)),
+		domain.NewTimelineEntry(order.ID, croissant.ID, domain.TimelineStageCompleted, domain.StationKitchen, "remy", timeIn.Add(4*time.Minute)),
+	}, timelineRepo.entries)
+}
@@ -14,4 +14,8 @@ type (
 	KitchenOrderUpdatedEventHandler interface {
 		Handle(context.Context, *event.KitchenOrderUpdated) error
 	}
+
+	CatalogChangedEventHandler interface {
+		Handle(context.Context, *event.CatalogChanged) error
+	}
 )
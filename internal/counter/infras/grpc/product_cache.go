@@ -0,0 +1,237 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/thangchung/go-coffeeshop/cmd/counter/config"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+)
+
+const _refreshTimeout = 5 * time.Second
+
+var _ domain.ProductDomainService = (*CachingProductClient)(nil)
+
+var _ domain.ProductCatalogCache = (*CachingProductClient)(nil)
+
+var (
+	ProductGRPCClientSet = wire.NewSet(NewProductConn, ProductClientSet)
+
+	// ProductClientSet is the client on a connection made elsewhere.
+	ProductClientSet = wire.NewSet(
+		NewProductClient,
+		wire.Bind(new(domain.ProductDomainService), new(*CachingProductClient)),
+		wire.Bind(new(domain.ProductCatalogCache), new(*CachingProductClient)),
+	)
+)
+
+// CachingProductClient keeps the looked up items per location, so orders can be placed
+// without asking the product service every time, and while it is down.
+// An item is served from the cache for ttl, then served while it is refreshed in the background up to maxStale.
+// Past maxStale, or for items never seen, the product service is asked before the order is placed.
+// An item is only served for the times the product service priced it for, from the time it was looked up at
+// until the menu changes it, so the price of a happy hour or a breakfast item is not served outside of its window.
+type CachingProductClient struct {
+	next          domain.ProductDomainService
+	ttl, maxStale time.Duration
+
+	mu         sync.Mutex
+	entries    map[productCacheKey]*productCacheEntry
+	refreshing map[shared.Location]bool
+	// generation changes on Invalidate, so a lookup which started before it doesn't store what it got
+	generation int
+}
+
+type productCacheKey struct {
+	location shared.Location
+	itemType shared.ItemType
+}
+
+// productCacheEntry is either a priced item or a missing one, the product service is asked about both.
+// It holds for the lookups at from until until, a zero until is for any time after from.
+type productCacheEntry struct {
+	item        *domain.ItemModel
+	missing     *domain.MissingItem
+	fetched     time.Time
+	from, until time.Time
+}
+
+func NewProductClient(cfg *config.Config, conn *grpc.ClientConn) *CachingProductClient {
+	return NewCachingProductClient(NewGRPCProductClient(conn), cfg.ProductClient.CacheTTL, cfg.ProductClient.CacheMaxStale)
+}
+
+func NewCachingProductClient(next domain.ProductDomainService, ttl, maxStale time.Duration) *CachingProductClient {
+	return &CachingProductClient{
+		next:       next,
+		ttl:        ttl,
+		maxStale:   maxStale,
+		entries:    make(map[productCacheKey]*productCacheEntry),
+		refreshing: make(map[shared.Location]bool),
+	}
+}
+
+func (c *CachingProductClient) LookupItems(
+	ctx context.Context,
+	location shared.Location,
+	itemTypes []shared.ItemType,
+	at time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	c.mu.Lock()
+	entries, fresh, usable := c.cached(location, itemTypes, at)
+	generation := c.generation
+
+	if usable && !fresh && !c.refreshing[location] {
+		c.refreshing[location] = true
+
+		go c.refresh(location, c.staleItemTypes(location), generation)
+	}
+	c.mu.Unlock()
+
+	if usable {
+		items, missing := fromEntries(entries)
+
+		return items, missing, nil
+	}
+
+	items, missing, err := c.next.LookupItems(ctx, location, itemTypes, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.store(location, items, missing, at, generation)
+
+	return items, missing, nil
+}
+
+// Invalidate drops every cached item, it is called when the product service announces a catalog change.
+func (c *CachingProductClient) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[productCacheKey]*productCacheEntry)
+	c.generation++
+}
+
+// cached returns the entries of the item types when all of them are no older than maxStale and hold at the time,
+// fresh tells whether they are also no older than ttl.
+func (c *CachingProductClient) cached(
+	location shared.Location,
+	itemTypes []shared.ItemType,
+	at time.Time,
+) ([]*productCacheEntry, bool, bool) {
+	now := time.Now()
+	entries := make([]*productCacheEntry, 0, len(itemTypes))
+	fresh := true
+
+	for _, itemType := range itemTypes {
+		entry, ok := c.entries[productCacheKey{location: location, itemType: itemType}]
+		if !ok || now.Sub(entry.fetched) >= c.maxStale || !entry.holdsAt(at) {
+			return nil, false, false
+		}
+
+		if now.Sub(entry.fetched) >= c.ttl {
+			fresh = false
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, fresh, true
+}
+
+// staleItemTypes are the item types of the location cached for longer than ttl, c.mu is held.
+func (c *CachingProductClient) staleItemTypes(location shared.Location) []shared.ItemType {
+	now := time.Now()
+	itemTypes := make([]shared.ItemType, 0)
+
+	for key, entry := range c.entries {
+		if key.location == location && now.Sub(entry.fetched) >= c.ttl {
+			itemTypes = append(itemTypes, key.itemType)
+		}
+	}
+
+	return itemTypes
+}
+
+// refresh looks the item types up again as they are sold now, the lookup which started it may be for another time.
+func (c *CachingProductClient) refresh(location shared.Location, itemTypes []shared.ItemType, generation int) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.refreshing, location)
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), _refreshTimeout)
+	defer cancel()
+
+	at := time.Now()
+
+	items, missing, err := c.next.LookupItems(ctx, location, itemTypes, at)
+	if err != nil {
+		// the stale items are served until they are older than maxStale
+		slog.Error("failed to refresh the product cache", "error", err, "location", location)
+
+		return
+	}
+
+	c.store(location, items, missing, at, generation)
+}
+
+func (c *CachingProductClient) store(
+	location shared.Location,
+	items []*domain.ItemModel,
+	missing []*domain.MissingItem,
+	at time.Time,
+	generation int,
+) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	now := time.Now()
+
+	for _, item := range items {
+		c.entries[productCacheKey{location: location, itemType: item.ItemType}] = &productCacheEntry{
+			item:    item,
+			fetched: now,
+			from:    at,
+			until:   item.ValidUntil,
+		}
+	}
+
+	for _, item := range missing {
+		c.entries[productCacheKey{location: location, itemType: item.ItemType}] = &productCacheEntry{
+			missing: item,
+			fetched: now,
+			from:    at,
+			until:   item.ValidUntil,
+		}
+	}
+}
+
+func (e *productCacheEntry) holdsAt(at time.Time) bool {
+	return !at.Before(e.from) && (e.until.IsZero() || at.Before(e.until))
+}
+
+func fromEntries(entries []*productCacheEntry) ([]*domain.ItemModel, []*domain.MissingItem) {
+	items := make([]*domain.ItemModel, 0, len(entries))
+	missing := make([]*domain.MissingItem, 0)
+
+	for _, entry := range entries {
+		if entry.item != nil {
+			items = append(items, entry.item)
+		} else {
+			missing = append(missing, entry.missing)
+		}
+	}
+
+	return items, missing
+}
@@ -0,0 +1,204 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	infrasGRPC "github.com/thangchung/go-coffeeshop/internal/counter/infras/grpc"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+var errProductDown = errors.New("product service is down")
+
+type fakeProductSvc struct {
+	mu    sync.Mutex
+	price float64
+	calls int
+	down  bool
+}
+
+func (f *fakeProductSvc) LookupItems(
+	_ context.Context,
+	_ shared.Location,
+	itemTypes []shared.ItemType,
+	_ time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	if f.down {
+		return nil, nil, errProductDown
+	}
+
+	results := make([]*domain.ItemModel, 0, len(itemTypes))
+	for _, itemType := range itemTypes {
+		results = append(results, &domain.ItemModel{ItemType: itemType, Price: f.price})
+	}
+
+	return results, nil, nil
+}
+
+func (f *fakeProductSvc) set(price float64, down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.price, f.down = price, down
+}
+
+func (f *fakeProductSvc) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+// happyHourProductSvc sells a latte at a discount from happyHour on and croissants only until then.
+type happyHourProductSvc struct {
+	happyHour time.Time
+	calls     int
+}
+
+func (f *happyHourProductSvc) LookupItems(
+	_ context.Context,
+	_ shared.Location,
+	_ []shared.ItemType,
+	at time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	f.calls++
+
+	if at.Before(f.happyHour) {
+		return []*domain.ItemModel{
+			{ItemType: shared.ItemTypeLatte, Price: 4.5, ValidUntil: f.happyHour},
+			{ItemType: shared.ItemTypeCroissant, Price: 3.25, ValidUntil: f.happyHour},
+		}, nil, nil
+	}
+
+	return []*domain.ItemModel{{ItemType: shared.ItemTypeLatte, Price: 3.6}},
+		[]*domain.MissingItem{{ItemType: shared.ItemTypeCroissant, Reason: domain.MissingReasonUnavailable}}, nil
+}
+
+func lookupPrice(t *testing.T, c *infrasGRPC.CachingProductClient) (float64, error) {
+	t.Helper()
+
+	items, _, err := c.LookupItems(context.Background(), shared.LocationAtlanta, []shared.ItemType{shared.ItemTypeLatte}, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	return items[0].Price, nil
+}
+
+func TestCachingProductClientServesFromCache(t *testing.T) {
+	t.Parallel()
+
+	next := &fakeProductSvc{price: 4.5}
+	c := infrasGRPC.NewCachingProductClient(next, time.Hour, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		price, err := lookupPrice(t, c)
+		assert.NoError(t, err)
+		assert.Equal(t, 4.5, price)
+	}
+
+	assert.Equal(t, 1, next.callCount())
+
+	// another location is another menu
+	_, _, err := c.LookupItems(context.Background(), shared.LocationRaleigh, []shared.ItemType{shared.ItemTypeLatte}, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, next.callCount())
+
+	next.set(5, false)
+	c.Invalidate()
+
+	price, err := lookupPrice(t, c)
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, price)
+}
+
+func TestCachingProductClientServesStaleWhileProductIsDown(t *testing.T) {
+	t.Parallel()
+
+	next := &fakeProductSvc{price: 4.5}
+	c := infrasGRPC.NewCachingProductClient(next, time.Millisecond, time.Hour)
+
+	_, err := lookupPrice(t, c)
+	assert.NoError(t, err)
+
+	next.set(5, true)
+	time.Sleep(5 * time.Millisecond)
+
+	price, err := lookupPrice(t, c)
+	assert.NoError(t, err)
+	assert.Equal(t, 4.5, price)
+
+	// the failed background refresh keeps the stale price, the next one picks the new price up
+	assert.Eventually(t, func() bool { return next.callCount() == 2 }, time.Second, time.Millisecond)
+	next.set(5, false)
+
+	assert.Eventually(t, func() bool {
+		price, err := lookupPrice(t, c)
+
+		return err == nil && price == 5
+	}, time.Second, time.Millisecond)
+}
+
+func TestCachingProductClientFailsPastMaxStale(t *testing.T) {
+	t.Parallel()
+
+	next := &fakeProductSvc{price: 4.5}
+	c := infrasGRPC.NewCachingProductClient(next, time.Millisecond, 2*time.Millisecond)
+
+	_, err := lookupPrice(t, c)
+	assert.NoError(t, err)
+
+	next.set(4.5, true)
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = lookupPrice(t, c)
+	assert.ErrorIs(t, err, errProductDown)
+}
+
+func TestCachingProductClientKeepsToTheMenuWindows(t *testing.T) {
+	t.Parallel()
+
+	happyHour := time.Now().Add(time.Hour)
+	next := &happyHourProductSvc{happyHour: happyHour}
+	c := infrasGRPC.NewCachingProductClient(next, time.Hour, time.Hour)
+	itemTypes := []shared.ItemType{shared.ItemTypeLatte, shared.ItemTypeCroissant}
+
+	lookup := func(at time.Time) ([]*domain.ItemModel, []*domain.MissingItem) {
+		items, missing, err := c.LookupItems(context.Background(), shared.LocationAtlanta, itemTypes, at)
+		assert.NoError(t, err)
+
+		return items, missing
+	}
+
+	items, _ := lookup(happyHour.Add(-time.Hour))
+	assert.Equal(t, 4.5, items[0].Price)
+
+	items, _ = lookup(happyHour.Add(-time.Minute))
+	assert.Equal(t, 4.5, items[0].Price)
+	assert.Equal(t, 1, next.calls)
+
+	items, missing := lookup(happyHour)
+	assert.Equal(t, 3.6, items[0].Price)
+	assert.Equal(t, []*domain.MissingItem{{ItemType: shared.ItemTypeCroissant, Reason: domain.MissingReasonUnavailable}}, missing)
+	assert.Equal(t, 2, next.calls)
+
+	_, missing = lookup(happyHour.Add(time.Minute))
+	assert.Len(t, missing, 1)
+	assert.Equal(t, 2, next.calls)
+
+	// the happy hour prices were looked up for the happy hour, not for the time before it
+	items, missing = lookup(happyHour.Add(-time.Minute))
+	assert.Equal(t, 4.5, items[0].Price)
+	assert.Empty(t, missing)
+	assert.Equal(t, 3, next.calls)
+}
@@ -2,18 +2,18 @@ package grpc
 
 import (
 	"context"
-	"fmt"
-	"strings"
+	"time"
 
-	"github.com/google/wire"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/thangchung/go-coffeeshop/cmd/counter/config"
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
 	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
 	gen "github.com/thangchung/go-coffeeshop/proto/gen"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type productGRPCClient struct {
@@ -22,49 +22,70 @@ type productGRPCClient struct {
 
 var _ domain.ProductDomainService = (*productGRPCClient)(nil)
 
-var ProductGRPCClientSet = wire.NewSet(NewGRPCProductClient)
-
-func NewGRPCProductClient(cfg *config.Config) (domain.ProductDomainService, error) {
-	conn, err := grpc.Dial(cfg.ProductClient.URL, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// NewProductConn connects to the product service, the connection is shared by the client and the readiness check.
+// The lookups for an order are made with the credentials of whoever placed it.
+func NewProductConn(cfg *config.Config) (*grpc.ClientConn, func(), error) {
+	conn, err := grpcx.Dial(
+		cfg.ProductClient.URL,
+		grpcx.ClientTLS(cfg.ProductClient.TLS),
+		grpcx.CallTimeout(cfg.ProductClient.Timeout),
+		grpcx.UnaryClientInterceptors(auth.UnaryClientInterceptor()),
+	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	return conn, func() { conn.Close() }, nil
+}
+
+func NewGRPCProductClient(conn *grpc.ClientConn) domain.ProductDomainService {
 	return &productGRPCClient{
 		conn: conn,
-	}, nil
+	}
 }
 
-func (p *productGRPCClient) GetItemsByType(
+func (p *productGRPCClient) LookupItems(
 	ctx context.Context,
-	model *domain.PlaceOrderModel,
-	isBarista bool,
-) ([]*domain.ItemModel, error) {
+	location shared.Location,
+	itemTypes []shared.ItemType,
+	at time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
 	c := gen.NewProductServiceClient(p.conn)
 
-	itemTypes := ""
-	if isBarista {
-		itemTypes = lo.Reduce(model.BaristaItems, func(agg string, item *domain.OrderItemModel, _ int) string {
-			return fmt.Sprintf("%s,%s", agg, item.ItemType.String())
-		}, "")
-	} else {
-		itemTypes = lo.Reduce(model.KitchenItems, func(agg string, item *domain.OrderItemModel, _ int) string {
-			return fmt.Sprintf("%s,%s", agg, item.ItemType.String())
-		}, "")
-	}
-
-	res, err := c.GetItemsByType(ctx, &gen.GetItemsByTypeRequest{ItemTypes: strings.TrimLeft(itemTypes, ",")})
+	res, err := c.LookupItems(ctx, &gen.LookupItemsRequest{
+		ItemTypes: lo.Map(itemTypes, func(t shared.ItemType, _ int) int32 { return int32(t) }),
+		Location:  int32(location),
+		At:        timestamppb.New(at),
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "productGRPCClient-c.GetItemsByType")
+		return nil, nil, errors.Wrap(err, "productGRPCClient-c.LookupItems")
 	}
 
-	results := make([]*domain.ItemModel, 0)
-	for _, item := range res.Items {
-		results = append(results, &domain.ItemModel{
-			ItemType: shared.ItemType(item.Type),
-			Price:    item.Price,
-		})
+	results := lo.Map(res.Items, func(item *gen.ItemDto, _ int) *domain.ItemModel {
+		return &domain.ItemModel{
+			ItemType:   shared.ItemType(item.Type),
+			Name:       item.Name,
+			Price:      item.Price,
+			ValidUntil: validUntil(item.ValidUntil),
+		}
+	})
+	missing := lo.Map(res.NotFound, func(item *gen.ItemNotFoundDto, _ int) *domain.MissingItem {
+		return &domain.MissingItem{
+			ItemType:   shared.ItemType(item.Type),
+			Name:       item.Name,
+			Reason:     item.Reason,
+			ValidUntil: validUntil(item.ValidUntil),
+		}
+	})
+
+	return results, missing, nil
+}
+
+// validUntil is zero when the product service doesn't say, the item is priced the same at any time.
+func validUntil(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
 	}
 
-	return results, nil
+	return t.AsTime()
 }
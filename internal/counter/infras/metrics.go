@@ -0,0 +1,57 @@
+package infras
+
+import (
+	"time"
+
+	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	"github.com/thangchung/go-coffeeshop/pkg/metrics"
+)
+
+var OrderMetricsSet = wire.NewSet(NewOrderMetrics)
+
+var (
+	ordersPlacedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "placed_total",
+		Help:      "Orders placed, by location and source.",
+	}, []string{"location", "source"})
+
+	lineItemsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "line_items_total",
+		Help:      "Line items of the placed orders, by the station which prepares them.",
+	}, []string{"station"})
+
+	fulfillmentSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "orders",
+		Name:      "fulfillment_seconds",
+		Help:      "Time from placing an order until all of its line items are fulfilled.",
+		Buckets:   []float64{1, 2, 5, 10, 20, 30, 60, 120, 300, 600, 1800},
+	}, []string{"location"})
+)
+
+// orderMetrics is stateless, the collectors are registered once for the process.
+type orderMetrics struct{}
+
+func NewOrderMetrics() orders.OrderMetrics {
+	return orderMetrics{}
+}
+
+func (orderMetrics) OrderPlaced(order *domain.Order) {
+	ordersPlacedTotal.WithLabelValues(order.Location.String(), order.OrderSource.String()).Inc()
+
+	for _, item := range order.LineItems {
+		lineItemsTotal.WithLabelValues(string(domain.StationOf(item))).Inc()
+	}
+}
+
+func (orderMetrics) OrderFulfilled(order *domain.Order, placedAt time.Time) {
+	fulfillmentSeconds.WithLabelValues(order.Location.String()).Observe(time.Since(placedAt).Seconds())
+}
@@ -6,11 +6,29 @@ package postgresql
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type OrderDailyItemSale struct {
+	SalesDate time.Time     `json:"sales_date"`
+	Location  sql.NullInt32 `json:"location"`
+	ItemType  int32         `json:"item_type"`
+	ItemName  string        `json:"item_name"`
+	Items     int64         `json:"items"`
+	Revenue   string        `json:"revenue"`
+}
+
+type OrderDailySale struct {
+	SalesDate time.Time     `json:"sales_date"`
+	Location  sql.NullInt32 `json:"location"`
+	Orders    int64         `json:"orders"`
+	Items     int64         `json:"items"`
+	Revenue   string        `json:"revenue"`
+}
+
 type OrderLineItem struct {
 	ID             uuid.UUID     `json:"id"`
 	ItemType       int32         `json:"item_type"`
@@ -23,10 +41,51 @@ type OrderLineItem struct {
 	Updated        sql.NullTime  `json:"updated"`
 }
 
+type OrderLineItemPrepTime struct {
+	LineItemID    uuid.UUID     `json:"line_item_id"`
+	CompletedDate time.Time     `json:"completed_date"`
+	Location      sql.NullInt32 `json:"location"`
+	ItemType      int32         `json:"item_type"`
+	ItemName      string        `json:"item_name"`
+	Station       string        `json:"station"`
+	Actor         string        `json:"actor"`
+	PrepSeconds   float64       `json:"prep_seconds"`
+	TotalSeconds  float64       `json:"total_seconds"`
+}
+
+type OrderLineItemTimeline struct {
+	ID         int64     `json:"id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Created    time.Time `json:"created"`
+}
+
 type OrderOrder struct {
-	ID              uuid.UUID    `json:"id"`
-	OrderSource     int32        `json:"order_source"`
-	LoyaltyMemberID uuid.UUID    `json:"loyalty_member_id"`
-	OrderStatus     int32        `json:"order_status"`
-	Updated         sql.NullTime `json:"updated"`
+	ID              uuid.UUID     `json:"id"`
+	OrderSource     int32         `json:"order_source"`
+	LoyaltyMemberID uuid.UUID     `json:"loyalty_member_id"`
+	OrderStatus     int32         `json:"order_status"`
+	Updated         sql.NullTime  `json:"updated"`
+	Version         int32         `json:"version"`
+	Location        sql.NullInt32 `json:"location"`
+}
+
+type OrderOrderEvent struct {
+	ID        int64           `json:"id"`
+	OrderID   uuid.UUID       `json:"order_id"`
+	Version   int32           `json:"version"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Created   time.Time       `json:"created"`
+}
+
+type OrderOrderSnapshot struct {
+	OrderID uuid.UUID       `json:"order_id"`
+	Version int32           `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+	Created time.Time       `json:"created"`
 }
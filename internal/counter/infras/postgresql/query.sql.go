@@ -8,11 +8,44 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const appendOrderEvent = `-- name: AppendOrderEvent :exec
+
+INSERT INTO
+    "order".order_events (
+        order_id,
+        version,
+        event_type,
+        payload,
+        created
+    )
+VALUES ($1, $2, $3, $4, $5)
+`
+
+type AppendOrderEventParams struct {
+	OrderID   uuid.UUID       `json:"order_id"`
+	Version   int32           `json:"version"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Created   time.Time       `json:"created"`
+}
+
+func (q *Queries) AppendOrderEvent(ctx context.Context, arg AppendOrderEventParams) error {
+	_, err := q.db.ExecContext(ctx, appendOrderEvent,
+		arg.OrderID,
+		arg.Version,
+		arg.EventType,
+		arg.Payload,
+		arg.Created,
+	)
+	return err
+}
+
 const createOrder = `-- name: CreateOrder :one
 
 INSERT INTO
@@ -21,17 +54,19 @@ INSERT INTO
         order_source,
         loyalty_member_id,
         order_status,
+        location,
         updated
     )
-VALUES ($1, $2, $3, $4, $5) RETURNING id, order_source, loyalty_member_id, order_status, updated
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, order_source, loyalty_member_id, order_status, updated, version, location
 `
 
 type CreateOrderParams struct {
-	ID              uuid.UUID    `json:"id"`
-	OrderSource     int32        `json:"order_source"`
-	LoyaltyMemberID uuid.UUID    `json:"loyalty_member_id"`
-	OrderStatus     int32        `json:"order_status"`
-	Updated         sql.NullTime `json:"updated"`
+	ID              uuid.UUID     `json:"id"`
+	OrderSource     int32         `json:"order_source"`
+	LoyaltyMemberID uuid.UUID     `json:"loyalty_member_id"`
+	OrderStatus     int32         `json:"order_status"`
+	Location        sql.NullInt32 `json:"location"`
+	Updated         sql.NullTime  `json:"updated"`
 }
 
 func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (OrderOrder, error) {
@@ -40,6 +75,7 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		arg.OrderSource,
 		arg.LoyaltyMemberID,
 		arg.OrderStatus,
+		arg.Location,
 		arg.Updated,
 	)
 	var i OrderOrder
@@ -49,6 +85,8 @@ func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) (Order
 		&i.LoyaltyMemberID,
 		&i.OrderStatus,
 		&i.Updated,
+		&i.Version,
+		&i.Location,
 	)
 	return i, err
 }
@@ -60,6 +98,8 @@ SELECT
     order_source,
     loyalty_member_id,
     order_status,
+    location,
+    version,
     l.id as "line_item_id",
     item_type,
     name,
@@ -75,6 +115,8 @@ type GetAllRow struct {
 	OrderSource     int32         `json:"order_source"`
 	LoyaltyMemberID uuid.UUID     `json:"loyalty_member_id"`
 	OrderStatus     int32         `json:"order_status"`
+	Location        sql.NullInt32 `json:"location"`
+	Version         int32         `json:"version"`
 	LineItemID      uuid.NullUUID `json:"line_item_id"`
 	ItemType        int32         `json:"item_type"`
 	Name            string        `json:"name"`
@@ -97,6 +139,8 @@ func (q *Queries) GetAll(ctx context.Context) ([]GetAllRow, error) {
 			&i.OrderSource,
 			&i.LoyaltyMemberID,
 			&i.OrderStatus,
+			&i.Location,
+			&i.Version,
 			&i.LineItemID,
 			&i.ItemType,
 			&i.Name,
@@ -124,6 +168,8 @@ SELECT
     order_source,
     loyalty_member_id,
     order_status,
+    location,
+    version,
     l.id as "line_item_id",
     item_type,
     name,
@@ -140,6 +186,8 @@ type GetByIDRow struct {
 	OrderSource     int32         `json:"order_source"`
 	LoyaltyMemberID uuid.UUID     `json:"loyalty_member_id"`
 	OrderStatus     int32         `json:"order_status"`
+	Location        sql.NullInt32 `json:"location"`
+	Version         int32         `json:"version"`
 	LineItemID      uuid.NullUUID `json:"line_item_id"`
 	ItemType        int32         `json:"item_type"`
 	Name            string        `json:"name"`
@@ -162,6 +210,8 @@ func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) ([]GetByIDRow, erro
 			&i.OrderSource,
 			&i.LoyaltyMemberID,
 			&i.OrderStatus,
+			&i.Location,
+			&i.Version,
 			&i.LineItemID,
 			&i.ItemType,
 			&i.Name,
@@ -182,6 +232,297 @@ func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) ([]GetByIDRow, erro
 	return items, nil
 }
 
+const getDailyItemSales = `-- name: GetDailyItemSales :many
+
+SELECT
+    sales_date,
+    location,
+    item_type,
+    item_name,
+    items,
+    revenue
+FROM "order".daily_item_sales
+WHERE sales_date >= $1 AND sales_date < $2
+ORDER BY sales_date, location, item_type
+`
+
+type GetDailyItemSalesParams struct {
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) GetDailyItemSales(ctx context.Context, arg GetDailyItemSalesParams) ([]OrderDailyItemSale, error) {
+	rows, err := q.db.QueryContext(ctx, getDailyItemSales, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderDailyItemSale
+	for rows.Next() {
+		var i OrderDailyItemSale
+		if err := rows.Scan(
+			&i.SalesDate,
+			&i.Location,
+			&i.ItemType,
+			&i.ItemName,
+			&i.Items,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDailySales = `-- name: GetDailySales :many
+
+SELECT
+    sales_date,
+    location,
+    orders,
+    items,
+    revenue
+FROM "order".daily_sales
+WHERE sales_date >= $1 AND sales_date < $2
+ORDER BY sales_date, location
+`
+
+type GetDailySalesParams struct {
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) GetDailySales(ctx context.Context, arg GetDailySalesParams) ([]OrderDailySale, error) {
+	rows, err := q.db.QueryContext(ctx, getDailySales, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderDailySale
+	for rows.Next() {
+		var i OrderDailySale
+		if err := rows.Scan(
+			&i.SalesDate,
+			&i.Location,
+			&i.Orders,
+			&i.Items,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestOrderSnapshot = `-- name: GetLatestOrderSnapshot :one
+
+SELECT
+    order_id,
+    version,
+    payload,
+    created
+FROM "order".order_snapshots
+WHERE order_id = $1
+ORDER BY version DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestOrderSnapshot(ctx context.Context, orderID uuid.UUID) (OrderOrderSnapshot, error) {
+	row := q.db.QueryRowContext(ctx, getLatestOrderSnapshot, orderID)
+	var i OrderOrderSnapshot
+	err := row.Scan(
+		&i.OrderID,
+		&i.Version,
+		&i.Payload,
+		&i.Created,
+	)
+	return i, err
+}
+
+const getLineItemPrepTimes = `-- name: GetLineItemPrepTimes :many
+
+SELECT
+    line_item_id,
+    completed_date,
+    location,
+    item_type,
+    item_name,
+    station,
+    actor,
+    prep_seconds,
+    total_seconds
+FROM "order".line_item_prep_times
+WHERE completed_date >= $1 AND completed_date < $2
+ORDER BY completed_date
+`
+
+type GetLineItemPrepTimesParams struct {
+	FromDate time.Time `json:"from_date"`
+	ToDate   time.Time `json:"to_date"`
+}
+
+func (q *Queries) GetLineItemPrepTimes(ctx context.Context, arg GetLineItemPrepTimesParams) ([]OrderLineItemPrepTime, error) {
+	rows, err := q.db.QueryContext(ctx, getLineItemPrepTimes, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrderLineItemPrepTime
+	for rows.Next() {
+		var i OrderLineItemPrepTime
+		if err := rows.Scan(
+			&i.LineItemID,
+			&i.CompletedDate,
+			&i.Location,
+			&i.ItemType,
+			&i.ItemName,
+			&i.Station,
+			&i.Actor,
+			&i.PrepSeconds,
+			&i.TotalSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderEvents = `-- name: GetOrderEvents :many
+
+SELECT
+    order_id,
+    version,
+    event_type,
+    payload,
+    created
+FROM "order".order_events
+WHERE order_id = $1 AND version > $2
+ORDER BY version
+`
+
+type GetOrderEventsParams struct {
+	OrderID uuid.UUID `json:"order_id"`
+	Version int32     `json:"version"`
+}
+
+type GetOrderEventsRow struct {
+	OrderID   uuid.UUID       `json:"order_id"`
+	Version   int32           `json:"version"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	Created   time.Time       `json:"created"`
+}
+
+func (q *Queries) GetOrderEvents(ctx context.Context, arg GetOrderEventsParams) ([]GetOrderEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderEvents, arg.OrderID, arg.Version)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderEventsRow
+	for rows.Next() {
+		var i GetOrderEventsRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.Version,
+			&i.EventType,
+			&i.Payload,
+			&i.Created,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderTimeline = `-- name: GetOrderTimeline :many
+
+SELECT
+    t.order_id,
+    t.line_item_id,
+    l.item_type,
+    l.name,
+    t.stage,
+    t.station,
+    t.actor,
+    t.occurred_at
+FROM "order".line_item_timeline t
+    INNER JOIN "order".line_items l ON l.id = t.line_item_id
+WHERE t.order_id = $1
+ORDER BY t.occurred_at, t.id
+`
+
+type GetOrderTimelineRow struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	ItemType   int32     `json:"item_type"`
+	Name       string    `json:"name"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (q *Queries) GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]GetOrderTimelineRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderTimeline, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderTimelineRow
+	for rows.Next() {
+		var i GetOrderTimelineRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.LineItemID,
+			&i.ItemType,
+			&i.Name,
+			&i.Stage,
+			&i.Station,
+			&i.Actor,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const insertItemLine = `-- name: InsertItemLine :one
 
 INSERT INTO
@@ -238,6 +579,123 @@ func (q *Queries) InsertItemLine(ctx context.Context, arg InsertItemLineParams)
 	return i, err
 }
 
+const insertTimelineEntry = `-- name: InsertTimelineEntry :exec
+
+INSERT INTO
+    "order".line_item_timeline (
+        order_id,
+        line_item_id,
+        stage,
+        station,
+        actor,
+        occurred_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (line_item_id, stage) DO NOTHING
+`
+
+type InsertTimelineEntryParams struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (q *Queries) InsertTimelineEntry(ctx context.Context, arg InsertTimelineEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertTimelineEntry,
+		arg.OrderID,
+		arg.LineItemID,
+		arg.Stage,
+		arg.Station,
+		arg.Actor,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const projectOrder = `-- name: ProjectOrder :exec
+
+INSERT INTO
+    "order".orders (
+        id,
+        order_source,
+        loyalty_member_id,
+        order_status,
+        location,
+        updated,
+        version
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO
+UPDATE
+SET
+    order_status = EXCLUDED.order_status,
+    updated = EXCLUDED.updated,
+    version = EXCLUDED.version
+`
+
+type ProjectOrderParams struct {
+	ID              uuid.UUID     `json:"id"`
+	OrderSource     int32         `json:"order_source"`
+	LoyaltyMemberID uuid.UUID     `json:"loyalty_member_id"`
+	OrderStatus     int32         `json:"order_status"`
+	Location        sql.NullInt32 `json:"location"`
+	Updated         sql.NullTime  `json:"updated"`
+	Version         int32         `json:"version"`
+}
+
+func (q *Queries) ProjectOrder(ctx context.Context, arg ProjectOrderParams) error {
+	_, err := q.db.ExecContext(ctx, projectOrder,
+		arg.ID,
+		arg.OrderSource,
+		arg.LoyaltyMemberID,
+		arg.OrderStatus,
+		arg.Location,
+		arg.Updated,
+		arg.Version,
+	)
+	return err
+}
+
+const refreshReports = `-- name: RefreshReports :exec
+
+SELECT "order".refresh_reports()
+`
+
+func (q *Queries) RefreshReports(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, refreshReports)
+	return err
+}
+
+const saveOrderSnapshot = `-- name: SaveOrderSnapshot :exec
+
+INSERT INTO
+    "order".order_snapshots (
+        order_id,
+        version,
+        payload,
+        created
+    )
+VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING
+`
+
+type SaveOrderSnapshotParams struct {
+	OrderID uuid.UUID       `json:"order_id"`
+	Version int32           `json:"version"`
+	Payload json.RawMessage `json:"payload"`
+	Created time.Time       `json:"created"`
+}
+
+func (q *Queries) SaveOrderSnapshot(ctx context.Context, arg SaveOrderSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, saveOrderSnapshot,
+		arg.OrderID,
+		arg.Version,
+		arg.Payload,
+		arg.Created,
+	)
+	return err
+}
+
 const updateItemLine = `-- name: UpdateItemLine :exec
 
 UPDATE "order".line_items
@@ -258,22 +716,32 @@ func (q *Queries) UpdateItemLine(ctx context.Context, arg UpdateItemLineParams)
 	return err
 }
 
-const updateOrder = `-- name: UpdateOrder :exec
+const updateOrder = `-- name: UpdateOrder :execrows
 
 UPDATE "order".orders
 SET
     order_status = $2,
-    updated = $3
-WHERE id = $1
+    updated = $3,
+    version = version + 1
+WHERE id = $1 AND version = $4
 `
 
 type UpdateOrderParams struct {
 	ID          uuid.UUID    `json:"id"`
 	OrderStatus int32        `json:"order_status"`
 	Updated     sql.NullTime `json:"updated"`
+	Version     int32        `json:"version"`
 }
 
-func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) error {
-	_, err := q.db.ExecContext(ctx, updateOrder, arg.ID, arg.OrderStatus, arg.Updated)
-	return err
+func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateOrder,
+		arg.ID,
+		arg.OrderStatus,
+		arg.Updated,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -5,6 +5,8 @@ SELECT
     order_source,
     loyalty_member_id,
     order_status,
+    location,
+    version,
     l.id as "line_item_id",
     item_type,
     name,
@@ -21,6 +23,8 @@ SELECT
     order_source,
     loyalty_member_id,
     order_status,
+    location,
+    version,
     l.id as "line_item_id",
     item_type,
     name,
@@ -39,9 +43,10 @@ INSERT INTO
         order_source,
         loyalty_member_id,
         order_status,
+        location,
         updated
     )
-VALUES ($1, $2, $3, $4, $5) RETURNING *;
+VALUES ($1, $2, $3, $4, $5, $6) RETURNING *;
 
 -- name: InsertItemLine :one
 
@@ -59,13 +64,14 @@ INSERT INTO
     )
 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
 
--- name: UpdateOrder :exec
+-- name: UpdateOrder :execrows
 
 UPDATE "order".orders
 SET
     order_status = $2,
-    updated = $3
-WHERE id = $1;
+    updated = $3,
+    version = version + 1
+WHERE id = $1 AND version = $4;
 
 -- name: UpdateItemLine :exec
 
@@ -73,4 +79,144 @@ UPDATE "order".line_items
 SET
     item_status = $2,
     updated = $3
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: AppendOrderEvent :exec
+
+INSERT INTO
+    "order".order_events (
+        order_id,
+        version,
+        event_type,
+        payload,
+        created
+    )
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetOrderEvents :many
+
+SELECT
+    order_id,
+    version,
+    event_type,
+    payload,
+    created
+FROM "order".order_events
+WHERE order_id = $1 AND version > $2
+ORDER BY version;
+
+-- name: SaveOrderSnapshot :exec
+
+INSERT INTO
+    "order".order_snapshots (
+        order_id,
+        version,
+        payload,
+        created
+    )
+VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING;
+
+-- name: GetLatestOrderSnapshot :one
+
+SELECT
+    order_id,
+    version,
+    payload,
+    created
+FROM "order".order_snapshots
+WHERE order_id = $1
+ORDER BY version DESC
+LIMIT 1;
+
+-- name: ProjectOrder :exec
+
+INSERT INTO
+    "order".orders (
+        id,
+        order_source,
+        loyalty_member_id,
+        order_status,
+        location,
+        updated,
+        version
+    )
+VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO
+UPDATE
+SET
+    order_status = EXCLUDED.order_status,
+    updated = EXCLUDED.updated,
+    version = EXCLUDED.version;
+
+-- name: InsertTimelineEntry :exec
+
+INSERT INTO
+    "order".line_item_timeline (
+        order_id,
+        line_item_id,
+        stage,
+        station,
+        actor,
+        occurred_at
+    )
+VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (line_item_id, stage) DO NOTHING;
+
+-- name: GetOrderTimeline :many
+
+SELECT
+    t.order_id,
+    t.line_item_id,
+    l.item_type,
+    l.name,
+    t.stage,
+    t.station,
+    t.actor,
+    t.occurred_at
+FROM "order".line_item_timeline t
+    INNER JOIN "order".line_items l ON l.id = t.line_item_id
+WHERE t.order_id = $1
+ORDER BY t.occurred_at, t.id;
+
+-- name: GetDailySales :many
+
+SELECT
+    sales_date,
+    location,
+    orders,
+    items,
+    revenue
+FROM "order".daily_sales
+WHERE sales_date >= sqlc.arg(from_date) AND sales_date < sqlc.arg(to_date)
+ORDER BY sales_date, location;
+
+-- name: GetDailyItemSales :many
+
+SELECT
+    sales_date,
+    location,
+    item_type,
+    item_name,
+    items,
+    revenue
+FROM "order".daily_item_sales
+WHERE sales_date >= sqlc.arg(from_date) AND sales_date < sqlc.arg(to_date)
+ORDER BY sales_date, location, item_type;
+
+-- name: GetLineItemPrepTimes :many
+
+SELECT
+    line_item_id,
+    completed_date,
+    location,
+    item_type,
+    item_name,
+    station,
+    actor,
+    prep_seconds,
+    total_seconds
+FROM "order".line_item_prep_times
+WHERE completed_date >= sqlc.arg(from_date) AND completed_date < sqlc.arg(to_date)
+ORDER BY completed_date;
+
+-- name: RefreshReports :exec
+
+SELECT "order".refresh_reports();
@@ -5,7 +5,7 @@ import (
 
 	"github.com/google/wire"
 	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 )
 
 var (
@@ -15,20 +15,26 @@ var (
 
 type (
 	baristaEventPublisher struct {
-		pub publisher.EventPublisher
+		pub messaging.Publisher
 	}
 	kitchenEventPublisher struct {
-		pub publisher.EventPublisher
+		pub messaging.Publisher
 	}
 )
 
-func NewBaristaEventPublisher(pub publisher.EventPublisher) orders.BaristaEventPublisher {
+// NewBaristaEventPublisher opens a publisher of its own on the broker, the kitchen one is routed elsewhere.
+func NewBaristaEventPublisher(broker messaging.Broker) (orders.BaristaEventPublisher, error) {
+	pub, err := broker.NewPublisher()
+	if err != nil {
+		return nil, err
+	}
+
 	return &baristaEventPublisher{
 		pub: pub,
-	}
+	}, nil
 }
 
-func (p *baristaEventPublisher) Configure(opts ...publisher.Option) {
+func (p *baristaEventPublisher) Configure(opts ...messaging.Option) {
 	p.pub.Configure(opts...)
 }
 
@@ -36,13 +42,19 @@ func (p *baristaEventPublisher) Publish(ctx context.Context, body []byte, conten
 	return p.pub.Publish(ctx, body, contentType)
 }
 
-func NewKitchenEventPublisher(pub publisher.EventPublisher) orders.KitchenEventPublisher {
+// NewKitchenEventPublisher opens a publisher of its own on the broker, the barista one is routed elsewhere.
+func NewKitchenEventPublisher(broker messaging.Broker) (orders.KitchenEventPublisher, error) {
+	pub, err := broker.NewPublisher()
+	if err != nil {
+		return nil, err
+	}
+
 	return &kitchenEventPublisher{
 		pub: pub,
-	}
+	}, nil
 }
 
-func (p *kitchenEventPublisher) Configure(opts ...publisher.Option) {
+func (p *kitchenEventPublisher) Configure(opts ...messaging.Option) {
 	p.pub.Configure(opts...)
 }
 
@@ -0,0 +1,112 @@
+package repo_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/repo"
+	events "github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+// _pgTestDSN names the PostgreSQL the tests which need one run against, they are skipped without it, e.g.
+// PG_TEST_DSN_URL="host=127.0.0.1 user=postgres password=P@ssw0rd dbname=postgres sslmode=disable".
+const _pgTestDSN = "PG_TEST_DSN_URL"
+
+type fakeProductSvc struct{}
+
+func (fakeProductSvc) LookupItems(
+	_ context.Context,
+	_ shared.Location,
+	itemTypes []shared.ItemType,
+	_ time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	items := make([]*domain.ItemModel, 0, len(itemTypes))
+	for _, itemType := range itemTypes {
+		items = append(items, &domain.ItemModel{ItemType: itemType, Name: itemType.String(), Price: 3})
+	}
+
+	return items, nil, nil
+}
+
+func newPgx(t *testing.T) postgres.DBEngine {
+	t.Helper()
+
+	dsn := os.Getenv(_pgTestDSN)
+	if dsn == "" {
+		t.Skipf("%s is not set", _pgTestDSN)
+	}
+
+	// closing the migrate closes its database, the repos get one of their own
+	migrateDB, err := postgres.NewPgxDB(postgres.DBConnString(dsn), postgres.Name("counter-pgx-migrate-test"))
+	require.NoError(t, err)
+
+	m, err := migrations.New(migrations.Counter, migrateDB.GetDB())
+	require.NoError(t, err)
+
+	if err = m.Up(); err != nil {
+		require.ErrorIs(t, err, migrate.ErrNoChange)
+	}
+
+	_, err = m.Close()
+	require.NoError(t, err)
+
+	pg, err := postgres.NewPgxDB(postgres.DBConnString(dsn), postgres.Name("counter-pgx-test"))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	return pg
+}
+
+func TestEventSourcedRepoReportsAVersionConflictWithPgx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pg := newPgx(t)
+	orderRepo := repo.NewEventSourcedOrderRepo(pg, postgres.NewUnitOfWork(pg), 0)
+
+	order, err := domain.CreateOrderFrom(ctx, &domain.PlaceOrderModel{
+		OrderSource:     shared.OrderSourceCounter,
+		Location:        shared.LocationAtlanta,
+		LoyaltyMemberID: uuid.New(),
+		BaristaItems:    []*domain.OrderItemModel{{ItemType: shared.ItemTypeLatte}},
+	}, fakeProductSvc{})
+	require.NoError(t, err)
+	require.NoError(t, orderRepo.Create(ctx, order))
+
+	// two handlers load the same version of the order and report its item
+	first, err := orderRepo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+
+	second, err := orderRepo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+
+	for _, o := range []*domain.Order{first, second} {
+		require.NoError(t, o.Apply(&events.OrderUp{
+			OrderID:    o.ID,
+			ItemLineID: o.LineItems[0].ID,
+			MadeBy:     "teesee",
+			TimeUp:     time.Now(),
+		}))
+	}
+
+	_, err = orderRepo.Update(ctx, first)
+	require.NoError(t, err)
+
+	// pgx reports the unique violation as a *pgconn.PgError, not a *pq.Error
+	_, err = orderRepo.Update(ctx, second)
+	assert.ErrorIs(t, err, domain.ErrOrderVersionConflict)
+
+	got, err := orderRepo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, shared.StatusFulfilled, got.OrderStatus)
+}
@@ -0,0 +1,234 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/postgresql"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"golang.org/x/exp/slog"
+)
+
+const _defaultSnapshotEvery = 10
+
+// eventFactories knows how to decode the events which change the state of an order.
+// Everything else in the stream (e.g. BaristaOrdered) is kept for the audit trail only.
+var eventFactories = map[string]func() shared.DomainEvent{
+	(&domain.OrderPlaced{}).Identity():       func() shared.DomainEvent { return &domain.OrderPlaced{} },
+	(&domain.LineItemAdded{}).Identity():     func() shared.DomainEvent { return &domain.LineItemAdded{} },
+	(&domain.LineItemFulfilled{}).Identity(): func() shared.DomainEvent { return &domain.LineItemFulfilled{} },
+	(&domain.OrderFulfilled{}).Identity():    func() shared.DomainEvent { return &domain.OrderFulfilled{} },
+}
+
+type orderSnapshot struct {
+	ID              uuid.UUID          `json:"id"`
+	OrderSource     shared.OrderSource `json:"orderSource"`
+	LoyaltyMemberID uuid.UUID          `json:"loyaltyMemberId"`
+	OrderStatus     shared.Status      `json:"orderStatus"`
+	Location        shared.Location    `json:"location"`
+	LineItems       []*domain.LineItem `json:"lineItems"`
+}
+
+type eventSourcedOrderRepo struct {
+	pg            postgres.DBEngine
+	uow           postgres.UnitOfWork
+	readModel     orders.OrderRepo
+	projector     *orderProjector
+	snapshotEvery int32
+}
+
+var _ orders.OrderRepo = (*eventSourcedOrderRepo)(nil)
+
+// NewEventSourcedOrderRepo stores orders as a stream of domain events in "order".order_events
+// and rebuilds them by replaying the stream on top of the latest snapshot.
+// The orders/line_items tables are kept up to date by a projector in the same transaction,
+// so GetAll keeps reading from them.
+func NewEventSourcedOrderRepo(pg postgres.DBEngine, uow postgres.UnitOfWork, snapshotEvery int) orders.OrderRepo {
+	if snapshotEvery <= 0 {
+		snapshotEvery = _defaultSnapshotEvery
+	}
+
+	return &eventSourcedOrderRepo{
+		pg:            pg,
+		uow:           uow,
+		readModel:     NewOrderRepo(pg, uow),
+		projector:     &orderProjector{},
+		snapshotEvery: int32(snapshotEvery),
+	}
+}
+
+func (d *eventSourcedOrderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
+	return d.readModel.GetAll(ctx)
+}
+
+func (d *eventSourcedOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	querier := postgresql.New(postgres.Conn(ctx, d.pg))
+
+	order := &domain.Order{}
+
+	snapshot, err := querier.GetLatestOrderSnapshot(ctx, id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Wrap(err, "querier.GetLatestOrderSnapshot")
+	}
+
+	if err == nil {
+		var state orderSnapshot
+		if err = json.Unmarshal(snapshot.Payload, &state); err != nil {
+			return nil, errors.Wrap(err, "json.Unmarshal[snapshot]")
+		}
+
+		order = &domain.Order{
+			ID:              state.ID,
+			OrderSource:     state.OrderSource,
+			LoyaltyMemberID: state.LoyaltyMemberID,
+			OrderStatus:     state.OrderStatus,
+			Location:        state.Location,
+			LineItems:       state.LineItems,
+			Version:         snapshot.Version,
+		}
+	}
+
+	rows, err := querier.GetOrderEvents(ctx, postgresql.GetOrderEventsParams{
+		OrderID: id,
+		Version: order.Version,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetOrderEvents")
+	}
+
+	if order.Version == 0 && len(rows) == 0 {
+		return nil, nil
+	}
+
+	history := make([]shared.DomainEvent, 0, len(rows))
+
+	for _, row := range rows {
+		order.Version = row.Version
+
+		factory, ok := eventFactories[row.EventType]
+		if !ok {
+			continue
+		}
+
+		e := factory()
+		if err = json.Unmarshal(row.Payload, e); err != nil {
+			return nil, errors.Wrapf(err, "json.Unmarshal[%s]", row.EventType)
+		}
+
+		history = append(history, e)
+	}
+
+	order.Replay(history)
+
+	return order, nil
+}
+
+func (d *eventSourcedOrderRepo) Create(ctx context.Context, order *domain.Order) error {
+	return d.append(ctx, order)
+}
+
+func (d *eventSourcedOrderRepo) Update(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	if err := d.append(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// append writes the pending domain events of the order after its current version.
+// A concurrent writer that appended first makes the unique (order_id, version) constraint fail,
+// which is reported as domain.ErrOrderVersionConflict.
+func (d *eventSourcedOrderRepo) append(ctx context.Context, order *domain.Order) error {
+	pending := order.DomainEvents()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	expectedVersion := order.Version
+	version := expectedVersion
+
+	err := d.uow.WithinTx(ctx, func(ctx context.Context) error {
+		qtx := postgresql.New(postgres.Conn(ctx, d.pg))
+
+		for _, e := range pending {
+			payload, err := json.Marshal(e)
+			if err != nil {
+				return errors.Wrapf(err, "json.Marshal[%s]", e.Identity())
+			}
+
+			version++
+
+			err = qtx.AppendOrderEvent(ctx, postgresql.AppendOrderEventParams{
+				OrderID:   order.ID,
+				Version:   version,
+				EventType: e.Identity(),
+				Payload:   payload,
+				Created:   time.Now(),
+			})
+			if err != nil {
+				if postgres.IsUniqueViolation(err) {
+					return domain.ErrOrderVersionConflict
+				}
+
+				return errors.Wrap(err, "qtx.AppendOrderEvent")
+			}
+		}
+
+		if err := d.projector.Project(ctx, qtx, order, pending, version); err != nil {
+			return errors.Wrap(err, "projector.Project")
+		}
+
+		if version/d.snapshotEvery > expectedVersion/d.snapshotEvery {
+			return d.saveSnapshot(ctx, qtx, order, version)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	order.Version = version
+
+	slog.Debug("order events appended", "order_id", order.ID, "version", version)
+
+	return nil
+}
+
+func (d *eventSourcedOrderRepo) saveSnapshot(
+	ctx context.Context,
+	qtx *postgresql.Queries,
+	order *domain.Order,
+	version int32,
+) error {
+	payload, err := json.Marshal(orderSnapshot{
+		ID:              order.ID,
+		OrderSource:     order.OrderSource,
+		LoyaltyMemberID: order.LoyaltyMemberID,
+		OrderStatus:     order.OrderStatus,
+		Location:        order.Location,
+		LineItems:       order.LineItems,
+	})
+	if err != nil {
+		return errors.Wrap(err, "json.Marshal[snapshot]")
+	}
+
+	err = qtx.SaveOrderSnapshot(ctx, postgresql.SaveOrderSnapshotParams{
+		OrderID: order.ID,
+		Version: version,
+		Payload: payload,
+		Created: time.Now(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "qtx.SaveOrderSnapshot")
+	}
+
+	return nil
+}
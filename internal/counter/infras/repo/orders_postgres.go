@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/google/wire"
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
@@ -21,19 +20,18 @@ import (
 const _defaultEntityCap = 64
 
 type orderRepo struct {
-	pg postgres.DBEngine
+	pg  postgres.DBEngine
+	uow postgres.UnitOfWork
 }
 
 var _ orders.OrderRepo = (*orderRepo)(nil)
 
-var RepositorySet = wire.NewSet(NewOrderRepo)
-
-func NewOrderRepo(pg postgres.DBEngine) orders.OrderRepo {
-	return &orderRepo{pg: pg}
+func NewOrderRepo(pg postgres.DBEngine, uow postgres.UnitOfWork) orders.OrderRepo {
+	return &orderRepo{pg: pg, uow: uow}
 }
 
 func (d *orderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
-	querier := postgresql.New(d.pg.GetDB())
+	querier := postgresql.New(postgres.Conn(ctx, d.pg))
 
 	results, err := querier.GetAll(ctx)
 	if err != nil {
@@ -49,6 +47,8 @@ func (d *orderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
 			OrderSource:     shared.OrderSource(x.OrderSource),
 			LoyaltyMemberID: x.LoyaltyMemberID,
 			OrderStatus:     shared.Status(x.OrderStatus),
+			Location:        locationOf(x.Location),
+			Version:         x.Version,
 		}
 	})
 	lineItems := lo.Map(results, func(x postgresql.GetAllRow, _ int) *domain.LineItem {
@@ -76,6 +76,8 @@ func (d *orderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
 			OrderSource:     o.OrderSource,
 			LoyaltyMemberID: o.LoyaltyMemberID,
 			OrderStatus:     o.OrderStatus,
+			Location:        o.Location,
+			Version:         o.Version,
 		}
 
 		filters := lo.Filter(lineItems, func(x *domain.LineItem, _ int) bool {
@@ -101,7 +103,7 @@ func (d *orderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
 }
 
 func (d *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
-	querier := postgresql.New(d.pg.GetDB())
+	querier := postgresql.New(postgres.Conn(ctx, d.pg))
 
 	results, err := querier.GetByID(ctx, id)
 	if err != nil {
@@ -118,6 +120,8 @@ func (d *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, e
 			OrderSource:     shared.OrderSource(x.OrderSource),
 			LoyaltyMemberID: x.LoyaltyMemberID,
 			OrderStatus:     shared.Status(x.OrderStatus),
+			Location:        locationOf(x.Location),
+			Version:         x.Version,
 		}
 	})
 	lineItems := lo.Map(results, func(x postgresql.GetByIDRow, _ int) *domain.LineItem {
@@ -147,6 +151,8 @@ func (d *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, e
 		OrderSource:     orders[0].OrderSource,
 		LoyaltyMemberID: orders[0].LoyaltyMemberID,
 		OrderStatus:     orders[0].OrderStatus,
+		Location:        orders[0].Location,
+		Version:         orders[0].Version,
 	}
 
 	for _, ol := range lineItems {
@@ -164,96 +170,111 @@ func (d *orderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, e
 }
 
 func (d *orderRepo) Create(ctx context.Context, order *domain.Order) error {
-	db := d.pg.GetDB()
-	querier := postgresql.New(db)
+	return d.uow.WithinTx(ctx, func(ctx context.Context) error {
+		querier := postgresql.New(postgres.Conn(ctx, d.pg))
+
+		_, err := querier.CreateOrder(ctx, postgresql.CreateOrderParams{
+			ID:              order.ID,
+			OrderSource:     int32(order.OrderSource),
+			LoyaltyMemberID: order.LoyaltyMemberID,
+			OrderStatus:     int32(order.OrderStatus),
+			Location:        locationValue(order.Location),
+			Updated: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "querier.CreateOrder(ctx, postgresql.CreateOrderParams{})")
+		}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return errors.Wrap(err, "baristaOrderedEventHandler.Handle")
-	}
+		// continue to insert order items
+		for _, item := range order.LineItems {
+			_, err = querier.InsertItemLine(ctx, postgresql.InsertItemLineParams{
+				ID:             item.ID,
+				ItemType:       int32(item.ItemType),
+				Name:           item.Name,
+				Price:          fmt.Sprintf("%f", item.Price),
+				ItemStatus:     int32(item.ItemStatus),
+				IsBaristaOrder: item.IsBaristaOrder,
+				OrderID: uuid.NullUUID{
+					UUID:  order.ID,
+					Valid: true,
+				},
+				Created: time.Now(),
+				Updated: sql.NullTime{
+					Time:  time.Now(),
+					Valid: true,
+				},
+			})
 
-	qtx := querier.WithTx(tx)
-
-	_, err = qtx.CreateOrder(ctx, postgresql.CreateOrderParams{
-		ID:              order.ID,
-		OrderSource:     int32(order.OrderSource),
-		LoyaltyMemberID: order.LoyaltyMemberID,
-		OrderStatus:     int32(order.OrderStatus),
-		Updated: sql.NullTime{
-			Time:  time.Now(),
-			Valid: true,
-		},
+			if err != nil {
+				return errors.Wrap(err, "querier.InsertItemLine(ctx, postgresql.InsertItemLineParams{})")
+			}
+		}
+
+		return nil
 	})
-	if err != nil {
-		return errors.Wrap(err, "qtx.CreateOrder(ctx, postgresql.CreateOrderParams{})")
-	}
+}
 
-	// continue to insert order items
-	for _, item := range order.LineItems {
-		_, err = qtx.InsertItemLine(ctx, postgresql.InsertItemLineParams{
-			ID:             item.ID,
-			ItemType:       int32(item.ItemType),
-			Name:           item.Name,
-			Price:          fmt.Sprintf("%f", item.Price),
-			ItemStatus:     int32(item.ItemStatus),
-			IsBaristaOrder: item.IsBaristaOrder,
-			OrderID: uuid.NullUUID{
-				UUID:  order.ID,
-				Valid: true,
-			},
-			Created: time.Now(),
+func (d *orderRepo) Update(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	err := d.uow.WithinTx(ctx, func(ctx context.Context) error {
+		querier := postgresql.New(postgres.Conn(ctx, d.pg))
+
+		affected, err := querier.UpdateOrder(ctx, postgresql.UpdateOrderParams{
+			ID:          order.ID,
+			OrderStatus: int32(order.OrderStatus),
 			Updated: sql.NullTime{
 				Time:  time.Now(),
 				Valid: true,
 			},
+			Version: order.Version,
 		})
-
 		if err != nil {
-			return errors.Wrap(err, "qtx.InsertItemLine(ctx, postgresql.InsertItemLineParams{})")
+			return errors.Wrap(err, "querier.UpdateOrder(ctx, postgresql.UpdateOrderParams{})")
 		}
-	}
-
-	return tx.Commit()
-}
 
-func (d *orderRepo) Update(ctx context.Context, order *domain.Order) (*domain.Order, error) {
-	db := d.pg.GetDB()
-	querier := postgresql.New(db)
+		// someone else has updated the order since we loaded it
+		if affected == 0 {
+			return domain.ErrOrderVersionConflict
+		}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return nil, errors.Wrap(err, "baristaOrderedEventHandler.Handle")
-	}
+		// continue to insert order items
+		for _, item := range order.LineItems {
+			err = querier.UpdateItemLine(ctx, postgresql.UpdateItemLineParams{
+				ID:         item.ID,
+				ItemStatus: int32(item.ItemStatus),
+				Updated: sql.NullTime{
+					Time:  time.Now(),
+					Valid: true,
+				},
+			})
 
-	qtx := querier.WithTx(tx)
+			if err != nil {
+				return errors.Wrap(err, "querier.UpdateItemLine(ctx, postgresql.UpdateItemLineParams{})")
+			}
+		}
 
-	err = qtx.UpdateOrder(ctx, postgresql.UpdateOrderParams{
-		ID:          order.ID,
-		OrderStatus: int32(order.OrderStatus),
-		Updated: sql.NullTime{
-			Time:  time.Now(),
-			Valid: true,
-		},
+		return nil
 	})
 	if err != nil {
-		return nil, errors.Wrap(err, "qtx.UpdateOrder(ctx, postgresql.UpdateOrderParams{})")
+		return nil, err
 	}
 
-	// continue to insert order items
-	for _, item := range order.LineItems {
-		err = qtx.UpdateItemLine(ctx, postgresql.UpdateItemLineParams{
-			ID:         item.ID,
-			ItemStatus: int32(item.ItemStatus),
-			Updated: sql.NullTime{
-				Time:  time.Now(),
-				Valid: true,
-			},
-		})
+	order.Version++
 
-		if err != nil {
-			return nil, errors.Wrap(err, "qtx.UpdateItemLine(ctx, postgresql.UpdateItemLineParams{})")
-		}
+	return order, nil
+}
+
+// locationOf reads the location column, the orders placed before it was added have none.
+func locationOf(location sql.NullInt32) shared.Location {
+	if !location.Valid {
+		return shared.LocationUnknown
 	}
 
-	return nil, tx.Commit()
+	return shared.Location(location.Int32)
+}
+
+func locationValue(location shared.Location) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(location), Valid: location != shared.LocationUnknown}
 }
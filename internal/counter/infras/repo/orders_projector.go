@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/postgresql"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+// orderProjector keeps the "order".orders and "order".line_items read tables in sync with the event store.
+type orderProjector struct{}
+
+func (p *orderProjector) Project(
+	ctx context.Context,
+	qtx *postgresql.Queries,
+	order *domain.Order,
+	events []shared.DomainEvent,
+	version int32,
+) error {
+	now := sql.NullTime{
+		Time:  time.Now(),
+		Valid: true,
+	}
+
+	// the order row goes first, line items reference it
+	err := qtx.ProjectOrder(ctx, postgresql.ProjectOrderParams{
+		ID:              order.ID,
+		OrderSource:     int32(order.OrderSource),
+		LoyaltyMemberID: order.LoyaltyMemberID,
+		OrderStatus:     int32(order.OrderStatus),
+		Location:        locationValue(order.Location),
+		Updated:         now,
+		Version:         version,
+	})
+	if err != nil {
+		return errors.Wrap(err, "qtx.ProjectOrder")
+	}
+
+	for _, e := range events {
+		switch e := e.(type) {
+		case *domain.LineItemAdded:
+			_, err = qtx.InsertItemLine(ctx, postgresql.InsertItemLineParams{
+				ID:             e.ItemLineID,
+				ItemType:       int32(e.ItemType),
+				Name:           e.Name,
+				Price:          fmt.Sprintf("%f", e.Price),
+				ItemStatus:     int32(e.ItemStatus),
+				IsBaristaOrder: e.IsBaristaOrder,
+				OrderID: uuid.NullUUID{
+					UUID:  e.OrderID,
+					Valid: true,
+				},
+				Created: e.Created,
+				Updated: now,
+			})
+			if err != nil {
+				return errors.Wrap(err, "qtx.InsertItemLine")
+			}
+		case *domain.LineItemFulfilled:
+			err = qtx.UpdateItemLine(ctx, postgresql.UpdateItemLineParams{
+				ID:         e.ItemLineID,
+				ItemStatus: int32(shared.StatusFulfilled),
+				Updated:    now,
+			})
+			if err != nil {
+				return errors.Wrap(err, "qtx.UpdateItemLine")
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/sqlite"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type sqliteOrderRepo struct {
+	pg  postgres.DBEngine
+	uow postgres.UnitOfWork
+}
+
+var _ orders.OrderRepo = (*sqliteOrderRepo)(nil)
+
+// NewSQLiteOrderRepo stores the orders in the tables of the SQLite migrations,
+// every query runs on the transaction of ctx, SQLite serves one connection only.
+func NewSQLiteOrderRepo(pg postgres.DBEngine, uow postgres.UnitOfWork) orders.OrderRepo {
+	return &sqliteOrderRepo{pg: pg, uow: uow}
+}
+
+// sqliteOrderRow is a row of GetAll and GetByID, which select the same columns.
+type sqliteOrderRow struct {
+	ID              uuid.UUID
+	OrderSource     int64
+	LoyaltyMemberID uuid.UUID
+	OrderStatus     int64
+	Location        int64
+	Version         int64
+	LineItemID      uuid.UUID
+	ItemType        int64
+	Name            string
+	Price           float64
+	ItemStatus      int64
+	IsBaristaOrder  bool
+}
+
+func (d *sqliteOrderRepo) GetAll(ctx context.Context) ([]*domain.Order, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	results, err := querier.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetAll")
+	}
+
+	return toOrders(lo.Map(results, func(x sqlite.GetAllRow, _ int) sqliteOrderRow {
+		return sqliteOrderRow(x)
+	})), nil
+}
+
+func (d *sqliteOrderRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Order, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	results, err := querier.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetByID")
+	}
+
+	entities := toOrders(lo.Map(results, func(x sqlite.GetByIDRow, _ int) sqliteOrderRow {
+		return sqliteOrderRow(x)
+	}))
+	if len(entities) == 0 {
+		return nil, nil
+	}
+
+	return entities[0], nil
+}
+
+func (d *sqliteOrderRepo) Create(ctx context.Context, order *domain.Order) error {
+	return d.uow.WithinTx(ctx, func(ctx context.Context) error {
+		querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+		err := querier.CreateOrder(ctx, sqlite.CreateOrderParams{
+			ID:              order.ID,
+			OrderSource:     int64(order.OrderSource),
+			LoyaltyMemberID: order.LoyaltyMemberID,
+			OrderStatus:     int64(order.OrderStatus),
+			Location:        int64(order.Location),
+			Updated: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return errors.Wrap(err, "querier.CreateOrder(ctx, sqlite.CreateOrderParams{})")
+		}
+
+		for _, item := range order.LineItems {
+			err = querier.InsertItemLine(ctx, sqlite.InsertItemLineParams{
+				ID:             item.ID,
+				ItemType:       int64(item.ItemType),
+				Name:           item.Name,
+				Price:          float64(item.Price),
+				ItemStatus:     int64(item.ItemStatus),
+				IsBaristaOrder: item.IsBaristaOrder,
+				OrderID: uuid.NullUUID{
+					UUID:  order.ID,
+					Valid: true,
+				},
+				Created: time.Now(),
+				Updated: sql.NullTime{
+					Time:  time.Now(),
+					Valid: true,
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "querier.InsertItemLine(ctx, sqlite.InsertItemLineParams{})")
+			}
+		}
+
+		return nil
+	})
+}
+
+func (d *sqliteOrderRepo) Update(ctx context.Context, order *domain.Order) (*domain.Order, error) {
+	err := d.uow.WithinTx(ctx, func(ctx context.Context) error {
+		querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+		affected, err := querier.UpdateOrder(ctx, sqlite.UpdateOrderParams{
+			ID:          order.ID,
+			OrderStatus: int64(order.OrderStatus),
+			Updated: sql.NullTime{
+				Time:  time.Now(),
+				Valid: true,
+			},
+			Version: int64(order.Version),
+		})
+		if err != nil {
+			return errors.Wrap(err, "querier.UpdateOrder(ctx, sqlite.UpdateOrderParams{})")
+		}
+
+		// someone else has updated the order since we loaded it
+		if affected == 0 {
+			return domain.ErrOrderVersionConflict
+		}
+
+		for _, item := range order.LineItems {
+			err = querier.UpdateItemLine(ctx, sqlite.UpdateItemLineParams{
+				ID:         item.ID,
+				ItemStatus: int64(item.ItemStatus),
+				Updated: sql.NullTime{
+					Time:  time.Now(),
+					Valid: true,
+				},
+			})
+			if err != nil {
+				return errors.Wrap(err, "querier.UpdateItemLine(ctx, sqlite.UpdateItemLineParams{})")
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	order.Version++
+
+	return order, nil
+}
+
+// toOrders groups the joined rows by order, keeping the order of the rows.
+func toOrders(rows []sqliteOrderRow) []*domain.Order {
+	entities := make([]*domain.Order, 0, _defaultEntityCap)
+	byID := make(map[uuid.UUID]*domain.Order)
+
+	for _, row := range rows {
+		order, ok := byID[row.ID]
+		if !ok {
+			order = &domain.Order{
+				ID:              row.ID,
+				OrderSource:     shared.OrderSource(row.OrderSource),
+				LoyaltyMemberID: row.LoyaltyMemberID,
+				OrderStatus:     shared.Status(row.OrderStatus),
+				Location:        shared.Location(row.Location),
+				Version:         int32(row.Version),
+			}
+			byID[row.ID] = order
+			entities = append(entities, order)
+		}
+
+		order.LineItems = append(order.LineItems, &domain.LineItem{
+			ID:             row.LineItemID,
+			ItemType:       shared.ItemType(row.ItemType),
+			Name:           row.Name,
+			Price:          float32(row.Price),
+			ItemStatus:     shared.Status(row.ItemStatus),
+			IsBaristaOrder: row.IsBaristaOrder,
+			OrderID:        row.ID,
+		})
+	}
+
+	return entities
+}
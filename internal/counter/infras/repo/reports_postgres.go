@@ -0,0 +1,130 @@
+package repo
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/postgresql"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type reportRepo struct {
+	pg postgres.DBEngine
+}
+
+var _ reports.ReportRepo = (*reportRepo)(nil)
+
+var ReportRepositorySet = wire.NewSet(NewReportRepoFromConfig)
+
+// NewReportRepo reads the aggregated materialized views of the "order" schema.
+// They lag behind the orders until the next Refresh.
+func NewReportRepo(pg postgres.DBEngine) reports.ReportRepo {
+	return &reportRepo{pg: pg}
+}
+
+func (d *reportRepo) GetDailySales(ctx context.Context, r domain.ReportRange) ([]*domain.DailySales, error) {
+	querier := postgresql.New(d.pg.GetDB())
+
+	rows, err := querier.GetDailySales(ctx, postgresql.GetDailySalesParams{
+		FromDate: r.From,
+		ToDate:   r.Until(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetDailySales")
+	}
+
+	results := make([]*domain.DailySales, 0, len(rows))
+
+	for _, row := range rows {
+		revenue, err := strconv.ParseFloat(row.Revenue, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "strconv.ParseFloat[revenue]")
+		}
+
+		results = append(results, &domain.DailySales{
+			Date:     row.SalesDate,
+			Location: locationOf(row.Location),
+			Orders:   row.Orders,
+			Items:    row.Items,
+			Revenue:  revenue,
+		})
+	}
+
+	return results, nil
+}
+
+func (d *reportRepo) GetDailyItemSales(ctx context.Context, r domain.ReportRange) ([]*domain.DailyItemSales, error) {
+	querier := postgresql.New(d.pg.GetDB())
+
+	rows, err := querier.GetDailyItemSales(ctx, postgresql.GetDailyItemSalesParams{
+		FromDate: r.From,
+		ToDate:   r.Until(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetDailyItemSales")
+	}
+
+	results := make([]*domain.DailyItemSales, 0, len(rows))
+
+	for _, row := range rows {
+		revenue, err := strconv.ParseFloat(row.Revenue, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "strconv.ParseFloat[revenue]")
+		}
+
+		results = append(results, &domain.DailyItemSales{
+			Date:     row.SalesDate,
+			Location: locationOf(row.Location),
+			ItemType: shared.ItemType(row.ItemType),
+			ItemName: row.ItemName,
+			Items:    row.Items,
+			Revenue:  revenue,
+		})
+	}
+
+	return results, nil
+}
+
+func (d *reportRepo) GetPrepTimes(ctx context.Context, r domain.ReportRange) ([]*domain.PrepTime, error) {
+	querier := postgresql.New(d.pg.GetDB())
+
+	rows, err := querier.GetLineItemPrepTimes(ctx, postgresql.GetLineItemPrepTimesParams{
+		FromDate: r.From,
+		ToDate:   r.Until(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetLineItemPrepTimes")
+	}
+
+	results := make([]*domain.PrepTime, 0, len(rows))
+
+	for _, row := range rows {
+		results = append(results, &domain.PrepTime{
+			Date:         row.CompletedDate,
+			Location:     locationOf(row.Location),
+			ItemType:     shared.ItemType(row.ItemType),
+			ItemName:     row.ItemName,
+			Station:      domain.Station(row.Station),
+			Actor:        row.Actor,
+			PrepSeconds:  row.PrepSeconds,
+			TotalSeconds: row.TotalSeconds,
+		})
+	}
+
+	return results, nil
+}
+
+func (d *reportRepo) Refresh(ctx context.Context) error {
+	querier := postgresql.New(d.pg.GetDB())
+
+	if err := querier.RefreshReports(ctx); err != nil {
+		return errors.Wrap(err, "querier.RefreshReports")
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/sqlite"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+const _sqliteDateLayout = "2006-01-02"
+
+type sqliteReportRepo struct {
+	pg postgres.DBEngine
+}
+
+var _ reports.ReportRepo = (*sqliteReportRepo)(nil)
+
+// NewSQLiteReportRepo reads the report views of the SQLite migrations.
+// They are plain views, so they are always up to date and Refresh has nothing to do.
+func NewSQLiteReportRepo(pg postgres.DBEngine) reports.ReportRepo {
+	return &sqliteReportRepo{pg: pg}
+}
+
+func (d *sqliteReportRepo) GetDailySales(ctx context.Context, r domain.ReportRange) ([]*domain.DailySales, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	rows, err := querier.GetDailySales(ctx, sqlite.GetDailySalesParams{
+		SalesDate:   r.From.Format(_sqliteDateLayout),
+		SalesDate_2: r.Until().Format(_sqliteDateLayout),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetDailySales")
+	}
+
+	results := make([]*domain.DailySales, 0, len(rows))
+
+	for _, row := range rows {
+		var sales domain.DailySales
+
+		if err := scanSQLite(
+			row.SalesDate, &sales.Date,
+			row.Orders, &sales.Orders,
+			row.Items, &sales.Items,
+			row.Revenue, &sales.Revenue,
+		); err != nil {
+			return nil, err
+		}
+
+		sales.Location = shared.Location(row.Location)
+		results = append(results, &sales)
+	}
+
+	return results, nil
+}
+
+func (d *sqliteReportRepo) GetDailyItemSales(ctx context.Context, r domain.ReportRange) ([]*domain.DailyItemSales, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	rows, err := querier.GetDailyItemSales(ctx, sqlite.GetDailyItemSalesParams{
+		SalesDate:   r.From.Format(_sqliteDateLayout),
+		SalesDate_2: r.Until().Format(_sqliteDateLayout),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetDailyItemSales")
+	}
+
+	results := make([]*domain.DailyItemSales, 0, len(rows))
+
+	for _, row := range rows {
+		var sales domain.DailyItemSales
+
+		if err := scanSQLite(
+			row.SalesDate, &sales.Date,
+			row.ItemName, &sales.ItemName,
+			row.Items, &sales.Items,
+			row.Revenue, &sales.Revenue,
+		); err != nil {
+			return nil, err
+		}
+
+		sales.Location = shared.Location(row.Location)
+		sales.ItemType = shared.ItemType(row.ItemType)
+		results = append(results, &sales)
+	}
+
+	return results, nil
+}
+
+func (d *sqliteReportRepo) GetPrepTimes(ctx context.Context, r domain.ReportRange) ([]*domain.PrepTime, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	rows, err := querier.GetLineItemPrepTimes(ctx, sqlite.GetLineItemPrepTimesParams{
+		CompletedDate:   r.From.Format(_sqliteDateLayout),
+		CompletedDate_2: r.Until().Format(_sqliteDateLayout),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetLineItemPrepTimes")
+	}
+
+	results := make([]*domain.PrepTime, 0, len(rows))
+
+	for _, row := range rows {
+		prepTime := domain.PrepTime{
+			Location: shared.Location(row.Location),
+			ItemType: shared.ItemType(row.ItemType),
+			ItemName: row.ItemName,
+			Station:  domain.Station(row.Station),
+			Actor:    row.Actor,
+		}
+
+		if err := scanSQLite(
+			row.CompletedDate, &prepTime.Date,
+			row.PrepSeconds, &prepTime.PrepSeconds,
+			row.TotalSeconds, &prepTime.TotalSeconds,
+		); err != nil {
+			return nil, err
+		}
+
+		results = append(results, &prepTime)
+	}
+
+	return results, nil
+}
+
+func (d *sqliteReportRepo) Refresh(context.Context) error {
+	return nil
+}
+
+// scanSQLite converts the untyped columns of the report views, sqlc can't tell the type of an expression on SQLite.
+// The pairs are a value then where it goes, a *time.Time, *int64 or *float64.
+func scanSQLite(pairs ...interface{}) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s := fmt.Sprint(pairs[i])
+		if b, ok := pairs[i].([]byte); ok {
+			s = string(b)
+		}
+
+		var err error
+
+		switch dest := pairs[i+1].(type) {
+		case *time.Time:
+			*dest, err = time.Parse(_sqliteDateLayout, s)
+		case *int64:
+			*dest, err = strconv.ParseInt(s, 10, 64)
+		case *float64:
+			*dest, err = strconv.ParseFloat(s, 64)
+		case *string:
+			*dest = s
+		default:
+			err = errors.Errorf("unsupported destination %T", dest)
+		}
+
+		if err != nil {
+			return errors.Wrapf(err, "scan %v", pairs[i])
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/cmd/counter/config"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/reports"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"golang.org/x/exp/slog"
+)
+
+const _eventSourcedOrderStore = "eventsourced"
+
+var ErrUnsupportedOrderStore = errors.New("unsupported order store")
+
+var RepositorySet = wire.NewSet(NewOrderRepoFromConfig, NewTimelineRepoFromConfig)
+
+func NewOrderRepoFromConfig(cfg *config.Config, pg postgres.DBEngine, uow postgres.UnitOfWork) (orders.OrderRepo, error) {
+	sqlite := cfg.PG.Driver == postgres.DriverSQLite
+
+	if cfg.OrderStore.Kind == _eventSourcedOrderStore {
+		// the event store has no SQLite tables
+		if sqlite {
+			return nil, errors.Wrapf(ErrUnsupportedOrderStore, "%s on %s", cfg.OrderStore.Kind, cfg.PG.Driver)
+		}
+
+		slog.Info("using event-sourced order store", "snapshot_every", cfg.OrderStore.SnapshotEvery)
+
+		return NewEventSourcedOrderRepo(pg, uow, cfg.OrderStore.SnapshotEvery), nil
+	}
+
+	if sqlite {
+		return NewSQLiteOrderRepo(pg, uow), nil
+	}
+
+	return NewOrderRepo(pg, uow), nil
+}
+
+func NewTimelineRepoFromConfig(cfg *config.Config, pg postgres.DBEngine) orders.TimelineRepo {
+	if cfg.PG.Driver == postgres.DriverSQLite {
+		return NewSQLiteTimelineRepo(pg)
+	}
+
+	return NewTimelineRepo(pg)
+}
+
+func NewReportRepoFromConfig(cfg *config.Config, pg postgres.DBEngine) reports.ReportRepo {
+	if cfg.PG.Driver == postgres.DriverSQLite {
+		return NewSQLiteReportRepo(pg)
+	}
+
+	return NewReportRepo(pg)
+}
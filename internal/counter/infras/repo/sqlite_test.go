@@ -0,0 +1,117 @@
+package repo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/repo"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+func newSQLite(t *testing.T) postgres.DBEngine {
+	t.Helper()
+
+	file := postgres.DBConnString(filepath.Join(t.TempDir(), "counter.db"))
+
+	// closing the migrate closes its database, the repos get one of their own
+	migrateDB, err := postgres.NewSQLiteDB(file, postgres.Name("counter-migrate-test"))
+	require.NoError(t, err)
+
+	m, err := migrations.NewSQLite(migrations.Counter, migrateDB.GetDB())
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	_, err = m.Close()
+	require.NoError(t, err)
+
+	pg, err := postgres.NewSQLiteDB(file, postgres.Name("counter-test"))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	return pg
+}
+
+func TestSQLiteReposStoreTheOrderItsTimelineAndReportIt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pg := newSQLite(t)
+	uow := postgres.NewUnitOfWork(pg)
+
+	orderRepo := repo.NewSQLiteOrderRepo(pg, uow)
+	timelineRepo := repo.NewSQLiteTimelineRepo(pg)
+	reportRepo := repo.NewSQLiteReportRepo(pg)
+
+	order := &domain.Order{
+		ID:              uuid.New(),
+		OrderSource:     shared.OrderSourceCounter,
+		LoyaltyMemberID: uuid.New(),
+		OrderStatus:     shared.StatusInProcess,
+		Location:        shared.LocationAtlanta,
+	}
+	item := &domain.LineItem{
+		ID:             uuid.New(),
+		ItemType:       shared.ItemTypeCappuccino,
+		Name:           "CAPPUCCINO",
+		Price:          4.5,
+		ItemStatus:     shared.StatusInProcess,
+		IsBaristaOrder: true,
+	}
+	order.LineItems = append(order.LineItems, item)
+
+	require.NoError(t, orderRepo.Create(ctx, order))
+
+	placed := time.Now().UTC()
+	require.NoError(t, timelineRepo.Append(ctx,
+		domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStagePlaced, domain.StationCounter, "", placed),
+		domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStageStarted, domain.StationBarista, "teesee", placed.Add(time.Second)),
+		domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStageCompleted, domain.StationBarista, "teesee", placed.Add(3*time.Second)),
+		// a redelivered event records nothing new
+		domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStagePlaced, domain.StationCounter, "", placed),
+	))
+
+	item.ItemStatus = shared.StatusFulfilled
+	order.OrderStatus = shared.StatusFulfilled
+	_, err := orderRepo.Update(ctx, order)
+	require.NoError(t, err)
+
+	// the version moved on, so the stale copy conflicts
+	order.Version = 0
+	_, err = orderRepo.Update(ctx, order)
+	assert.ErrorIs(t, err, domain.ErrOrderVersionConflict)
+
+	got, err := orderRepo.GetByID(ctx, order.ID)
+	require.NoError(t, err)
+	require.Len(t, got.LineItems, 1)
+	assert.Equal(t, shared.StatusFulfilled, got.OrderStatus)
+	assert.Equal(t, int32(1), got.Version)
+	assert.Equal(t, shared.StatusFulfilled, got.LineItems[0].ItemStatus)
+	assert.InDelta(t, 4.5, got.LineItems[0].Price, 0.001)
+
+	timeline, err := timelineRepo.GetByOrderID(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Len(t, timeline, 3)
+
+	today, err := domain.NewReportRange(placed.Format("2006-01-02"), placed.Format("2006-01-02"))
+	require.NoError(t, err)
+
+	sales, err := reportRepo.GetDailySales(ctx, today)
+	require.NoError(t, err)
+	require.Len(t, sales, 1)
+	assert.Equal(t, int64(1), sales[0].Orders)
+	assert.InDelta(t, 4.5, sales[0].Revenue, 0.001)
+
+	prepTimes, err := reportRepo.GetPrepTimes(ctx, today)
+	require.NoError(t, err)
+	require.Len(t, prepTimes, 1)
+	assert.InDelta(t, 2, prepTimes[0].PrepSeconds, 0.01)
+	assert.InDelta(t, 3, prepTimes[0].TotalSeconds, 0.01)
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/sqlite"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type sqliteTimelineRepo struct {
+	pg postgres.DBEngine
+}
+
+var _ orders.TimelineRepo = (*sqliteTimelineRepo)(nil)
+
+func NewSQLiteTimelineRepo(pg postgres.DBEngine) orders.TimelineRepo {
+	return &sqliteTimelineRepo{pg: pg}
+}
+
+func (d *sqliteTimelineRepo) Append(ctx context.Context, entries ...*domain.TimelineEntry) error {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	for _, entry := range entries {
+		err := querier.InsertTimelineEntry(ctx, sqlite.InsertTimelineEntryParams{
+			OrderID:    entry.OrderID,
+			LineItemID: entry.LineItemID,
+			Stage:      string(entry.Stage),
+			Station:    string(entry.Station),
+			Actor:      entry.Actor,
+			OccurredAt: entry.OccurredAt,
+		})
+		if err != nil {
+			return errors.Wrap(err, "querier.InsertTimelineEntry")
+		}
+	}
+
+	return nil
+}
+
+func (d *sqliteTimelineRepo) GetByOrderID(ctx context.Context, id uuid.UUID) ([]*domain.TimelineEntry, error) {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	rows, err := querier.GetOrderTimeline(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "querier.GetOrderTimeline")
+	}
+
+	results := make([]*domain.TimelineEntry, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, &domain.TimelineEntry{
+			OrderID:    row.OrderID,
+			LineItemID: row.LineItemID,
+			ItemType:   shared.ItemType(row.ItemType),
+			Name:       row.Name,
+			Stage:      domain.TimelineStage(row.Stage),
+			Station:    domain.Station(row.Station),
+			Actor:      row.Actor,
+			OccurredAt: row.OccurredAt,
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,74 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+
+package sqlite
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DailyItemSale struct {
+	SalesDate interface{}     `json:"sales_date"`
+	Location  int64           `json:"location"`
+	ItemType  int64           `json:"item_type"`
+	ItemName  interface{}     `json:"item_name"`
+	Items     int64           `json:"items"`
+	Revenue   sql.NullFloat64 `json:"revenue"`
+}
+
+type DailySale struct {
+	SalesDate interface{}     `json:"sales_date"`
+	Location  int64           `json:"location"`
+	Orders    int64           `json:"orders"`
+	Items     int64           `json:"items"`
+	Revenue   sql.NullFloat64 `json:"revenue"`
+}
+
+type LineItem struct {
+	ID             uuid.UUID     `json:"id"`
+	ItemType       int64         `json:"item_type"`
+	Name           string        `json:"name"`
+	Price          float64       `json:"price"`
+	ItemStatus     int64         `json:"item_status"`
+	IsBaristaOrder bool          `json:"is_barista_order"`
+	OrderID        uuid.NullUUID `json:"order_id"`
+	Created        time.Time     `json:"created"`
+	Updated        sql.NullTime  `json:"updated"`
+}
+
+type LineItemPrepTime struct {
+	LineItemID    uuid.UUID   `json:"line_item_id"`
+	CompletedDate interface{} `json:"completed_date"`
+	Location      int64       `json:"location"`
+	ItemType      int64       `json:"item_type"`
+	ItemName      string      `json:"item_name"`
+	Station       string      `json:"station"`
+	Actor         string      `json:"actor"`
+	PrepSeconds   int64       `json:"prep_seconds"`
+	TotalSeconds  int64       `json:"total_seconds"`
+}
+
+type LineItemTimeline struct {
+	ID         int64     `json:"id"`
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Created    time.Time `json:"created"`
+}
+
+type Order struct {
+	ID              uuid.UUID    `json:"id"`
+	OrderSource     int64        `json:"order_source"`
+	LoyaltyMemberID uuid.UUID    `json:"loyalty_member_id"`
+	OrderStatus     int64        `json:"order_status"`
+	Location        int64        `json:"location"`
+	Version         int64        `json:"version"`
+	Updated         sql.NullTime `json:"updated"`
+}
@@ -0,0 +1,561 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: query.sql
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const createOrder = `-- name: CreateOrder :exec
+
+INSERT INTO
+    orders (
+        id,
+        order_source,
+        loyalty_member_id,
+        order_status,
+        location,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?)
+`
+
+type CreateOrderParams struct {
+	ID              uuid.UUID    `json:"id"`
+	OrderSource     int64        `json:"order_source"`
+	LoyaltyMemberID uuid.UUID    `json:"loyalty_member_id"`
+	OrderStatus     int64        `json:"order_status"`
+	Location        int64        `json:"location"`
+	Updated         sql.NullTime `json:"updated"`
+}
+
+func (q *Queries) CreateOrder(ctx context.Context, arg CreateOrderParams) error {
+	_, err := q.db.ExecContext(ctx, createOrder,
+		arg.ID,
+		arg.OrderSource,
+		arg.LoyaltyMemberID,
+		arg.OrderStatus,
+		arg.Location,
+		arg.Updated,
+	)
+	return err
+}
+
+const getAll = `-- name: GetAll :many
+
+SELECT
+    o.id,
+    order_source,
+    loyalty_member_id,
+    order_status,
+    location,
+    version,
+    l.id AS line_item_id,
+    item_type,
+    name,
+    price,
+    item_status,
+    is_barista_order
+FROM orders o
+    LEFT JOIN line_items l ON o.id = l.order_id
+`
+
+type GetAllRow struct {
+	ID              uuid.UUID `json:"id"`
+	OrderSource     int64     `json:"order_source"`
+	LoyaltyMemberID uuid.UUID `json:"loyalty_member_id"`
+	OrderStatus     int64     `json:"order_status"`
+	Location        int64     `json:"location"`
+	Version         int64     `json:"version"`
+	LineItemID      uuid.UUID `json:"line_item_id"`
+	ItemType        int64     `json:"item_type"`
+	Name            string    `json:"name"`
+	Price           float64   `json:"price"`
+	ItemStatus      int64     `json:"item_status"`
+	IsBaristaOrder  bool      `json:"is_barista_order"`
+}
+
+func (q *Queries) GetAll(ctx context.Context) ([]GetAllRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAllRow
+	for rows.Next() {
+		var i GetAllRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderSource,
+			&i.LoyaltyMemberID,
+			&i.OrderStatus,
+			&i.Location,
+			&i.Version,
+			&i.LineItemID,
+			&i.ItemType,
+			&i.Name,
+			&i.Price,
+			&i.ItemStatus,
+			&i.IsBaristaOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getByID = `-- name: GetByID :many
+
+SELECT
+    o.id,
+    order_source,
+    loyalty_member_id,
+    order_status,
+    location,
+    version,
+    l.id AS line_item_id,
+    item_type,
+    name,
+    price,
+    item_status,
+    is_barista_order
+FROM orders o
+    LEFT JOIN line_items l ON o.id = l.order_id
+WHERE o.id = ?
+`
+
+type GetByIDRow struct {
+	ID              uuid.UUID `json:"id"`
+	OrderSource     int64     `json:"order_source"`
+	LoyaltyMemberID uuid.UUID `json:"loyalty_member_id"`
+	OrderStatus     int64     `json:"order_status"`
+	Location        int64     `json:"location"`
+	Version         int64     `json:"version"`
+	LineItemID      uuid.UUID `json:"line_item_id"`
+	ItemType        int64     `json:"item_type"`
+	Name            string    `json:"name"`
+	Price           float64   `json:"price"`
+	ItemStatus      int64     `json:"item_status"`
+	IsBaristaOrder  bool      `json:"is_barista_order"`
+}
+
+func (q *Queries) GetByID(ctx context.Context, id uuid.UUID) ([]GetByIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getByID, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetByIDRow
+	for rows.Next() {
+		var i GetByIDRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrderSource,
+			&i.LoyaltyMemberID,
+			&i.OrderStatus,
+			&i.Location,
+			&i.Version,
+			&i.LineItemID,
+			&i.ItemType,
+			&i.Name,
+			&i.Price,
+			&i.ItemStatus,
+			&i.IsBaristaOrder,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDailyItemSales = `-- name: GetDailyItemSales :many
+
+SELECT
+    CAST(sales_date AS TEXT) AS sales_date,
+    location,
+    item_type,
+    CAST(item_name AS TEXT) AS item_name,
+    CAST(items AS INTEGER) AS items,
+    CAST(revenue AS REAL) AS revenue
+FROM daily_item_sales
+WHERE sales_date >= ? AND sales_date < ?
+ORDER BY sales_date, location, item_type
+`
+
+type GetDailyItemSalesParams struct {
+	SalesDate   interface{} `json:"sales_date"`
+	SalesDate_2 interface{} `json:"sales_date_2"`
+}
+
+type GetDailyItemSalesRow struct {
+	SalesDate interface{} `json:"sales_date"`
+	Location  int64       `json:"location"`
+	ItemType  int64       `json:"item_type"`
+	ItemName  interface{} `json:"item_name"`
+	Items     interface{} `json:"items"`
+	Revenue   interface{} `json:"revenue"`
+}
+
+func (q *Queries) GetDailyItemSales(ctx context.Context, arg GetDailyItemSalesParams) ([]GetDailyItemSalesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDailyItemSales, arg.SalesDate, arg.SalesDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDailyItemSalesRow
+	for rows.Next() {
+		var i GetDailyItemSalesRow
+		if err := rows.Scan(
+			&i.SalesDate,
+			&i.Location,
+			&i.ItemType,
+			&i.ItemName,
+			&i.Items,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getDailySales = `-- name: GetDailySales :many
+
+
+SELECT
+    CAST(sales_date AS TEXT) AS sales_date,
+    location,
+    CAST(orders AS INTEGER) AS orders,
+    CAST(items AS INTEGER) AS items,
+    CAST(revenue AS REAL) AS revenue
+FROM daily_sales
+WHERE sales_date >= ? AND sales_date < ?
+ORDER BY sales_date, location
+`
+
+type GetDailySalesParams struct {
+	SalesDate   interface{} `json:"sales_date"`
+	SalesDate_2 interface{} `json:"sales_date_2"`
+}
+
+type GetDailySalesRow struct {
+	SalesDate interface{} `json:"sales_date"`
+	Location  int64       `json:"location"`
+	Orders    interface{} `json:"orders"`
+	Items     interface{} `json:"items"`
+	Revenue   interface{} `json:"revenue"`
+}
+
+// the reports take the first day and the day after the range, both formatted 2006-01-02
+func (q *Queries) GetDailySales(ctx context.Context, arg GetDailySalesParams) ([]GetDailySalesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDailySales, arg.SalesDate, arg.SalesDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDailySalesRow
+	for rows.Next() {
+		var i GetDailySalesRow
+		if err := rows.Scan(
+			&i.SalesDate,
+			&i.Location,
+			&i.Orders,
+			&i.Items,
+			&i.Revenue,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLineItemPrepTimes = `-- name: GetLineItemPrepTimes :many
+
+SELECT
+    line_item_id,
+    CAST(completed_date AS TEXT) AS completed_date,
+    location,
+    item_type,
+    item_name,
+    station,
+    actor,
+    CAST(prep_seconds AS REAL) AS prep_seconds,
+    CAST(total_seconds AS REAL) AS total_seconds
+FROM line_item_prep_times
+WHERE completed_date >= ? AND completed_date < ?
+ORDER BY completed_date
+`
+
+type GetLineItemPrepTimesParams struct {
+	CompletedDate   interface{} `json:"completed_date"`
+	CompletedDate_2 interface{} `json:"completed_date_2"`
+}
+
+type GetLineItemPrepTimesRow struct {
+	LineItemID    uuid.UUID   `json:"line_item_id"`
+	CompletedDate interface{} `json:"completed_date"`
+	Location      int64       `json:"location"`
+	ItemType      int64       `json:"item_type"`
+	ItemName      string      `json:"item_name"`
+	Station       string      `json:"station"`
+	Actor         string      `json:"actor"`
+	PrepSeconds   interface{} `json:"prep_seconds"`
+	TotalSeconds  interface{} `json:"total_seconds"`
+}
+
+func (q *Queries) GetLineItemPrepTimes(ctx context.Context, arg GetLineItemPrepTimesParams) ([]GetLineItemPrepTimesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getLineItemPrepTimes, arg.CompletedDate, arg.CompletedDate_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetLineItemPrepTimesRow
+	for rows.Next() {
+		var i GetLineItemPrepTimesRow
+		if err := rows.Scan(
+			&i.LineItemID,
+			&i.CompletedDate,
+			&i.Location,
+			&i.ItemType,
+			&i.ItemName,
+			&i.Station,
+			&i.Actor,
+			&i.PrepSeconds,
+			&i.TotalSeconds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrderTimeline = `-- name: GetOrderTimeline :many
+
+SELECT
+    t.order_id,
+    t.line_item_id,
+    l.item_type,
+    l.name,
+    t.stage,
+    t.station,
+    t.actor,
+    t.occurred_at
+FROM line_item_timeline t
+    INNER JOIN line_items l ON l.id = t.line_item_id
+WHERE t.order_id = ?
+ORDER BY t.occurred_at, t.id
+`
+
+type GetOrderTimelineRow struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	ItemType   int64     `json:"item_type"`
+	Name       string    `json:"name"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (q *Queries) GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]GetOrderTimelineRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrderTimeline, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrderTimelineRow
+	for rows.Next() {
+		var i GetOrderTimelineRow
+		if err := rows.Scan(
+			&i.OrderID,
+			&i.LineItemID,
+			&i.ItemType,
+			&i.Name,
+			&i.Stage,
+			&i.Station,
+			&i.Actor,
+			&i.OccurredAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertItemLine = `-- name: InsertItemLine :exec
+
+INSERT INTO
+    line_items (
+        id,
+        item_type,
+        name,
+        price,
+        item_status,
+        is_barista_order,
+        order_id,
+        created,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+`
+
+type InsertItemLineParams struct {
+	ID             uuid.UUID     `json:"id"`
+	ItemType       int64         `json:"item_type"`
+	Name           string        `json:"name"`
+	Price          float64       `json:"price"`
+	ItemStatus     int64         `json:"item_status"`
+	IsBaristaOrder bool          `json:"is_barista_order"`
+	OrderID        uuid.NullUUID `json:"order_id"`
+	Created        time.Time     `json:"created"`
+	Updated        sql.NullTime  `json:"updated"`
+}
+
+func (q *Queries) InsertItemLine(ctx context.Context, arg InsertItemLineParams) error {
+	_, err := q.db.ExecContext(ctx, insertItemLine,
+		arg.ID,
+		arg.ItemType,
+		arg.Name,
+		arg.Price,
+		arg.ItemStatus,
+		arg.IsBaristaOrder,
+		arg.OrderID,
+		arg.Created,
+		arg.Updated,
+	)
+	return err
+}
+
+const insertTimelineEntry = `-- name: InsertTimelineEntry :exec
+
+INSERT INTO
+    line_item_timeline (
+        order_id,
+        line_item_id,
+        stage,
+        station,
+        actor,
+        occurred_at
+    )
+VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (line_item_id, stage) DO NOTHING
+`
+
+type InsertTimelineEntryParams struct {
+	OrderID    uuid.UUID `json:"order_id"`
+	LineItemID uuid.UUID `json:"line_item_id"`
+	Stage      string    `json:"stage"`
+	Station    string    `json:"station"`
+	Actor      string    `json:"actor"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (q *Queries) InsertTimelineEntry(ctx context.Context, arg InsertTimelineEntryParams) error {
+	_, err := q.db.ExecContext(ctx, insertTimelineEntry,
+		arg.OrderID,
+		arg.LineItemID,
+		arg.Stage,
+		arg.Station,
+		arg.Actor,
+		arg.OccurredAt,
+	)
+	return err
+}
+
+const updateItemLine = `-- name: UpdateItemLine :exec
+
+UPDATE line_items
+SET
+    item_status = ?,
+    updated = ?
+WHERE id = ?
+`
+
+type UpdateItemLineParams struct {
+	ItemStatus int64        `json:"item_status"`
+	Updated    sql.NullTime `json:"updated"`
+	ID         uuid.UUID    `json:"id"`
+}
+
+func (q *Queries) UpdateItemLine(ctx context.Context, arg UpdateItemLineParams) error {
+	_, err := q.db.ExecContext(ctx, updateItemLine, arg.ItemStatus, arg.Updated, arg.ID)
+	return err
+}
+
+const updateOrder = `-- name: UpdateOrder :execrows
+
+UPDATE orders
+SET
+    order_status = ?,
+    updated = ?,
+    version = version + 1
+WHERE id = ? AND version = ?
+`
+
+type UpdateOrderParams struct {
+	OrderStatus int64        `json:"order_status"`
+	Updated     sql.NullTime `json:"updated"`
+	ID          uuid.UUID    `json:"id"`
+	Version     int64        `json:"version"`
+}
+
+func (q *Queries) UpdateOrder(ctx context.Context, arg UpdateOrderParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateOrder,
+		arg.OrderStatus,
+		arg.Updated,
+		arg.ID,
+		arg.Version,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
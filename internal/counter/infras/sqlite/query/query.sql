@@ -0,0 +1,154 @@
+-- name: GetAll :many
+
+SELECT
+    o.id,
+    order_source,
+    loyalty_member_id,
+    order_status,
+    location,
+    version,
+    l.id AS line_item_id,
+    item_type,
+    name,
+    price,
+    item_status,
+    is_barista_order
+FROM orders o
+    LEFT JOIN line_items l ON o.id = l.order_id;
+
+-- name: GetByID :many
+
+SELECT
+    o.id,
+    order_source,
+    loyalty_member_id,
+    order_status,
+    location,
+    version,
+    l.id AS line_item_id,
+    item_type,
+    name,
+    price,
+    item_status,
+    is_barista_order
+FROM orders o
+    LEFT JOIN line_items l ON o.id = l.order_id
+WHERE o.id = ?;
+
+-- name: CreateOrder :exec
+
+INSERT INTO
+    orders (
+        id,
+        order_source,
+        loyalty_member_id,
+        order_status,
+        location,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: InsertItemLine :exec
+
+INSERT INTO
+    line_items (
+        id,
+        item_type,
+        name,
+        price,
+        item_status,
+        is_barista_order,
+        order_id,
+        created,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: UpdateOrder :execrows
+
+UPDATE orders
+SET
+    order_status = ?,
+    updated = ?,
+    version = version + 1
+WHERE id = ? AND version = ?;
+
+-- name: UpdateItemLine :exec
+
+UPDATE line_items
+SET
+    item_status = ?,
+    updated = ?
+WHERE id = ?;
+
+-- name: InsertTimelineEntry :exec
+
+INSERT INTO
+    line_item_timeline (
+        order_id,
+        line_item_id,
+        stage,
+        station,
+        actor,
+        occurred_at
+    )
+VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT (line_item_id, stage) DO NOTHING;
+
+-- name: GetOrderTimeline :many
+
+SELECT
+    t.order_id,
+    t.line_item_id,
+    l.item_type,
+    l.name,
+    t.stage,
+    t.station,
+    t.actor,
+    t.occurred_at
+FROM line_item_timeline t
+    INNER JOIN line_items l ON l.id = t.line_item_id
+WHERE t.order_id = ?
+ORDER BY t.occurred_at, t.id;
+
+-- the reports take the first day and the day after the range, both formatted 2006-01-02
+
+-- name: GetDailySales :many
+
+SELECT
+    CAST(sales_date AS TEXT) AS sales_date,
+    location,
+    CAST(orders AS INTEGER) AS orders,
+    CAST(items AS INTEGER) AS items,
+    CAST(revenue AS REAL) AS revenue
+FROM daily_sales
+WHERE sales_date >= ? AND sales_date < ?
+ORDER BY sales_date, location;
+
+-- name: GetDailyItemSales :many
+
+SELECT
+    CAST(sales_date AS TEXT) AS sales_date,
+    location,
+    item_type,
+    CAST(item_name AS TEXT) AS item_name,
+    CAST(items AS INTEGER) AS items,
+    CAST(revenue AS REAL) AS revenue
+FROM daily_item_sales
+WHERE sales_date >= ? AND sales_date < ?
+ORDER BY sales_date, location, item_type;
+
+-- name: GetLineItemPrepTimes :many
+
+SELECT
+    line_item_id,
+    CAST(completed_date AS TEXT) AS completed_date,
+    location,
+    item_type,
+    item_name,
+    station,
+    actor,
+    CAST(prep_seconds AS REAL) AS prep_seconds,
+    CAST(total_seconds AS REAL) AS total_seconds
+FROM line_item_prep_times
+WHERE completed_date >= ? AND completed_date < ?
+ORDER BY completed_date;
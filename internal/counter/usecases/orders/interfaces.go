@@ -2,10 +2,11 @@ package orders
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 )
 
 type (
@@ -16,18 +17,30 @@ type (
 		Update(context.Context, *domain.Order) (*domain.Order, error)
 	}
 
+	TimelineRepo interface {
+		Append(context.Context, ...*domain.TimelineEntry) error
+		GetByOrderID(context.Context, uuid.UUID) ([]*domain.TimelineEntry, error)
+	}
+
+	// OrderMetrics records the business metrics of the orders.
+	OrderMetrics interface {
+		OrderPlaced(*domain.Order)
+		OrderFulfilled(order *domain.Order, placedAt time.Time)
+	}
+
 	BaristaEventPublisher interface {
-		Configure(...publisher.Option)
+		Configure(...messaging.Option)
 		Publish(context.Context, []byte, string) error
 	}
 
 	KitchenEventPublisher interface {
-		Configure(...publisher.Option)
+		Configure(...messaging.Option)
 		Publish(context.Context, []byte, string) error
 	}
 
 	UseCase interface {
 		GetListOrderFulfillment(context.Context) ([]*domain.Order, error)
-		PlaceOrder(context.Context, *domain.PlaceOrderModel) error
+		PlaceOrder(context.Context, *domain.PlaceOrderModel) (uuid.UUID, error)
+		GetOrderTimeline(context.Context, uuid.UUID) ([]*domain.TimelineEntry, error)
 	}
 )
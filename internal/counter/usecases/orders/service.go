@@ -4,18 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
 	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"golang.org/x/exp/slog"
 )
 
 type usecase struct {
+	uow              postgres.UnitOfWork
 	orderRepo        OrderRepo
+	timelineRepo     TimelineRepo
 	productDomainSvc domain.ProductDomainService
 	baristaEventPub  BaristaEventPublisher
 	kitchenEventPub  KitchenEventPublisher
+	metrics          OrderMetrics
 }
 
 var _ UseCase = (*usecase)(nil)
@@ -23,16 +30,22 @@ var _ UseCase = (*usecase)(nil)
 var UseCaseSet = wire.NewSet(NewUseCase)
 
 func NewUseCase(
+	uow postgres.UnitOfWork,
 	orderRepo OrderRepo,
+	timelineRepo TimelineRepo,
 	productDomainSvc domain.ProductDomainService,
 	baristaEventPub BaristaEventPublisher,
 	kitchenEventPub KitchenEventPublisher,
+	metrics OrderMetrics,
 ) UseCase {
 	return &usecase{
+		uow:              uow,
 		orderRepo:        orderRepo,
+		timelineRepo:     timelineRepo,
 		productDomainSvc: productDomainSvc,
 		baristaEventPub:  baristaEventPub,
 		kitchenEventPub:  kitchenEventPub,
+		metrics:          metrics,
 	}
 }
 
@@ -45,25 +58,33 @@ func (uc *usecase) GetListOrderFulfillment(ctx context.Context) ([]*domain.Order
 	return entities, nil
 }
 
-func (uc *usecase) PlaceOrder(ctx context.Context, model *domain.PlaceOrderModel) error {
+func (uc *usecase) PlaceOrder(ctx context.Context, model *domain.PlaceOrderModel) (uuid.UUID, error) {
 	order, err := domain.CreateOrderFrom(ctx, model, uc.productDomainSvc)
 	if err != nil {
-		return errors.Wrap(err, "domain.CreateOrderFrom")
+		return uuid.Nil, errors.Wrap(err, "domain.CreateOrderFrom")
 	}
 
-	err = uc.orderRepo.Create(ctx, order)
+	err = uc.uow.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.orderRepo.Create(ctx, order); err != nil {
+			return errors.Wrap(err, "orderRepo.Create")
+		}
+
+		return uc.recordPlaced(ctx, order)
+	})
 	if err != nil {
-		return errors.Wrap(err, "orderRepo.Create")
+		return uuid.Nil, err
 	}
 
 	slog.Debug("order created", "order", *order)
 
+	uc.metrics.OrderPlaced(order)
+
 	// todo: it might cause dual-write problem, but we accept it temporary
 	for _, event := range order.DomainEvents() {
 		if event.Identity() == "BaristaOrdered" {
 			eventBytes, err := json.Marshal(event)
 			if err != nil {
-				return errors.Wrap(err, "json.Marshal[event]")
+				return uuid.Nil, errors.Wrap(err, "json.Marshal[event]")
 			}
 
 			uc.baristaEventPub.Publish(ctx, eventBytes, "text/plain")
@@ -72,12 +93,50 @@ func (uc *usecase) PlaceOrder(ctx context.Context, model *domain.PlaceOrderModel
 		if event.Identity() == "KitchenOrdered" {
 			eventBytes, err := json.Marshal(event)
 			if err != nil {
-				return errors.Wrap(err, "json.Marshal[event]")
+				return uuid.Nil, errors.Wrap(err, "json.Marshal[event]")
 			}
 
 			uc.kitchenEventPub.Publish(ctx, eventBytes, "text/plain")
 		}
 	}
 
+	return order.ID, nil
+}
+
+func (uc *usecase) GetOrderTimeline(ctx context.Context, orderID uuid.UUID) ([]*domain.TimelineEntry, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, errors.Wrap(err, "orderRepo.GetByID")
+	}
+
+	if order == nil {
+		return nil, errors.Wrapf(domain.ErrOrderNotFound, "order %s", orderID)
+	}
+
+	// the orders placed before the timeline was recorded have none
+	entries, err := uc.timelineRepo.GetByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, errors.Wrap(err, "timelineRepo.GetByOrderID")
+	}
+
+	return entries, nil
+}
+
+// recordPlaced writes the first two stages of every line item, in the transaction that creates the order.
+func (uc *usecase) recordPlaced(ctx context.Context, order *domain.Order) error {
+	now := time.Now()
+	entries := make([]*domain.TimelineEntry, 0, len(order.LineItems)*2)
+
+	for _, item := range order.LineItems {
+		entries = append(entries,
+			domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStagePlaced, domain.StationCounter, "", now),
+			domain.NewTimelineEntry(order.ID, item.ID, domain.TimelineStageSentToStation, domain.StationOf(item), "", now),
+		)
+	}
+
+	if err := uc.timelineRepo.Append(ctx, entries...); err != nil {
+		return errors.Wrap(err, "timelineRepo.Append")
+	}
+
 	return nil
 }
@@ -0,0 +1,178 @@
+package orders_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+	"github.com/thangchung/go-coffeeshop/internal/counter/infras/repo"
+	"github.com/thangchung/go-coffeeshop/internal/counter/usecases/orders"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type fakeProductSvc struct{}
+
+func (fakeProductSvc) LookupItems(
+	_ context.Context,
+	_ shared.Location,
+	itemTypes []shared.ItemType,
+	_ time.Time,
+) ([]*domain.ItemModel, []*domain.MissingItem, error) {
+	items := make([]*domain.ItemModel, 0, len(itemTypes))
+	for _, itemType := range itemTypes {
+		items = append(items, &domain.ItemModel{ItemType: itemType, Name: itemType.String(), Price: 3})
+	}
+
+	return items, nil, nil
+}
+
+type nopPublisher struct{}
+
+func (nopPublisher) Configure(...messaging.Option) {}
+
+func (nopPublisher) Publish(context.Context, []byte, string) error {
+	return nil
+}
+
+type nopOrderMetrics struct{}
+
+func (nopOrderMetrics) OrderPlaced(*domain.Order) {}
+
+func (nopOrderMetrics) OrderFulfilled(*domain.Order, time.Time) {}
+
+// failingTimelineRepo stands for a timeline table that can't be written.
+type failingTimelineRepo struct{}
+
+func (failingTimelineRepo) Append(context.Context, ...*domain.TimelineEntry) error {
+	return errors.New("timeline is down")
+}
+
+func (failingTimelineRepo) GetByOrderID(context.Context, uuid.UUID) ([]*domain.TimelineEntry, error) {
+	return nil, nil
+}
+
+func newSQLite(t *testing.T) postgres.DBEngine {
+	t.Helper()
+
+	file := postgres.DBConnString(filepath.Join(t.TempDir(), "counter.db"))
+
+	migrateDB, err := postgres.NewSQLiteDB(file, postgres.Name("counter-migrate-test"))
+	require.NoError(t, err)
+
+	m, err := migrations.NewSQLite(migrations.Counter, migrateDB.GetDB())
+	require.NoError(t, err)
+	require.NoError(t, m.Up())
+
+	_, err = m.Close()
+	require.NoError(t, err)
+
+	pg, err := postgres.NewSQLiteDB(file, postgres.Name("counter-test"))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	return pg
+}
+
+func TestGetOrderTimeline(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pg := newSQLite(t)
+	uow := postgres.NewUnitOfWork(pg)
+	orderRepo := repo.NewSQLiteOrderRepo(pg, uow)
+	timelineRepo := repo.NewSQLiteTimelineRepo(pg)
+	uc := orders.NewUseCase(uow, orderRepo, timelineRepo, fakeProductSvc{}, nopPublisher{}, nopPublisher{}, nopOrderMetrics{})
+
+	orderID, err := uc.PlaceOrder(ctx, &domain.PlaceOrderModel{
+		OrderSource:     shared.OrderSourceCounter,
+		Location:        shared.LocationAtlanta,
+		LoyaltyMemberID: uuid.New(),
+		BaristaItems:    []*domain.OrderItemModel{{ItemType: shared.ItemTypeLatte}},
+	})
+	require.NoError(t, err)
+
+	order, err := orderRepo.GetByID(ctx, orderID)
+	require.NoError(t, err)
+	require.Len(t, order.LineItems, 1)
+
+	latte := order.LineItems[0].ID
+	timeIn := time.Now().UTC().Add(time.Minute)
+
+	// the station reports are appended out of order, the timeline is in the order they happened
+	require.NoError(t, timelineRepo.Append(ctx,
+		domain.NewTimelineEntry(orderID, latte, domain.TimelineStageCompleted, domain.StationBarista, "teesee", timeIn.Add(time.Minute)),
+		domain.NewTimelineEntry(orderID, latte, domain.TimelineStageStarted, domain.StationBarista, "teesee", timeIn),
+	))
+
+	entries, err := uc.GetOrderTimeline(ctx, orderID)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	for i, expected := range []struct {
+		stage   domain.TimelineStage
+		station domain.Station
+	}{
+		{domain.TimelineStagePlaced, domain.StationCounter},
+		{domain.TimelineStageSentToStation, domain.StationBarista},
+		{domain.TimelineStageStarted, domain.StationBarista},
+		{domain.TimelineStageCompleted, domain.StationBarista},
+	} {
+		assert.Equal(t, expected.stage, entries[i].Stage)
+		assert.Equal(t, expected.station, entries[i].Station)
+		assert.Equal(t, "LATTE", entries[i].Name)
+	}
+
+	_, err = uc.GetOrderTimeline(ctx, uuid.New())
+	assert.ErrorIs(t, err, domain.ErrOrderNotFound)
+}
+
+func TestGetOrderTimelineOfAnOrderWithoutOne(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pg := newSQLite(t)
+	uow := postgres.NewUnitOfWork(pg)
+	orderRepo := repo.NewSQLiteOrderRepo(pg, uow)
+	uc := orders.NewUseCase(uow, orderRepo, repo.NewSQLiteTimelineRepo(pg), fakeProductSvc{}, nopPublisher{}, nopPublisher{}, nopOrderMetrics{})
+
+	// stored without the timeline, like the orders placed before it was recorded
+	order := domain.NewOrder(shared.OrderSourceCounter, uuid.New(), shared.StatusInProcess, shared.LocationAtlanta)
+	order.LineItems = []*domain.LineItem{domain.NewLineItem(shared.ItemTypeLatte, "LATTE", 4.5, shared.StatusInProcess, true)}
+	require.NoError(t, orderRepo.Create(ctx, order))
+
+	entries, err := uc.GetOrderTimeline(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPlaceOrderRollsBackWithoutTimeline(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	pg := newSQLite(t)
+	uow := postgres.NewUnitOfWork(pg)
+	orderRepo := repo.NewSQLiteOrderRepo(pg, uow)
+	uc := orders.NewUseCase(uow, orderRepo, failingTimelineRepo{}, fakeProductSvc{}, nopPublisher{}, nopPublisher{}, nopOrderMetrics{})
+
+	_, err := uc.PlaceOrder(ctx, &domain.PlaceOrderModel{
+		OrderSource:     shared.OrderSourceCounter,
+		Location:        shared.LocationAtlanta,
+		LoyaltyMemberID: uuid.New(),
+		BaristaItems:    []*domain.OrderItemModel{{ItemType: shared.ItemTypeLatte}},
+	})
+	require.Error(t, err)
+
+	// the order is created in the same transaction as its timeline, so none is left behind
+	entities, err := orderRepo.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entities)
+}
@@ -0,0 +1,22 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+)
+
+type (
+	ReportRepo interface {
+		GetDailySales(context.Context, domain.ReportRange) ([]*domain.DailySales, error)
+		GetDailyItemSales(context.Context, domain.ReportRange) ([]*domain.DailyItemSales, error)
+		GetPrepTimes(context.Context, domain.ReportRange) ([]*domain.PrepTime, error)
+		Refresh(context.Context) error
+	}
+
+	UseCase interface {
+		GetSalesReport(context.Context, domain.ReportRange, []domain.ReportGroupBy) ([]*domain.SalesReportRow, error)
+		GetPrepTimeReport(context.Context, domain.ReportRange, []domain.ReportGroupBy) ([]*domain.PrepTimeReportRow, error)
+		RefreshReports(context.Context) error
+	}
+)
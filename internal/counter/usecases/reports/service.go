@@ -0,0 +1,72 @@
+package reports
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/counter/domain"
+)
+
+type usecase struct {
+	reportRepo ReportRepo
+}
+
+var _ UseCase = (*usecase)(nil)
+
+var UseCaseSet = wire.NewSet(NewUseCase)
+
+func NewUseCase(reportRepo ReportRepo) UseCase {
+	return &usecase{
+		reportRepo: reportRepo,
+	}
+}
+
+func (uc *usecase) GetSalesReport(
+	ctx context.Context,
+	reportRange domain.ReportRange,
+	groupBy []domain.ReportGroupBy,
+) ([]*domain.SalesReportRow, error) {
+	daily, err := uc.reportRepo.GetDailySales(ctx, reportRange)
+	if err != nil {
+		return nil, errors.Wrap(err, "reportRepo.GetDailySales")
+	}
+
+	dailyItems, err := uc.reportRepo.GetDailyItemSales(ctx, reportRange)
+	if err != nil {
+		return nil, errors.Wrap(err, "reportRepo.GetDailyItemSales")
+	}
+
+	rows, err := domain.BuildSalesReport(daily, dailyItems, groupBy)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.BuildSalesReport")
+	}
+
+	return rows, nil
+}
+
+func (uc *usecase) GetPrepTimeReport(
+	ctx context.Context,
+	reportRange domain.ReportRange,
+	groupBy []domain.ReportGroupBy,
+) ([]*domain.PrepTimeReportRow, error) {
+	times, err := uc.reportRepo.GetPrepTimes(ctx, reportRange)
+	if err != nil {
+		return nil, errors.Wrap(err, "reportRepo.GetPrepTimes")
+	}
+
+	rows, err := domain.BuildPrepTimeReport(times, groupBy)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.BuildPrepTimeReport")
+	}
+
+	return rows, nil
+}
+
+func (uc *usecase) RefreshReports(ctx context.Context) error {
+	if err := uc.reportRepo.Refresh(ctx); err != nil {
+		return errors.Wrap(err, "reportRepo.Refresh")
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+// Package gateway is the REST API in front of the gRPC services, cmd/proxy and cmd/coffeeshop serve it.
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	gwruntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	// the services put BadRequest and ErrorInfo into their statuses, the gateway needs the types to write them out
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+const (
+	_domain = "gateway"
+	// _preflightMaxAge is how many seconds a browser may keep the answer to a preflight.
+	_preflightMaxAge = "600"
+)
+
+// New proxies the REST API to the product and the counter services on their connections.
+func New(
+	ctx context.Context,
+	productConn, counterConn *grpc.ClientConn,
+	opts ...gwruntime.ServeMuxOption,
+) (http.Handler, error) {
+	mux := gwruntime.NewServeMux(append([]gwruntime.ServeMuxOption{gwruntime.WithIncomingHeaderMatcher(matchHeader)}, opts...)...)
+
+	err := gen.RegisterProductServiceHandler(ctx, mux, productConn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gen.RegisterCounterServiceHandler(ctx, mux, counterConn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = gen.RegisterReportServiceHandler(ctx, mux, counterConn)
+	if err != nil {
+		return nil, err
+	}
+
+	return mux, nil
+}
+
+type handler struct {
+	origins []string
+	authn   *auth.Authenticator
+}
+
+type Option func(*handler)
+
+// AllowedOrigins are the origins of the pages which may call the API from a browser, * allows every origin.
+// Without any the browsers keep the pages of other origins from calling it.
+func AllowedOrigins(origins ...string) Option {
+	return func(h *handler) {
+		h.origins = append(h.origins, origins...)
+	}
+}
+
+// Authenticator refuses the requests with credentials which don't verify before they reach a service.
+// The services check the roles themselves, a request without credentials may still call a public method.
+func Authenticator(authn *auth.Authenticator) Option {
+	return func(h *handler) {
+		h.authn = authn
+	}
+}
+
+// Handler logs and traces the requests to gw, checks their credentials and answers the CORS preflights,
+// the span of a request is the parent of the calls the gateway makes for it.
+func Handler(name string, gw http.Handler, opts ...Option) http.Handler {
+	h := &handler{}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return otelhttp.NewHandler(h.allowCORS(h.authenticate(withLogger(gw))), name, otelhttp.WithSpanNameFormatter(
+		func(_ string, r *http.Request) string { return r.Method + " " + r.URL.Path },
+	))
+}
+
+// allowCORS lets the allowed origins read the responses, the others get no CORS headers and their preflights fail.
+func (h *handler) allowCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		// the response depends on the origin, caches must not hand it to the pages of another one
+		w.Header().Add("Vary", "Origin")
+
+		allowed := lo.Contains(h.origins, "*") || lo.Contains(h.origins, origin)
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		switch {
+		case preflight && !allowed:
+			slog.Info("refused a preflight request", "origin", origin, "http_path", r.URL.Path)
+			w.WriteHeader(http.StatusForbidden)
+		case preflight:
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			preflightHandler(w, r)
+		case allowed:
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			next.ServeHTTP(w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+func preflightHandler(w http.ResponseWriter, r *http.Request) {
+	headers := []string{"Authorization", "Content-Type", "X-API-Key"}
+	w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ","))
+
+	methods := []string{"GET", "HEAD", "POST", "PUT", "DELETE"}
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ","))
+	w.Header().Set("Access-Control-Max-Age", _preflightMaxAge)
+	w.WriteHeader(http.StatusNoContent)
+
+	slog.Info("preflight request", "http_path", r.URL.Path)
+}
+
+// authenticate answers 401 to the requests with credentials which don't verify, in the JSON of the gateway errors,
+// and passes the identity of the others on for the log. The credentials themselves go to the services as metadata.
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	if h.authn == nil || !h.authn.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := h.authn.Authenticate(auth.Credentials{
+			Authorization: r.Header.Get(auth.HeaderAuthorization),
+			APIKey:        r.Header.Get(auth.HeaderAPIKey),
+		})
+
+		switch {
+		case errors.Is(err, auth.ErrNoCredentials):
+			next.ServeHTTP(w, r)
+		case err != nil:
+			writeStatus(w, grpcerror.ToStatus(err, _domain, auth.ErrorRules...))
+		default:
+			next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), id)))
+		}
+	})
+}
+
+func writeStatus(w http.ResponseWriter, st *status.Status) {
+	body, err := protojson.Marshal(st.Proto())
+	if err != nil {
+		slog.Error("failed to marshal a status", "error", err)
+
+		body = []byte(`{"code":16,"message":"unauthenticated"}`)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	w.WriteHeader(gwruntime.HTTPStatusFromCode(st.Code()))
+
+	if _, err := w.Write(body); err != nil {
+		slog.Error("failed to write a response", "error", err)
+	}
+}
+
+func withLogger(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject := ""
+		if id, ok := auth.FromContext(r.Context()); ok {
+			subject = id.Subject
+		}
+
+		slog.Info("Run request", "http_method", r.Method, "http_url", r.URL, "subject", subject)
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// matchHeader forwards the API key of a device to the services besides the headers the gateway forwards anyway,
+// the Authorization header is one of them.
+func matchHeader(key string) (string, bool) {
+	if strings.EqualFold(key, auth.HeaderAPIKey) {
+		return auth.HeaderAPIKey, true
+	}
+
+	return gwruntime.DefaultHeaderMatcher(key)
+}
@@ -0,0 +1,134 @@
+package inprocess
+
+import (
+	"fmt"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/cmd/coffeeshop/config"
+	"github.com/thangchung/go-coffeeshop/db/migrations"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	_embeddedUser     = "postgres"
+	_embeddedPassword = "postgres"
+	_embeddedDatabase = "postgres"
+)
+
+// OpenDatabase starts the embedded PostgreSQL unless it is disabled and brings the schemas of the services up to date,
+// the services share the returned engine. The sqlite driver keeps everything in the file of the DSN instead.
+func OpenDatabase(cfg *config.Config) (postgres.DBEngine, func(), error) {
+	dsnURL := postgres.DBConnString(cfg.PG.DsnURL)
+	stop := func() {}
+
+	if cfg.PG.Driver == postgres.DriverSQLite {
+		return openSQLite(cfg, dsnURL)
+	}
+
+	if cfg.EmbeddedPG.Enabled {
+		pgCfg := embeddedpostgres.DefaultConfig().
+			Port(cfg.EmbeddedPG.Port).
+			Username(_embeddedUser).
+			Password(_embeddedPassword).
+			Database(_embeddedDatabase)
+
+		if cfg.EmbeddedPG.DataPath != "" {
+			pgCfg = pgCfg.DataPath(cfg.EmbeddedPG.DataPath)
+		}
+
+		if cfg.EmbeddedPG.CachePath != "" {
+			pgCfg = pgCfg.CachePath(cfg.EmbeddedPG.CachePath)
+		}
+
+		embedded := embeddedpostgres.NewDatabase(pgCfg)
+		if err := embedded.Start(); err != nil {
+			return nil, nil, errors.Wrap(err, "embedded.Start")
+		}
+
+		slog.Info("🐘 started the embedded postgres", "port", cfg.EmbeddedPG.Port)
+
+		dsnURL = postgres.DBConnString(fmt.Sprintf(
+			"host=127.0.0.1 port=%d user=%s password=%s dbname=%s sslmode=disable",
+			cfg.EmbeddedPG.Port, _embeddedUser, _embeddedPassword, _embeddedDatabase,
+		))
+		stop = func() {
+			if err := embedded.Stop(); err != nil {
+				slog.Error("failed to stop the embedded postgres", "error", err)
+			}
+		}
+	}
+
+	if err := migrateAll(cfg.PG.Driver, dsnURL); err != nil {
+		stop()
+
+		return nil, nil, err
+	}
+
+	pg, err := postgres.New(cfg.PG.Driver, dsnURL, postgres.Pool(cfg.PG), postgres.Name(cfg.Name))
+	if err != nil {
+		stop()
+
+		return nil, nil, err
+	}
+
+	return pg, func() {
+		pg.Close()
+		stop()
+	}, nil
+}
+
+func openSQLite(cfg *config.Config, dsnURL postgres.DBConnString) (postgres.DBEngine, func(), error) {
+	if err := migrateAll(cfg.PG.Driver, dsnURL); err != nil {
+		return nil, nil, err
+	}
+
+	pg, err := postgres.NewSQLiteDB(dsnURL, postgres.Name(cfg.Name))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	slog.Info("🪶 using sqlite", "file", cfg.PG.DsnURL)
+
+	return pg, pg.Close, nil
+}
+
+// migrateAll applies the migrations of every service, each set keeps its own version table.
+func migrateAll(driver string, dsnURL postgres.DBConnString) error {
+	for _, service := range migrations.Services() {
+		if err := migrateUp(driver, service, dsnURL); err != nil {
+			return errors.Wrapf(err, "migrate %s", service)
+		}
+	}
+
+	return nil
+}
+
+func migrateUp(driver, service string, dsnURL postgres.DBConnString) error {
+	newMigrate, newDB := migrations.New, postgres.NewPostgresDB
+	if driver == postgres.DriverSQLite {
+		newMigrate, newDB = migrations.NewSQLite, postgres.NewSQLiteDB
+	}
+
+	pg, err := newDB(dsnURL, postgres.MaxOpenConns(1), postgres.Name("migrate"))
+	if err != nil {
+		return err
+	}
+
+	// closing pg unregisters the metrics of the pool as well, it runs after m has closed the database
+	defer pg.Close()
+
+	m, err := newMigrate(service, pg.GetDB())
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return errors.Wrap(err, "m.Up")
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+// Package inprocess runs the product, counter, barista and kitchen services in one process.
+// They talk over in-memory gRPC connections and a broker of the caller, usually the in-memory bus,
+// and share one database. cmd/coffeeshop and the integration tests boot the services with it.
+package inprocess
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	baristaConfig "github.com/thangchung/go-coffeeshop/cmd/barista/config"
+	"github.com/thangchung/go-coffeeshop/cmd/coffeeshop/config"
+	counterConfig "github.com/thangchung/go-coffeeshop/cmd/counter/config"
+	kitchenConfig "github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
+	productConfig "github.com/thangchung/go-coffeeshop/cmd/product/config"
+	baristaApp "github.com/thangchung/go-coffeeshop/internal/barista/app"
+	counterApp "github.com/thangchung/go-coffeeshop/internal/counter/app"
+	counterRouter "github.com/thangchung/go-coffeeshop/internal/counter/app/router"
+	"github.com/thangchung/go-coffeeshop/internal/gateway"
+	kitchenApp "github.com/thangchung/go-coffeeshop/internal/kitchen/app"
+	productApp "github.com/thangchung/go-coffeeshop/internal/product/app"
+	productRouter "github.com/thangchung/go-coffeeshop/internal/product/app/router"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcvalidate"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	_bufSize = 1024 * 1024
+	// _readinessInterval is how often the gRPC health services are brought in line with the readiness checks.
+	_readinessInterval = 10 * time.Second
+
+	_productName = "product-service"
+	_counterName = "counter-service"
+	_baristaName = "barista-service"
+	_kitchenName = "kitchen-service"
+)
+
+// Start starts every service against one database and the in-memory bus and returns the gateway in front of them,
+// the servers stop when ctx is done and the cleanup closes the bus and the database.
+func Start(ctx context.Context, cfg *config.Config, checker *health.Checker) (http.Handler, func(), error) {
+	pg, closeDB, err := OpenDatabase(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bus := inmem.NewBus()
+
+	// the subscriptions end before the database goes away
+	cleanup := func() {
+		bus.Close()
+		closeDB()
+	}
+
+	productConn, err := StartProduct(ctx, cfg, bus, checker)
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	counterConn, err := StartCounter(ctx, cfg, pg, bus, productConn, checker)
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	if err := StartBarista(cfg, pg, bus, checker); err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	if err := StartKitchen(cfg, pg, bus, checker); err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	gw, err := gateway.New(ctx, productConn, counterConn)
+	if err != nil {
+		cleanup()
+
+		return nil, nil, err
+	}
+
+	return gw, cleanup, nil
+}
+
+// StartProduct serves the product service in memory until ctx is done,
+// the returned connection is shared by the counter and the gateway.
+func StartProduct(
+	ctx context.Context,
+	cfg *config.Config,
+	broker messaging.Broker,
+	checker *health.Checker,
+) (*grpc.ClientConn, error) {
+	server, healthServer, err := newServer(ctx, cfg, _productName, productRouter.ErrorRules)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := productApp.InitInProcessApp(&productConfig.Config{
+		App:     configs.App{Name: _productName, Version: cfg.Version},
+		GRPC:    cfg.GRPC,
+		Images:  cfg.Images,
+		Menu:    cfg.Menu,
+		Catalog: cfg.Catalog,
+	}, broker, server)
+	if err != nil {
+		return nil, err
+	}
+
+	a.ConfigureRoutes()
+
+	serviceChecker := health.NewChecker()
+	a.RegisterChecks(serviceChecker)
+
+	return serve(ctx, cfg, _productName, server, healthServer, serviceChecker, checker)
+}
+
+// StartCounter serves the counter service in memory until ctx is done and consumes the updates of the stations.
+func StartCounter(
+	ctx context.Context,
+	cfg *config.Config,
+	pg postgres.DBEngine,
+	broker messaging.Broker,
+	productConn *grpc.ClientConn,
+	checker *health.Checker,
+) (*grpc.ClientConn, error) {
+	server, healthServer, err := newServer(ctx, cfg, _counterName, counterRouter.ErrorRules)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := counterApp.InitInProcessApp(&counterConfig.Config{
+		App:  configs.App{Name: _counterName, Version: cfg.Version},
+		GRPC: cfg.GRPC,
+		PG:   cfg.PG,
+		ProductClient: counterConfig.ProductClient{
+			Timeout:       cfg.GRPC.Timeout,
+			CacheTTL:      cfg.ProductCache.TTL,
+			CacheMaxStale: cfg.ProductCache.MaxStale,
+		},
+		OrderStore: cfg.OrderStore,
+		Reporting:  cfg.Reporting,
+	}, pg, broker, productConn, server)
+	if err != nil {
+		return nil, err
+	}
+
+	a.ConfigureRoutes()
+
+	serviceChecker := health.NewChecker()
+	a.RegisterChecks(serviceChecker)
+
+	catalogConsumer, err := a.NewCatalogConsumer()
+	if err != nil {
+		return nil, err
+	}
+
+	serviceChecker.Register("catalog-consumer", catalogConsumer.Check)
+
+	go subscribe(_counterName, a.Consumer, a.Worker)
+	go subscribe(_counterName, catalogConsumer, a.Worker)
+	go a.RefreshReports(ctx, cfg.Reporting.RefreshInterval)
+
+	return serve(ctx, cfg, _counterName, server, healthServer, serviceChecker, checker)
+}
+
+// StartBarista consumes the barista orders until the broker is closed.
+func StartBarista(cfg *config.Config, pg postgres.DBEngine, broker messaging.Broker, checker *health.Checker) error {
+	a, err := baristaApp.InitInProcessApp(&baristaConfig.Config{
+		App:         configs.App{Name: _baristaName, Version: cfg.Version},
+		PG:          cfg.PG,
+		Preparation: cfg.Preparation,
+	}, pg, broker)
+	if err != nil {
+		return err
+	}
+
+	a.ConfigureRoutes()
+
+	serviceChecker := health.NewChecker()
+	a.RegisterChecks(serviceChecker)
+	checker.Register(_baristaName, serviceChecker.Ready)
+
+	go subscribe(_baristaName, a.Consumer, a.Worker)
+
+	return nil
+}
+
+// StartKitchen consumes the kitchen orders until the broker is closed.
+func StartKitchen(cfg *config.Config, pg postgres.DBEngine, broker messaging.Broker, checker *health.Checker) error {
+	a, err := kitchenApp.InitInProcessApp(&kitchenConfig.Config{
+		App:         configs.App{Name: _kitchenName, Version: cfg.Version},
+		PG:          cfg.PG,
+		Preparation: cfg.Preparation,
+	}, pg, broker)
+	if err != nil {
+		return err
+	}
+
+	a.ConfigureRoutes()
+
+	serviceChecker := health.NewChecker()
+	a.RegisterChecks(serviceChecker)
+	checker.Register(_kitchenName, serviceChecker.Ready)
+
+	go subscribe(_kitchenName, a.Consumer, a.Worker)
+
+	return nil
+}
+
+// newServer is the gRPC server of a service as its own binary sets it up, it stops when ctx is done.
+func newServer(
+	ctx context.Context,
+	cfg *config.Config,
+	name string,
+	rules []grpcerror.Rule,
+) (*grpc.Server, *grpchealth.Server, error) {
+	validation, err := grpcvalidate.UnaryServerInterceptor(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the callers are checked before anything else runs for them,
+	// the error mapping comes next, so it passes the statuses of the validation on as they are
+	server, healthServer, err := grpcx.NewServer(
+		grpcx.ServerConfig(cfg.GRPC),
+		grpcx.UnaryInterceptors(
+			auth.UnaryServerInterceptor(name, authn),
+			grpcerror.UnaryServerInterceptor(name, rules...),
+			validation,
+		),
+		grpcx.StreamInterceptors(auth.StreamServerInterceptor(name, authn)),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	go func() {
+		defer server.GracefulStop()
+		<-ctx.Done()
+		healthServer.Shutdown()
+	}()
+
+	return server, healthServer, nil
+}
+
+// serve runs the server on an in-memory listener and connects to it,
+// the readiness of the service is one check of the process.
+// The calls a service makes on the connection carry the credentials of the call it handles, like its binary does.
+func serve(
+	ctx context.Context,
+	cfg *config.Config,
+	name string,
+	server *grpc.Server,
+	healthServer *grpchealth.Server,
+	serviceChecker, checker *health.Checker,
+) (*grpc.ClientConn, error) {
+	grpcx.InitializeMetrics(server)
+
+	checker.Register(name, serviceChecker.Ready)
+
+	go serviceChecker.Watch(ctx, _readinessInterval, healthServer)
+
+	l := bufconn.Listen(_bufSize)
+
+	go func() {
+		if err := server.Serve(l); err != nil {
+			slog.Error("failed start gRPC server", "error", err, "service", name)
+		}
+	}()
+
+	conn, err := grpcx.Dial(
+		name,
+		grpcx.CallTimeout(cfg.GRPC.Timeout),
+		grpcx.UnaryClientInterceptors(auth.UnaryClientInterceptor()),
+		grpcx.Dialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := conn.Close(); err != nil {
+			slog.Error("failed to close the connection", "error", err, "service", name)
+		}
+	}()
+
+	return conn, nil
+}
+
+// subscribe consumes until the bus is closed, the readiness check of the consumer reports when it couldn't start.
+func subscribe(name string, sub messaging.Subscriber, fn messaging.Worker) {
+	if err := sub.Subscribe(fn); err != nil {
+		slog.Error("failed to start Consumer", "error", err, "service", name)
+	}
+}
@@ -4,24 +4,23 @@ import (
 	"context"
 	"encoding/json"
 
-	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
 	"github.com/thangchung/go-coffeeshop/internal/kitchen/eventhandlers"
 	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"golang.org/x/exp/slog"
 )
 
 type App struct {
 	Cfg *config.Config
 
-	PG       postgres.DBEngine
-	AMQPConn *amqp.Connection
+	PG     postgres.DBEngine
+	Broker messaging.Broker
 
-	CounterOrderPub pkgPublisher.EventPublisher
-	Consumer        pkgConsumer.EventConsumer
+	CounterOrderPub messaging.Publisher
+	Consumer        messaging.Subscriber
 
 	handler eventhandlers.KitchenOrderedEventHandler
 }
@@ -29,15 +28,15 @@ type App struct {
 func New(
 	cfg *config.Config,
 	pg postgres.DBEngine,
-	amqpConn *amqp.Connection,
-	counterOrderPub pkgPublisher.EventPublisher,
-	consumer pkgConsumer.EventConsumer,
+	broker messaging.Broker,
+	counterOrderPub messaging.Publisher,
+	consumer messaging.Subscriber,
 	handler eventhandlers.KitchenOrderedEventHandler,
 ) *App {
 	return &App{
-		Cfg:      cfg,
-		PG:       pg,
-		AMQPConn: amqpConn,
+		Cfg:    cfg,
+		PG:     pg,
+		Broker: broker,
 
 		CounterOrderPub: counterOrderPub,
 		Consumer:        consumer,
@@ -46,8 +45,33 @@ func New(
 	}
 }
 
-func (c *App) Worker(ctx context.Context, messages <-chan amqp.Delivery) {
+// RegisterChecks adds the dependencies the app can't work without to the readiness checks.
+func (c *App) RegisterChecks(checker *health.Checker) {
+	checker.Register("postgres", health.SQL(c.PG.GetDB()))
+	checker.Register("broker", c.Broker.Check)
+	checker.Register("consumer", c.Consumer.Check)
+}
+
+// ConfigureRoutes points the consumer at the kitchen orders and the publisher at the counter.
+func (c *App) ConfigureRoutes() {
+	c.CounterOrderPub.Configure(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.BindingKey("counter-order-routing-key"),
+		messaging.MessageTypeName("kitchen-order-updated"),
+	)
+
+	c.Consumer.Configure(
+		messaging.ExchangeName("kitchen-order-exchange"),
+		messaging.QueueName("kitchen-order-queue"),
+		messaging.BindingKey("kitchen-order-routing-key"),
+		messaging.ConsumerTag("kitchen-order-consumer"),
+	)
+}
+
+func (c *App) Worker(ctx context.Context, messages <-chan messaging.Delivery) {
 	for delivery := range messages {
+		deliveryCtx, span := messaging.StartConsumeSpan(ctx, delivery)
+
 		slog.Info("processDeliveries", "delivery_tag", delivery.DeliveryTag)
 		slog.Info("received", "delivery_type", delivery.Type)
 
@@ -57,26 +81,28 @@ func (c *App) Worker(ctx context.Context, messages <-chan amqp.Delivery) {
 			err := json.Unmarshal(delivery.Body, &payload)
 
 			if err != nil {
-				slog.Error("failed to Unmarshal message", err)
+				slog.Error("failed to Unmarshal message", "error", err)
 			}
 
-			err = c.handler.Handle(ctx, payload)
+			err = c.handler.Handle(deliveryCtx, payload)
 
 			if err != nil {
 				if err = delivery.Reject(false); err != nil {
-					slog.Error("failed to delivery.Reject", err)
+					slog.Error("failed to delivery.Reject", "error", err)
 				}
 
-				slog.Error("failed to process delivery", err)
+				slog.Error("failed to process delivery", "error", err)
 			} else {
 				err = delivery.Ack(false)
 				if err != nil {
-					slog.Error("failed to acknowledge delivery", err)
+					slog.Error("failed to acknowledge delivery", "error", err)
 				}
 			}
 		default:
 			slog.Info("default")
 		}
+
+		span.End()
 	}
 
 	slog.Info("deliveries channel closed")
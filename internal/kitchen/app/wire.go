@@ -8,10 +8,10 @@ import (
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
 	"github.com/thangchung/go-coffeeshop/internal/kitchen/eventhandlers"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/infras/repo"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
-	pkgConsumer "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 )
 
 func InitApp(
@@ -22,15 +22,34 @@ func InitApp(
 	panic(wire.Build(
 		New,
 		dbEngineFunc,
+		postgres.UnitOfWorkSet,
 		rabbitMQFunc,
-		pkgPublisher.EventPublisherSet,
-		pkgConsumer.EventConsumerSet,
+		rabbitmq.BrokerSet,
+		messaging.PublisherSet,
+		messaging.SubscriberSet,
+		repo.RepositorySet,
 		eventhandlers.KitchenOrderedEventHandlerSet,
 	))
 }
 
-func dbEngineFunc(url postgres.DBConnString) (postgres.DBEngine, func(), error) {
-	db, err := postgres.NewPostgresDB(url)
+// InitInProcessApp builds the app on a database and a broker shared with the other services of the process.
+func InitInProcessApp(
+	cfg *config.Config,
+	pg postgres.DBEngine,
+	broker messaging.Broker,
+) (*App, error) {
+	panic(wire.Build(
+		New,
+		postgres.UnitOfWorkSet,
+		messaging.PublisherSet,
+		messaging.SubscriberSet,
+		repo.RepositorySet,
+		eventhandlers.KitchenOrderedEventHandlerSet,
+	))
+}
+
+func dbEngineFunc(cfg *config.Config, url postgres.DBConnString) (postgres.DBEngine, func(), error) {
+	db, err := postgres.New(cfg.PG.Driver, url, postgres.Pool(cfg.PG), postgres.Name(cfg.Name))
 	if err != nil {
 		return nil, nil, err
 	}
@@ -10,16 +10,16 @@ import (
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
 	"github.com/thangchung/go-coffeeshop/internal/kitchen/eventhandlers"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/infras/repo"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
 	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
-	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 )
 
 // Injectors from wire.go:
 
 func InitApp(cfg *config.Config, dbConnStr postgres.DBConnString, rabbitMQConnStr rabbitmq.RabbitMQConnStr) (*App, func(), error) {
-	dbEngine, cleanup, err := dbEngineFunc(dbConnStr)
+	dbEngine, cleanup, err := dbEngineFunc(cfg, dbConnStr)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -28,30 +28,50 @@ func InitApp(cfg *config.Config, dbConnStr postgres.DBConnString, rabbitMQConnSt
 		cleanup()
 		return nil, nil, err
 	}
-	eventPublisher, err := publisher.NewPublisher(connection)
+	broker := rabbitmq.NewBroker(connection)
+	publisher, err := messaging.NewPublisher(broker)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	eventConsumer, err := consumer.NewConsumer(connection)
+	subscriber, err := messaging.NewSubscriber(broker)
 	if err != nil {
 		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	kitchenOrderedEventHandler := eventhandlers.NewKitchenOrderedEventHandler(dbEngine, eventPublisher)
-	app := New(cfg, dbEngine, connection, eventPublisher, eventConsumer, kitchenOrderedEventHandler)
+	unitOfWork := postgres.NewUnitOfWork(dbEngine)
+	orderRepo := repo.NewOrderRepoFromConfig(cfg, dbEngine)
+	kitchenOrderedEventHandler := eventhandlers.NewKitchenOrderedEventHandler(cfg, unitOfWork, orderRepo, publisher)
+	app := New(cfg, dbEngine, broker, publisher, subscriber, kitchenOrderedEventHandler)
 	return app, func() {
 		cleanup2()
 		cleanup()
 	}, nil
 }
 
+// InitInProcessApp builds the app on a database and a broker shared with the other services of the process.
+func InitInProcessApp(cfg *config.Config, pg postgres.DBEngine, broker messaging.Broker) (*App, error) {
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		return nil, err
+	}
+	subscriber, err := messaging.NewSubscriber(broker)
+	if err != nil {
+		return nil, err
+	}
+	unitOfWork := postgres.NewUnitOfWork(pg)
+	orderRepo := repo.NewOrderRepoFromConfig(cfg, pg)
+	kitchenOrderedEventHandler := eventhandlers.NewKitchenOrderedEventHandler(cfg, unitOfWork, orderRepo, publisher)
+	app := New(cfg, pg, broker, publisher, subscriber, kitchenOrderedEventHandler)
+	return app, nil
+}
+
 // wire.go:
 
-func dbEngineFunc(url postgres.DBConnString) (postgres.DBEngine, func(), error) {
-	db, err := postgres.NewPostgresDB(url)
+func dbEngineFunc(cfg *config.Config, url postgres.DBConnString) (postgres.DBEngine, func(), error) {
+	db, err := postgres.New(cfg.PG.Driver, url, postgres.Pool(cfg.PG), postgres.Name(cfg.Name))
 	if err != nil {
 		return nil, nil, err
 	}
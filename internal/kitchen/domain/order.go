@@ -19,18 +19,25 @@ type KitchenOrder struct {
 	Updated  time.Time
 }
 
-func NewKitchenOrder(e event.KitchenOrdered) KitchenOrder {
+// NewKitchenOrder makes the item, it takes the time of the item type multiplied by timeScale.
+func NewKitchenOrder(e event.KitchenOrdered, timeScale float64) KitchenOrder {
 	timeIn := time.Now()
 
-	delay := calculateDelay(e.ItemType)
+	delay := time.Duration(float64(calculateDelay(e.ItemType)) * timeScale)
 	time.Sleep(delay) // simulate the delay when makes the drink
 
 	timeUp := time.Now().Add(delay)
 
+	// the messages of older counters have no name, the item types they know have one in this build
+	name := e.Name
+	if name == "" {
+		name = e.ItemType.String()
+	}
+
 	order := KitchenOrder{
 		ID:       e.ItemLineID,
 		OrderID:  e.OrderID,
-		ItemName: e.ItemType.String(),
+		ItemName: name,
 		ItemType: e.ItemType,
 		TimeUp:   timeUp,
 		Created:  time.Now(),
@@ -40,7 +47,7 @@ func NewKitchenOrder(e event.KitchenOrdered) KitchenOrder {
 	orderUpdatedEvent := event.KitchenOrderUpdated{
 		OrderID:    e.OrderID,
 		ItemLineID: e.ItemLineID,
-		Name:       e.ItemType.String(),
+		Name:       name,
 		ItemType:   e.ItemType,
 		MadeBy:     "teesee",
 		TimeIn:     timeIn,
@@ -2,22 +2,23 @@ package eventhandlers
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
 	"github.com/thangchung/go-coffeeshop/internal/kitchen/domain"
-	"github.com/thangchung/go-coffeeshop/internal/kitchen/infras/postgresql"
 	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/pkg/postgres"
-	pkgPublisher "github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
 	"golang.org/x/exp/slog"
 )
 
 type kitchenOrderedEventHandler struct {
-	pg         postgres.DBEngine
-	counterPub pkgPublisher.EventPublisher
+	cfg        *config.Config
+	uow        postgres.UnitOfWork
+	repo       domain.OrderRepo
+	counterPub messaging.Publisher
 }
 
 var _ KitchenOrderedEventHandler = (*kitchenOrderedEventHandler)(nil)
@@ -25,11 +26,15 @@ var _ KitchenOrderedEventHandler = (*kitchenOrderedEventHandler)(nil)
 var KitchenOrderedEventHandlerSet = wire.NewSet(NewKitchenOrderedEventHandler)
 
 func NewKitchenOrderedEventHandler(
-	pg postgres.DBEngine,
-	counterPub pkgPublisher.EventPublisher,
+	cfg *config.Config,
+	uow postgres.UnitOfWork,
+	repo domain.OrderRepo,
+	counterPub messaging.Publisher,
 ) KitchenOrderedEventHandler {
 	return &kitchenOrderedEventHandler{
-		pg:         pg,
+		cfg:        cfg,
+		uow:        uow,
+		repo:       repo,
 		counterPub: counterPub,
 	}
 }
@@ -37,47 +42,29 @@ func NewKitchenOrderedEventHandler(
 func (h *kitchenOrderedEventHandler) Handle(ctx context.Context, e event.KitchenOrdered) error {
 	slog.Info("kitchenOrderedEventHandler-Handle", "KitchenOrdered", e)
 
-	order := domain.NewKitchenOrder(e)
+	order := domain.NewKitchenOrder(e, h.cfg.Preparation.TimeScale)
 
-	db := h.pg.GetDB()
-	querier := postgresql.New(db)
+	return h.uow.WithinTx(ctx, func(ctx context.Context) error {
+		if err := h.repo.Create(ctx, &order); err != nil {
+			slog.Info("failed to call to repo", "error", err)
 
-	tx, err := db.Begin()
-	if err != nil {
-		return errors.Wrap(err, "kitchenOrderedEventHandler.Handle")
-	}
-
-	qtx := querier.WithTx(tx)
-
-	_, err = qtx.CreateOrder(ctx, postgresql.CreateOrderParams{
-		ID:       order.ID,
-		OrderID:  e.OrderID,
-		ItemType: int32(order.ItemType),
-		ItemName: order.ItemName,
-		TimeUp:   order.TimeUp,
-		Created:  order.Created,
-		Updated: sql.NullTime{
-			Time:  order.Updated,
-			Valid: true,
-		},
-	})
-	if err != nil {
-		slog.Info("failed to call to repo", "error", err)
-
-		return errors.Wrap(err, "kitchenOrderedEventHandler-querier.CreateOrder")
-	}
-
-	// todo: it might cause dual-write problem, but we accept it temporary
-	for _, event := range order.DomainEvents() {
-		eventBytes, err := json.Marshal(event)
-		if err != nil {
-			return errors.Wrap(err, "json.Marshal[event]")
+			return errors.Wrap(err, "repo.Create")
 		}
 
-		if err := h.counterPub.Publish(ctx, eventBytes, "text/plain"); err != nil {
-			return errors.Wrap(err, "counterPub.Publish")
-		}
-	}
-
-	return tx.Commit()
+		// publish once the order is stored, a failure here still loses the events (no outbox yet)
+		return postgres.AfterCommit(ctx, func(ctx context.Context) error {
+			for _, event := range order.DomainEvents() {
+				eventBytes, err := json.Marshal(event)
+				if err != nil {
+					return errors.Wrap(err, "json.Marshal[event]")
+				}
+
+				if err := h.counterPub.Publish(ctx, eventBytes, "text/plain"); err != nil {
+					return errors.Wrap(err, "counterPub.Publish")
+				}
+			}
+
+			return nil
+		})
+	})
 }
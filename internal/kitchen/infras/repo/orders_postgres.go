@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/domain"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/infras/postgresql"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type orderRepo struct {
+	pg postgres.DBEngine
+}
+
+var _ domain.OrderRepo = (*orderRepo)(nil)
+
+func NewOrderRepo(pg postgres.DBEngine) domain.OrderRepo {
+	return &orderRepo{pg: pg}
+}
+
+func (d *orderRepo) Create(ctx context.Context, order *domain.KitchenOrder) error {
+	querier := postgresql.New(postgres.Conn(ctx, d.pg))
+
+	_, err := querier.CreateOrder(ctx, postgresql.CreateOrderParams{
+		ID:       order.ID,
+		OrderID:  order.OrderID,
+		ItemType: int32(order.ItemType),
+		ItemName: order.ItemName,
+		TimeUp:   order.TimeUp,
+		Created:  order.Created,
+		Updated: sql.NullTime{
+			Time:  order.Updated,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "querier.CreateOrder")
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/domain"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/infras/sqlite"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type sqliteOrderRepo struct {
+	pg postgres.DBEngine
+}
+
+var _ domain.OrderRepo = (*sqliteOrderRepo)(nil)
+
+func NewSQLiteOrderRepo(pg postgres.DBEngine) domain.OrderRepo {
+	return &sqliteOrderRepo{pg: pg}
+}
+
+func (d *sqliteOrderRepo) Create(ctx context.Context, order *domain.KitchenOrder) error {
+	querier := sqlite.New(postgres.Conn(ctx, d.pg))
+
+	err := querier.CreateOrder(ctx, sqlite.CreateOrderParams{
+		ID:       order.ID,
+		OrderID:  order.OrderID,
+		ItemType: int64(order.ItemType),
+		ItemName: order.ItemName,
+		TimeUp:   order.TimeUp,
+		Created:  order.Created,
+		Updated: sql.NullTime{
+			Time:  order.Updated,
+			Valid: true,
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "querier.CreateOrder")
+	}
+
+	return nil
+}
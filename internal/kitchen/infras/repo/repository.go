@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"github.com/google/wire"
+	"github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
+	"github.com/thangchung/go-coffeeshop/internal/kitchen/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+var RepositorySet = wire.NewSet(NewOrderRepoFromConfig)
+
+// NewOrderRepoFromConfig picks the queries of the database driver, SQLite has tables of its own.
+func NewOrderRepoFromConfig(cfg *config.Config, pg postgres.DBEngine) domain.OrderRepo {
+	if cfg.PG.Driver == postgres.DriverSQLite {
+		return NewSQLiteOrderRepo(pg)
+	}
+
+	return NewOrderRepo(pg)
+}
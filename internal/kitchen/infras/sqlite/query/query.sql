@@ -0,0 +1,13 @@
+-- name: CreateOrder :exec
+
+INSERT INTO
+    kitchen_orders (
+        id,
+        order_id,
+        item_type,
+        item_name,
+        time_up,
+        created,
+        updated
+    )
+VALUES (?, ?, ?, ?, ?, ?, ?);
@@ -13,6 +13,8 @@ type BaristaOrdered struct {
 	OrderID    uuid.UUID       `json:"orderId"`
 	ItemLineID uuid.UUID       `json:"itemLineId"`
 	ItemType   shared.ItemType `json:"itemType"`
+	// Name is the name of the item in the catalog, empty in the messages of older counters.
+	Name string `json:"name,omitempty"`
 }
 
 func (e BaristaOrdered) Identity() string {
@@ -24,6 +26,8 @@ type KitchenOrdered struct {
 	OrderID    uuid.UUID       `json:"orderId"`
 	ItemLineID uuid.UUID       `json:"itemLineId"`
 	ItemType   shared.ItemType `json:"itemType"`
+	// Name is the name of the item in the catalog, empty in the messages of older counters.
+	Name string `json:"name,omitempty"`
 }
 
 func (e KitchenOrdered) Identity() string {
@@ -72,3 +76,14 @@ type OrderUp struct {
 func (e *OrderUp) Identity() string {
 	return "OrderUp"
 }
+
+// CatalogChanged is published by the product service whenever items or their prices change.
+type CatalogChanged struct {
+	shared.DomainEvent
+	Items   []string  `json:"items"`
+	Created time.Time `json:"created"`
+}
+
+func (e *CatalogChanged) Identity() string {
+	return "CatalogChanged"
+}
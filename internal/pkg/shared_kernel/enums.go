@@ -35,10 +35,29 @@ const (
 	LocationRaleigh
 )
 
+// LocationUnknown is the location of the orders placed before the location of an order was recorded.
+const LocationUnknown Location = -1
+
 func (e Location) String() string {
 	return fmt.Sprintf("%d", int(e))
 }
 
+// Name is how a location is referred to in configuration, e.g. the price schedules of the menu.
+func (e Location) Name() string {
+	switch e {
+	case LocationAtlanta:
+		return "ATLANTA"
+	case LocationCharlotte:
+		return "CHARLOTTE"
+	case LocationRaleigh:
+		return "RALEIGH"
+	case LocationUnknown:
+		return "unknown"
+	default:
+		return e.String()
+	}
+}
+
 type CommandType int8
 
 const (
@@ -64,8 +83,9 @@ const (
 	ItemTypeCroissantChocolate
 )
 
+// String is the name of the item in the catalog, or the number of an item type this build doesn't know.
 func (e ItemType) String() string {
-	return []string{
+	names := []string{
 		"CAPPUCCINO",
 		"COFFEE_BLACK",
 		"COFFEE_WITH_ROOM",
@@ -76,6 +96,11 @@ func (e ItemType) String() string {
 		"CROISSANT",
 		"MUFFIN",
 		"CROISSANT_CHOCOLATE",
-		"CAPPUCCINO",
-	}[e]
+	}
+
+	if e < 0 || int(e) >= len(names) {
+		return fmt.Sprintf("%d", int(e))
+	}
+
+	return names[e]
 }
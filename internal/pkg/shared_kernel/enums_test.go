@@ -0,0 +1,18 @@
+package sharedkernel_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+)
+
+func TestItemTypeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "CAPPUCCINO", shared.ItemTypeCappuccino.String())
+	assert.Equal(t, "CROISSANT_CHOCOLATE", shared.ItemTypeCroissantChocolate.String())
+	// the types added by a catalog import have no name in this build
+	assert.Equal(t, "10", shared.ItemType(10).String())
+	assert.Equal(t, "-1", shared.ItemType(-1).String())
+}
@@ -3,23 +3,47 @@ package app
 import (
 	"github.com/thangchung/go-coffeeshop/cmd/product/config"
 	productUC "github.com/thangchung/go-coffeeshop/internal/product/usecases/products"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"github.com/thangchung/go-coffeeshop/proto/gen"
 )
 
 type App struct {
-	Cfg               *config.Config
+	Cfg    *config.Config
+	Broker messaging.Broker
+
+	CatalogPub        productUC.CatalogEventPublisher
 	UC                productUC.UseCase
 	ProductGRPCServer gen.ProductServiceServer
 }
 
 func New(
 	cfg *config.Config,
+	broker messaging.Broker,
+	catalogPub productUC.CatalogEventPublisher,
 	uc productUC.UseCase,
 	productGRPCServer gen.ProductServiceServer,
 ) *App {
 	return &App{
-		Cfg:               cfg,
+		Cfg:    cfg,
+		Broker: broker,
+
+		CatalogPub:        catalogPub,
 		UC:                uc,
 		ProductGRPCServer: productGRPCServer,
 	}
 }
+
+// RegisterChecks adds the dependencies of the app to the readiness checks, the catalog changes go out over the broker.
+func (a *App) RegisterChecks(checker *health.Checker) {
+	checker.Register("broker", a.Broker.Check)
+}
+
+// ConfigureRoutes points the catalog publisher at the counters.
+func (a *App) ConfigureRoutes() {
+	a.CatalogPub.Configure(
+		messaging.ExchangeName("product-catalog-exchange"),
+		messaging.BindingKey("product-catalog-routing-key"),
+		messaging.MessageTypeName("catalog-changed"),
+	)
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/blob"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"google.golang.org/grpc/codes"
+)
+
+// ErrorRules are the statuses of the product service errors, anything else is an internal error.
+var ErrorRules = []grpcerror.Rule{
+	{Target: domain.ErrItemNotFound, Code: codes.NotFound, Reason: "ITEM_NOT_FOUND"},
+	{Target: blob.ErrNotFound, Code: codes.NotFound, Reason: "IMAGE_NOT_FOUND"},
+	{Target: domain.ErrUnsupportedImage, Code: codes.InvalidArgument, Reason: "UNSUPPORTED_IMAGE"},
+	{Target: domain.ErrImageTooLarge, Code: codes.InvalidArgument, Reason: "IMAGE_TOO_LARGE"},
+	{Target: domain.ErrUnknownCatalogFormat, Code: codes.InvalidArgument, Reason: "UNKNOWN_CATALOG_FORMAT"},
+	{Target: domain.ErrInvalidCatalog, Code: codes.InvalidArgument, Reason: "INVALID_CATALOG"},
+	{Target: domain.ErrInvalidSearch, Code: codes.InvalidArgument, Reason: "INVALID_SEARCH"},
+}
@@ -2,14 +2,23 @@ package router
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/cmd/product/config"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
 	"github.com/thangchung/go-coffeeshop/internal/product/usecases/products"
 	"github.com/thangchung/go-coffeeshop/proto/gen"
 	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/api/httpbody"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 var _ gen.ProductServiceServer = (*productGRPCServer)(nil)
@@ -18,15 +27,18 @@ var ProductGRPCServerSet = wire.NewSet(NewProductGRPCServer)
 
 type productGRPCServer struct {
 	gen.UnimplementedProductServiceServer
-	uc products.UseCase
+	cfg *config.Config
+	uc  products.UseCase
 }
 
 func NewProductGRPCServer(
 	grpcServer *grpc.Server,
+	cfg *config.Config,
 	uc products.UseCase,
 ) gen.ProductServiceServer {
 	svc := productGRPCServer{
-		uc: uc,
+		cfg: cfg,
+		uc:  uc,
 	}
 
 	gen.RegisterProductServiceServer(grpcServer, &svc)
@@ -40,22 +52,17 @@ func (g *productGRPCServer) GetItemTypes(
 	ctx context.Context,
 	request *gen.GetItemTypesRequest,
 ) (*gen.GetItemTypesResponse, error) {
-	slog.Info("gRPC client", "http_method", "GET", "http_name", "GetItemTypes")
+	slog.Info("gRPC client", "http_method", "GET", "http_name", "GetItemTypes", "location", request.Location)
 
 	res := gen.GetItemTypesResponse{}
 
-	results, err := g.uc.GetItemTypes(ctx)
+	results, err := g.uc.GetItemTypes(ctx, shared.Location(request.Location).Name(), menuTime(request.At))
 	if err != nil {
 		return nil, errors.Wrap(err, "productGRPCServer-GetItemTypes")
 	}
 
 	for _, item := range results {
-		res.ItemTypes = append(res.ItemTypes, &gen.ItemTypeDto{
-			Name:  item.Name,
-			Type:  int32(item.Type),
-			Price: item.Price,
-			Image: item.Image,
-		})
+		res.ItemTypes = append(res.ItemTypes, g.toItemTypeDto(item))
 	}
 
 	return &res, nil
@@ -65,21 +72,234 @@ func (g *productGRPCServer) GetItemsByType(
 	ctx context.Context,
 	request *gen.GetItemsByTypeRequest,
 ) (*gen.GetItemsByTypeResponse, error) {
-	slog.Info("gRPC client", "http_method", "GET", "http_name", "GetItemsByType", "item_types", request.ItemTypes)
+	slog.Info("gRPC client", "http_method", "GET", "http_name", "GetItemsByType",
+		"item_types", request.ItemTypes, "location", request.Location)
 
 	res := gen.GetItemsByTypeResponse{}
 
-	results, err := g.uc.GetItemsByType(ctx, request.ItemTypes)
+	results, err := g.uc.GetItemsByType(
+		ctx,
+		request.ItemTypes,
+		shared.Location(request.Location).Name(),
+		menuTime(request.At),
+	)
 	if err != nil {
 		return nil, errors.Wrap(err, "productGRPCServer-GetItemsByType")
 	}
 
 	for _, item := range results {
 		res.Items = append(res.Items, &gen.ItemDto{
-			Type:  int32(item.Type),
-			Price: item.Price,
+			Type:       int32(item.Type),
+			Price:      item.Price,
+			Name:       item.Name,
+			ValidUntil: timestamp(item.ValidUntil),
 		})
 	}
 
 	return &res, nil
 }
+
+func (g *productGRPCServer) LookupItems(
+	ctx context.Context,
+	request *gen.LookupItemsRequest,
+) (*gen.LookupItemsResponse, error) {
+	slog.Info("gRPC client", "http_method", "POST", "http_name", "LookupItems",
+		"item_types", request.ItemTypes, "location", request.Location)
+
+	itemTypes := lo.Map(request.ItemTypes, func(t int32, _ int) int { return int(t) })
+
+	found, missing, err := g.uc.LookupItems(ctx, itemTypes, shared.Location(request.Location).Name(), menuTime(request.At))
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-LookupItems")
+	}
+
+	return &gen.LookupItemsResponse{
+		Items: lo.Map(found, func(item *domain.ItemDto, _ int) *gen.ItemDto {
+			return &gen.ItemDto{
+				Type:       int32(item.Type),
+				Price:      item.Price,
+				Name:       item.Name,
+				ValidUntil: timestamp(item.ValidUntil),
+			}
+		}),
+		NotFound: lo.Map(missing, func(m *domain.ItemLookupMiss, _ int) *gen.ItemNotFoundDto {
+			return &gen.ItemNotFoundDto{
+				Type:       int32(m.Type),
+				Name:       m.Name,
+				Reason:     m.Reason,
+				ValidUntil: timestamp(m.ValidUntil),
+			}
+		}),
+	}, nil
+}
+
+func (g *productGRPCServer) SearchItems(
+	ctx context.Context,
+	request *gen.SearchItemsRequest,
+) (*gen.SearchItemsResponse, error) {
+	slog.Info("gRPC client", "http_method", "GET", "http_name", "SearchItems",
+		"query", request.Query, "category", request.Category, "tags", request.Tags, "location", request.Location)
+
+	query := domain.SearchQuery{
+		Text:             request.Query,
+		Category:         request.Category,
+		Tags:             request.Tags,
+		ExcludeAllergens: request.ExcludeAllergens,
+		SortBy:           request.SortBy,
+		Descending:       request.Descending,
+		Page:             int(request.Page),
+		PageSize:         int(request.PageSize),
+	}
+
+	result, err := g.uc.SearchItems(ctx, query, shared.Location(request.Location).Name(), menuTime(request.At))
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-SearchItems")
+	}
+
+	return &gen.SearchItemsResponse{
+		Items:    lo.Map(result.Items, func(item *domain.ItemTypeDto, _ int) *gen.ItemTypeDto { return g.toItemTypeDto(item) }),
+		Total:    int32(result.Total),
+		Page:     int32(result.Page),
+		PageSize: int32(result.PageSize),
+	}, nil
+}
+
+func (g *productGRPCServer) UploadItemImage(
+	ctx context.Context,
+	request *gen.UploadItemImageRequest,
+) (*gen.ItemTypeDto, error) {
+	slog.Info("gRPC client", "http_method", "POST", "http_name", "UploadItemImage",
+		"name", request.Name, "content_type", request.ContentType, "size", len(request.Data))
+
+	item, err := g.uc.UploadItemImage(ctx, request.Name, request.ContentType, request.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-UploadItemImage")
+	}
+
+	return g.toItemTypeDto(item), nil
+}
+
+func (g *productGRPCServer) GetItemImage(
+	ctx context.Context,
+	request *gen.GetItemImageRequest,
+) (*httpbody.HttpBody, error) {
+	slog.Info("gRPC client", "http_method", "GET", "http_name", "GetItemImage",
+		"name", request.Name, "thumbnail", request.Thumbnail)
+
+	data, contentType, err := g.uc.GetItemImage(ctx, request.Name, request.Thumbnail)
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-GetItemImage")
+	}
+
+	return &httpbody.HttpBody{
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+func (g *productGRPCServer) ImportCatalog(
+	ctx context.Context,
+	request *gen.ImportCatalogRequest,
+) (*gen.ImportCatalogResponse, error) {
+	slog.Info("gRPC client", "http_method", "POST", "http_name", "ImportCatalog",
+		"format", request.Format, "dry_run", request.DryRun, "replace", request.Replace)
+
+	result, err := g.uc.ImportCatalog(ctx, request.Format, []byte(request.Data), request.DryRun, request.Replace)
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-ImportCatalog")
+	}
+
+	res := gen.ImportCatalogResponse{
+		Applied: result.Applied,
+		Errors: lo.Map(result.Errors, func(e *domain.CatalogRowError, _ int) *gen.CatalogRowErrorDto {
+			return &gen.CatalogRowErrorDto{
+				Row:     int32(e.Row),
+				Field:   e.Field,
+				Message: e.Message,
+			}
+		}),
+		Changes: lo.Map(result.Changes, func(c *domain.CatalogChange, _ int) *gen.CatalogChangeDto {
+			return &gen.CatalogChangeDto{
+				Action: c.Action,
+				Name:   c.Name,
+				Before: toCatalogItemDto(c.Before),
+				After:  toCatalogItemDto(c.After),
+			}
+		}),
+	}
+
+	return &res, nil
+}
+
+func (g *productGRPCServer) ExportCatalog(
+	ctx context.Context,
+	request *gen.ExportCatalogRequest,
+) (*httpbody.HttpBody, error) {
+	slog.Info("gRPC client", "http_method", "GET", "http_name", "ExportCatalog", "format", request.Format)
+
+	data, contentType, err := g.uc.ExportCatalog(ctx, request.Format)
+	if err != nil {
+		return nil, errors.Wrap(err, "productGRPCServer-ExportCatalog")
+	}
+
+	return &httpbody.HttpBody{
+		ContentType: contentType,
+		Data:        data,
+	}, nil
+}
+
+func toCatalogItemDto(item *domain.ItemTypeDto) *gen.CatalogItemDto {
+	if item == nil {
+		return nil
+	}
+
+	return &gen.CatalogItemDto{
+		Name:      item.Name,
+		Type:      int32(item.Type),
+		Sku:       item.SKU,
+		Price:     item.Price,
+		Station:   item.Station,
+		Image:     item.Image,
+		Available: item.Available,
+		Category:  item.Category,
+		Tags:      item.Tags,
+		Allergens: item.Allergens,
+	}
+}
+
+// toItemTypeDto resolves the stored image of the item to the URLs of GetItemImage behind the proxy.
+func (g *productGRPCServer) toItemTypeDto(item *domain.ItemTypeDto) *gen.ItemTypeDto {
+	dto := &gen.ItemTypeDto{
+		Name:      item.Name,
+		Type:      int32(item.Type),
+		Price:     item.Price,
+		Category:  item.Category,
+		Tags:      item.Tags,
+		Allergens: item.Allergens,
+	}
+
+	if item.Image != "" {
+		dto.Image = fmt.Sprintf("%s/v1/api/item-types/%s/image", g.cfg.Images.BaseURL, url.PathEscape(item.Name))
+		dto.Thumbnail = dto.Image + "?thumbnail=true"
+	}
+
+	return dto
+}
+
+// menuTime is the time the menu is asked for, now when the caller doesn't say.
+func menuTime(at *timestamppb.Timestamp) time.Time {
+	if at == nil {
+		return time.Now()
+	}
+
+	return at.AsTime()
+}
+
+// timestamp leaves a zero time unset.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
@@ -7,19 +7,90 @@ import (
 	"github.com/google/wire"
 	"github.com/thangchung/go-coffeeshop/cmd/product/config"
 	"github.com/thangchung/go-coffeeshop/internal/product/app/router"
+	"github.com/thangchung/go-coffeeshop/internal/product/infras"
 	"github.com/thangchung/go-coffeeshop/internal/product/infras/repo"
 	productsUC "github.com/thangchung/go-coffeeshop/internal/product/usecases/products"
+	"github.com/thangchung/go-coffeeshop/pkg/blob"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
+	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
 )
 
 func InitApp(
 	cfg *config.Config,
+	rabbitMQConnStr rabbitmq.RabbitMQConnStr,
+	grpcServer *grpc.Server,
+) (*App, func(), error) {
+	panic(wire.Build(
+		New,
+		brokerFunc,
+		messaging.PublisherSet,
+		infras.CatalogEventPublisherSet,
+		router.ProductGRPCServerSet,
+		repo.RepositorySet,
+		repo.MenuRepositorySet,
+		productsUC.UseCaseSet,
+		blobStoreFunc,
+		imageConfigFunc,
+		menuFileFunc,
+		catalogFileFunc,
+	))
+}
+
+// InitInProcessApp builds the app on a broker shared with the other services of the process.
+func InitInProcessApp(
+	cfg *config.Config,
+	broker messaging.Broker,
 	grpcServer *grpc.Server,
 ) (*App, error) {
 	panic(wire.Build(
 		New,
+		messaging.PublisherSet,
+		infras.CatalogEventPublisherSet,
 		router.ProductGRPCServerSet,
 		repo.RepositorySet,
+		repo.MenuRepositorySet,
 		productsUC.UseCaseSet,
+		blobStoreFunc,
+		imageConfigFunc,
+		menuFileFunc,
+		catalogFileFunc,
 	))
 }
+
+// brokerFunc falls back on a bus inside the process when RabbitMQ can't be reached, the catalog is served anyway.
+// The catalog changes then reach no counter, their caches only drop the items when the entries expire.
+func brokerFunc(url rabbitmq.RabbitMQConnStr) (messaging.Broker, func(), error) {
+	conn, err := rabbitmq.NewRabbitMQConn(url)
+	if err != nil {
+		slog.Warn("starting without RabbitMQ, the catalog changes are not published", "error", err)
+
+		bus := inmem.NewBus()
+
+		return bus, bus.Close, nil
+	}
+
+	return rabbitmq.NewBroker(conn), func() { conn.Close() }, nil
+}
+
+func blobStoreFunc(cfg *config.Config) (blob.Store, error) {
+	return blob.NewLocalStore(blob.StoreDir(cfg.Images.Dir))
+}
+
+func imageConfigFunc(cfg *config.Config) productsUC.ImageConfig {
+	return productsUC.ImageConfig{
+		ThumbnailWidth: cfg.Images.ThumbnailWidth,
+		MaxBytes:       cfg.Images.MaxBytes,
+		MaxPixels:      cfg.Images.MaxPixels,
+	}
+}
+
+func menuFileFunc(cfg *config.Config) repo.MenuFile {
+	return repo.MenuFile(cfg.Menu.SchedulesFile)
+}
+
+func catalogFileFunc(cfg *config.Config) repo.CatalogFile {
+	return repo.CatalogFile(cfg.Catalog.File)
+}
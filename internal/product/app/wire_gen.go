@@ -9,17 +9,117 @@ package app
 import (
 	"github.com/thangchung/go-coffeeshop/cmd/product/config"
 	"github.com/thangchung/go-coffeeshop/internal/product/app/router"
+	"github.com/thangchung/go-coffeeshop/internal/product/infras"
 	"github.com/thangchung/go-coffeeshop/internal/product/infras/repo"
 	"github.com/thangchung/go-coffeeshop/internal/product/usecases/products"
+	"github.com/thangchung/go-coffeeshop/pkg/blob"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq"
+	"golang.org/x/exp/slog"
 	"google.golang.org/grpc"
 )
 
 // Injectors from wire.go:
 
-func InitApp(cfg *config.Config, grpcServer *grpc.Server) (*App, error) {
-	productRepo := repo.NewOrderRepo()
-	useCase := products.NewService(productRepo)
-	productServiceServer := router.NewProductGRPCServer(grpcServer, useCase)
-	app := New(cfg, useCase, productServiceServer)
+func InitApp(cfg *config.Config, rabbitMQConnStr rabbitmq.RabbitMQConnStr, grpcServer *grpc.Server) (*App, func(), error) {
+	broker, cleanup, err := brokerFunc(rabbitMQConnStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	catalogEventPublisher := infras.NewCatalogEventPublisher(publisher)
+	catalogFile := catalogFileFunc(cfg)
+	productRepo, err := repo.NewOrderRepo(catalogFile)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	menuFile := menuFileFunc(cfg)
+	menuRepo, err := repo.NewMenuRepo(menuFile)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	store, err := blobStoreFunc(cfg)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	imageConfig := imageConfigFunc(cfg)
+	useCase := products.NewService(productRepo, menuRepo, catalogEventPublisher, store, imageConfig)
+	productServiceServer := router.NewProductGRPCServer(grpcServer, cfg, useCase)
+	app := New(cfg, broker, catalogEventPublisher, useCase, productServiceServer)
+	return app, func() {
+		cleanup()
+	}, nil
+}
+
+// InitInProcessApp builds the app on a broker shared with the other services of the process.
+func InitInProcessApp(cfg *config.Config, broker messaging.Broker, grpcServer *grpc.Server) (*App, error) {
+	publisher, err := messaging.NewPublisher(broker)
+	if err != nil {
+		return nil, err
+	}
+	catalogEventPublisher := infras.NewCatalogEventPublisher(publisher)
+	catalogFile := catalogFileFunc(cfg)
+	productRepo, err := repo.NewOrderRepo(catalogFile)
+	if err != nil {
+		return nil, err
+	}
+	menuFile := menuFileFunc(cfg)
+	menuRepo, err := repo.NewMenuRepo(menuFile)
+	if err != nil {
+		return nil, err
+	}
+	store, err := blobStoreFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	imageConfig := imageConfigFunc(cfg)
+	useCase := products.NewService(productRepo, menuRepo, catalogEventPublisher, store, imageConfig)
+	productServiceServer := router.NewProductGRPCServer(grpcServer, cfg, useCase)
+	app := New(cfg, broker, catalogEventPublisher, useCase, productServiceServer)
 	return app, nil
 }
+
+// wire.go:
+
+// brokerFunc falls back on a bus inside the process when RabbitMQ can't be reached, the catalog is served anyway.
+// The catalog changes then reach no counter, their caches only drop the items when the entries expire.
+func brokerFunc(url rabbitmq.RabbitMQConnStr) (messaging.Broker, func(), error) {
+	conn, err := rabbitmq.NewRabbitMQConn(url)
+	if err != nil {
+		slog.Warn("starting without RabbitMQ, the catalog changes are not published", "error", err)
+
+		bus := inmem.NewBus()
+
+		return bus, bus.Close, nil
+	}
+
+	return rabbitmq.NewBroker(conn), func() { conn.Close() }, nil
+}
+
+func blobStoreFunc(cfg *config.Config) (blob.Store, error) {
+	return blob.NewLocalStore(blob.StoreDir(cfg.Images.Dir))
+}
+
+func imageConfigFunc(cfg *config.Config) products.ImageConfig {
+	return products.ImageConfig{
+		ThumbnailWidth: cfg.Images.ThumbnailWidth,
+		MaxBytes:       cfg.Images.MaxBytes,
+		MaxPixels:      cfg.Images.MaxPixels,
+	}
+}
+
+func menuFileFunc(cfg *config.Config) repo.MenuFile {
+	return repo.MenuFile(cfg.Menu.SchedulesFile)
+}
+
+func catalogFileFunc(cfg *config.Config) repo.CatalogFile {
+	return repo.CatalogFile(cfg.Catalog.File)
+}
@@ -0,0 +1,516 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	CatalogFormatCSV  = "csv"
+	CatalogFormatJSON = "json"
+
+	StationBarista = "barista"
+	StationKitchen = "kitchen"
+
+	CategoryBeverages = "beverages"
+	CategoryFood      = "food"
+	CategorySeasonal  = "seasonal"
+
+	TagVegan        = "vegan"
+	TagGlutenFree   = "gluten-free"
+	TagContainsNuts = "contains-nuts"
+
+	CatalogChangeAdd    = "add"
+	CatalogChangeUpdate = "update"
+	CatalogChangeRemove = "remove"
+)
+
+var (
+	ErrUnknownCatalogFormat = errors.New("unknown catalog format")
+	ErrInvalidCatalog       = errors.New("invalid catalog")
+
+	catalogColumns         = []string{"name", "sku", "price", "station", "image", "available", "category", "tags", "allergens"}
+	requiredCatalogColumns = []string{"name", "sku", "price", "station"}
+
+	categories = []string{CategoryBeverages, CategoryFood, CategorySeasonal}
+)
+
+// catalogListSeparator separates the tags and allergens within a CSV cell.
+const catalogListSeparator = ";"
+
+// CatalogRow is one item of an imported catalog, Row is its line in the file (CSV) or position (JSON), from 1.
+type CatalogRow struct {
+	Row       int
+	Name      string
+	SKU       string
+	Price     float64
+	Station   string
+	Image     string
+	Available bool
+	Category  string
+	Tags      []string
+	Allergens []string
+}
+
+type CatalogRowError struct {
+	Row     int
+	Field   string
+	Message string
+}
+
+func (e *CatalogRowError) Error() string {
+	return fmt.Sprintf("row %d: %s: %s", e.Row, e.Field, e.Message)
+}
+
+type CatalogChange struct {
+	Action string
+	Name   string
+	Before *ItemTypeDto
+	After  *ItemTypeDto
+}
+
+type CatalogImportResult struct {
+	Applied bool
+	Errors  []*CatalogRowError
+	Changes []*CatalogChange
+}
+
+// catalogJSONRow is the JSON shape of a catalog item, available is optional and defaults to true.
+type catalogJSONRow struct {
+	Name      string   `json:"name"`
+	SKU       string   `json:"sku"`
+	Price     float64  `json:"price"`
+	Station   string   `json:"station"`
+	Image     string   `json:"image"`
+	Available *bool    `json:"available"`
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	Allergens []string `json:"allergens"`
+}
+
+// ParseCatalog decodes and validates a catalog file.
+// A file which can't be decoded at all is returned as an error, invalid rows are reported one by one.
+func ParseCatalog(format string, data []byte) ([]*CatalogRow, []*CatalogRowError, error) {
+	var (
+		rows []*CatalogRow
+		errs []*CatalogRowError
+		err  error
+	)
+
+	switch strings.ToLower(format) {
+	case CatalogFormatCSV:
+		rows, errs, err = parseCatalogCSV(data)
+	case CatalogFormatJSON:
+		rows, err = parseCatalogJSON(data)
+	default:
+		return nil, nil, errors.Wrapf(ErrUnknownCatalogFormat, "%q", format)
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, append(errs, validateCatalog(rows, errs)...), nil
+}
+
+func parseCatalogCSV(data []byte) ([]*CatalogRow, []*CatalogRowError, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.Wrapf(ErrInvalidCatalog, "csv header: %v", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range requiredCatalogColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, errors.Wrapf(ErrInvalidCatalog, "csv: missing column %q", name)
+		}
+	}
+
+	// a record can't be shorter than the header, csv.Reader rejects it with ErrFieldCount
+	value := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	// a missing column leaves the list nil, so the item keeps it, an empty cell clears it
+	list := func(record []string, name string) []string {
+		if _, ok := columns[name]; !ok {
+			return nil
+		}
+
+		return normalizeCatalogList(strings.Split(value(record, name), catalogListSeparator))
+	}
+
+	rows := make([]*CatalogRow, 0)
+	errs := make([]*CatalogRowError, 0)
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			errs = append(errs, &CatalogRowError{Row: line, Field: "row", Message: err.Error()})
+
+			continue
+		}
+
+		row := &CatalogRow{
+			Row:       line,
+			Name:      value(record, "name"),
+			SKU:       value(record, "sku"),
+			Station:   strings.ToLower(value(record, "station")),
+			Image:     value(record, "image"),
+			Category:  strings.ToLower(value(record, "category")),
+			Tags:      list(record, "tags"),
+			Allergens: list(record, "allergens"),
+		}
+
+		if row.Price, err = strconv.ParseFloat(value(record, "price"), 64); err != nil {
+			errs = append(errs, &CatalogRowError{Row: line, Field: "price", Message: "must be a number"})
+		}
+
+		row.Available = true
+		if available := value(record, "available"); available != "" {
+			if row.Available, err = strconv.ParseBool(available); err != nil {
+				errs = append(errs, &CatalogRowError{Row: line, Field: "available", Message: "must be true or false"})
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, errs, nil
+}
+
+func parseCatalogJSON(data []byte) ([]*CatalogRow, error) {
+	var items []catalogJSONRow
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, errors.Wrapf(ErrInvalidCatalog, "json: %v", err)
+	}
+
+	rows := make([]*CatalogRow, 0, len(items))
+
+	for i, item := range items {
+		available := true
+		if item.Available != nil {
+			available = *item.Available
+		}
+
+		rows = append(rows, &CatalogRow{
+			Row:       i + 1,
+			Name:      strings.TrimSpace(item.Name),
+			SKU:       strings.TrimSpace(item.SKU),
+			Price:     item.Price,
+			Station:   strings.ToLower(strings.TrimSpace(item.Station)),
+			Image:     strings.TrimSpace(item.Image),
+			Available: available,
+			Category:  strings.ToLower(strings.TrimSpace(item.Category)),
+			Tags:      normalizeCatalogList(item.Tags),
+			Allergens: normalizeCatalogList(item.Allergens),
+		})
+	}
+
+	return rows, nil
+}
+
+// normalizeCatalogList lower-cases and sorts the values and drops the empty and repeated ones, nil stays nil.
+func normalizeCatalogList(values []string) []string {
+	if values == nil {
+		return nil
+	}
+
+	results := make([]string, 0, len(values))
+
+	for _, v := range values {
+		if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+			results = append(results, v)
+		}
+	}
+
+	sort.Strings(results)
+
+	return lo.Uniq(results)
+}
+
+// validateCatalog checks the decoded rows, fields which already failed to decode are not reported twice.
+func validateCatalog(rows []*CatalogRow, decodeErrs []*CatalogRowError) []*CatalogRowError {
+	failed := make(map[CatalogRowError]bool, len(decodeErrs))
+	for _, e := range decodeErrs {
+		failed[CatalogRowError{Row: e.Row, Field: e.Field}] = true
+	}
+
+	errs := make([]*CatalogRowError, 0)
+	names := make(map[string]int, len(rows))
+	skus := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		if row.Name == "" {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "name", Message: "is required"})
+		} else if first, ok := names[row.Name]; ok {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "name", Message: fmt.Sprintf("duplicates row %d", first)})
+		} else {
+			names[row.Name] = row.Row
+		}
+
+		if row.SKU == "" {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "sku", Message: "is required"})
+		} else if first, ok := skus[row.SKU]; ok {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "sku", Message: fmt.Sprintf("duplicates row %d", first)})
+		} else {
+			skus[row.SKU] = row.Row
+		}
+
+		if row.Price <= 0 && !failed[CatalogRowError{Row: row.Row, Field: "price"}] {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "price", Message: "must be greater than 0"})
+		}
+
+		if row.Station != StationBarista && row.Station != StationKitchen {
+			errs = append(errs, &CatalogRowError{
+				Row:     row.Row,
+				Field:   "station",
+				Message: fmt.Sprintf("must be %s or %s", StationBarista, StationKitchen),
+			})
+		}
+
+		if row.Category != "" && !lo.Contains(categories, row.Category) {
+			errs = append(errs, &CatalogRowError{
+				Row:     row.Row,
+				Field:   "category",
+				Message: fmt.Sprintf("must be one of %s", strings.Join(categories, ", ")),
+			})
+		}
+	}
+
+	return errs
+}
+
+// MergeCatalog applies the imported rows to the current catalog.
+// Items are matched by name and keep their type, and their image, category, tags and allergens when the row has none.
+// New items get the next free type.
+// With replace, the items missing from the rows are removed.
+func MergeCatalog(current []*ItemTypeDto, rows []*CatalogRow, replace bool) []*ItemTypeDto {
+	byName := make(map[string]*ItemTypeDto, len(current))
+	nextType := 0
+
+	for _, item := range current {
+		byName[item.Name] = item
+
+		if item.Type >= nextType {
+			nextType = item.Type + 1
+		}
+	}
+
+	results := make([]*ItemTypeDto, 0, len(current)+len(rows))
+	imported := make(map[string]bool, len(rows))
+
+	for _, row := range rows {
+		imported[row.Name] = true
+
+		itemType, image, category, tags, allergens := nextType, row.Image, row.Category, row.Tags, row.Allergens
+		if existing, ok := byName[row.Name]; ok {
+			itemType = existing.Type
+
+			if image == "" {
+				image = existing.Image
+			}
+
+			if category == "" {
+				category = existing.Category
+			}
+
+			if tags == nil {
+				tags = existing.Tags
+			}
+
+			if allergens == nil {
+				allergens = existing.Allergens
+			}
+		} else {
+			nextType++
+		}
+
+		results = append(results, &ItemTypeDto{
+			Name:      row.Name,
+			Type:      itemType,
+			Price:     row.Price,
+			Image:     image,
+			SKU:       row.SKU,
+			Station:   row.Station,
+			Available: row.Available,
+			Category:  category,
+			Tags:      tags,
+			Allergens: allergens,
+		})
+	}
+
+	if !replace {
+		for _, item := range current {
+			if !imported[item.Name] {
+				copied := *item
+				results = append(results, &copied)
+			}
+		}
+	}
+
+	SortCatalog(results)
+
+	return results
+}
+
+// ValidateRetainedSKUs reports the rows whose SKU is already taken by an item the import keeps,
+// validateCatalog only compares the rows with each other.
+func ValidateRetainedSKUs(rows []*CatalogRow, next []*ItemTypeDto) []*CatalogRowError {
+	imported := lo.SliceToMap(rows, func(row *CatalogRow) (string, bool) {
+		return row.Name, true
+	})
+
+	retained := make(map[string]string, len(next))
+	for _, item := range next {
+		if !imported[item.Name] && item.SKU != "" {
+			retained[item.SKU] = item.Name
+		}
+	}
+
+	errs := make([]*CatalogRowError, 0)
+
+	for _, row := range rows {
+		if name, ok := retained[row.SKU]; ok {
+			errs = append(errs, &CatalogRowError{Row: row.Row, Field: "sku", Message: fmt.Sprintf("is already used by %s", name)})
+		}
+	}
+
+	return errs
+}
+
+// DiffCatalog lists what changes when the current catalog is replaced by next.
+func DiffCatalog(current, next []*ItemTypeDto) []*CatalogChange {
+	before := make(map[string]*ItemTypeDto, len(current))
+	for _, item := range current {
+		before[item.Name] = item
+	}
+
+	changes := make([]*CatalogChange, 0)
+
+	for _, item := range next {
+		old, ok := before[item.Name]
+		if !ok {
+			changes = append(changes, &CatalogChange{Action: CatalogChangeAdd, Name: item.Name, After: item})
+
+			continue
+		}
+
+		delete(before, item.Name)
+
+		if !sameItem(old, item) {
+			changes = append(changes, &CatalogChange{Action: CatalogChangeUpdate, Name: item.Name, Before: old, After: item})
+		}
+	}
+
+	for _, item := range current {
+		if _, ok := before[item.Name]; ok {
+			changes = append(changes, &CatalogChange{Action: CatalogChangeRemove, Name: item.Name, Before: item})
+		}
+	}
+
+	return changes
+}
+
+func sameItem(a, b *ItemTypeDto) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.Price == b.Price && a.Image == b.Image &&
+		a.SKU == b.SKU && a.Station == b.Station && a.Available == b.Available && a.Category == b.Category &&
+		slices.Equal(a.Tags, b.Tags) && slices.Equal(a.Allergens, b.Allergens)
+}
+
+// EncodeCatalog writes the catalog in the same shape ParseCatalog reads, so an export can be edited and imported back.
+func EncodeCatalog(format string, items []*ItemTypeDto) ([]byte, string, error) {
+	switch strings.ToLower(format) {
+	case CatalogFormatCSV, "":
+		var buf bytes.Buffer
+
+		writer := csv.NewWriter(&buf)
+		if err := writer.Write(catalogColumns); err != nil {
+			return nil, "", errors.Wrap(err, "csv.Write[header]")
+		}
+
+		for _, item := range items {
+			err := writer.Write([]string{
+				item.Name,
+				item.SKU,
+				strconv.FormatFloat(item.Price, 'f', -1, 64),
+				item.Station,
+				item.Image,
+				strconv.FormatBool(item.Available),
+				item.Category,
+				strings.Join(item.Tags, catalogListSeparator),
+				strings.Join(item.Allergens, catalogListSeparator),
+			})
+			if err != nil {
+				return nil, "", errors.Wrap(err, "csv.Write")
+			}
+		}
+
+		writer.Flush()
+
+		if err := writer.Error(); err != nil {
+			return nil, "", errors.Wrap(err, "csv.Flush")
+		}
+
+		return buf.Bytes(), "text/csv", nil
+	case CatalogFormatJSON:
+		rows := make([]catalogJSONRow, 0, len(items))
+
+		for _, item := range items {
+			available := item.Available
+			rows = append(rows, catalogJSONRow{
+				Name:      item.Name,
+				SKU:       item.SKU,
+				Price:     item.Price,
+				Station:   item.Station,
+				Image:     item.Image,
+				Available: &available,
+				Category:  item.Category,
+				Tags:      item.Tags,
+				Allergens: item.Allergens,
+			})
+		}
+
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return nil, "", errors.Wrap(err, "json.Marshal")
+		}
+
+		return data, "application/json", nil
+	default:
+		return nil, "", errors.Wrapf(ErrUnknownCatalogFormat, "%q", format)
+	}
+}
+
+// SortCatalog orders items by type, which is the order of the menu.
+func SortCatalog(items []*ItemTypeDto) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Type < items[j].Type
+	})
+}
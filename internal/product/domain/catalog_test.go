@@ -0,0 +1,112 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+func TestParseCatalogReportsRowErrors(t *testing.T) {
+	t.Parallel()
+
+	data := []byte("name,sku,price,station,available\n" +
+		"LATTE,CS-0005,4.75,barista,true\n" +
+		"MATCHA,CS-0005,abc,bar,maybe\n")
+
+	rows, errs, err := domain.ParseCatalog(domain.CatalogFormatCSV, data)
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Equal(t, []*domain.CatalogRowError{
+		{Row: 3, Field: "price", Message: "must be a number"},
+		{Row: 3, Field: "available", Message: "must be true or false"},
+		{Row: 3, Field: "sku", Message: "duplicates row 2"},
+		{Row: 3, Field: "station", Message: "must be barista or kitchen"},
+	}, errs)
+
+	_, _, err = domain.ParseCatalog("xlsx", data)
+	assert.ErrorIs(t, err, domain.ErrUnknownCatalogFormat)
+}
+
+func TestMergeAndDiffCatalog(t *testing.T) {
+	t.Parallel()
+
+	current := []*domain.ItemTypeDto{
+		{Name: "LATTE", Type: 5, Price: 4.5, Image: "img/LATTE.png", SKU: "CS-0005", Station: "barista", Available: true},
+		{Name: "MUFFIN", Type: 8, Price: 3, Image: "img/MUFFIN.png", SKU: "CS-0008", Station: "kitchen", Available: true},
+	}
+
+	rows, errs, err := domain.ParseCatalog(domain.CatalogFormatJSON, []byte(`[
+		{"name": "LATTE", "sku": "CS-0005", "price": 4.75, "station": "barista"},
+		{"name": "MATCHA", "sku": "CS-0010", "price": 5, "station": "barista", "available": false}
+	]`))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	next := domain.MergeCatalog(current, rows, true)
+	assert.Len(t, next, 2)
+	assert.Equal(t, "img/LATTE.png", next[0].Image)
+	assert.Equal(t, 9, next[1].Type)
+
+	changes := domain.DiffCatalog(current, next)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, domain.CatalogChangeUpdate, changes[0].Action)
+	assert.Equal(t, domain.CatalogChangeAdd, changes[1].Action)
+	assert.Equal(t, "MATCHA", changes[1].Name)
+	assert.Equal(t, domain.CatalogChangeRemove, changes[2].Action)
+	assert.Equal(t, "MUFFIN", changes[2].Name)
+
+	assert.Len(t, domain.MergeCatalog(current, rows, false), 3)
+}
+
+func TestValidateRetainedSKUs(t *testing.T) {
+	t.Parallel()
+
+	current := []*domain.ItemTypeDto{
+		{Name: "LATTE", Type: 5, Price: 4.5, SKU: "CS-0005", Station: "barista", Available: true},
+		{Name: "MUFFIN", Type: 8, Price: 3, SKU: "CS-0008", Station: "kitchen", Available: true},
+	}
+
+	rows, errs, err := domain.ParseCatalog(domain.CatalogFormatJSON, []byte(`[
+		{"name": "LATTE", "sku": "CS-0005", "price": 4.75, "station": "barista"},
+		{"name": "MATCHA", "sku": "CS-0008", "price": 5, "station": "barista"}
+	]`))
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+
+	// MUFFIN is kept by a merge, so its SKU can't be given to MATCHA
+	assert.Equal(t, []*domain.CatalogRowError{
+		{Row: 2, Field: "sku", Message: "is already used by MUFFIN"},
+	}, domain.ValidateRetainedSKUs(rows, domain.MergeCatalog(current, rows, false)))
+
+	// a replace drops MUFFIN, its SKU is free
+	assert.Empty(t, domain.ValidateRetainedSKUs(rows, domain.MergeCatalog(current, rows, true)))
+}
+
+func TestCatalogListsRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	current := []*domain.ItemTypeDto{
+		{Name: "MUFFIN", Type: 8, Price: 3, SKU: "CS-0008", Station: "kitchen", Available: true,
+			Category: domain.CategoryFood, Tags: []string{domain.TagContainsNuts}, Allergens: []string{"nuts"}},
+	}
+
+	data, _, err := domain.EncodeCatalog(domain.CatalogFormatCSV, current)
+	assert.NoError(t, err)
+
+	rows, errs, err := domain.ParseCatalog(domain.CatalogFormatCSV, data)
+	assert.NoError(t, err)
+	assert.Empty(t, errs)
+	assert.Empty(t, domain.DiffCatalog(current, domain.MergeCatalog(current, rows, true)))
+
+	// without the columns the item keeps its lists, an empty cell clears them
+	rows, _, _ = domain.ParseCatalog(domain.CatalogFormatCSV, []byte("name,sku,price,station\nMUFFIN,CS-0008,3,kitchen\n"))
+	assert.Equal(t, []string{"nuts"}, domain.MergeCatalog(current, rows, true)[0].Allergens)
+
+	rows, _, _ = domain.ParseCatalog(domain.CatalogFormatCSV, []byte("name,sku,price,station,tags\nMUFFIN,CS-0008,3,kitchen,\n"))
+	assert.Empty(t, domain.MergeCatalog(current, rows, true)[0].Tags)
+
+	_, errs, _ = domain.ParseCatalog(domain.CatalogFormatCSV, []byte("name,sku,price,station,category\nMUFFIN,CS-0008,3,kitchen,drinks\n"))
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "category", errs[0].Field)
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"path"
+	"strings"
+
+	// decoders of the accepted uploads
+	_ "image/gif"
+	_ "image/jpeg"
+
+	"github.com/pkg/errors"
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrItemNotFound     = errors.New("item not found")
+	ErrUnsupportedImage = errors.New("unsupported image")
+	ErrImageTooLarge    = errors.New("image too large")
+)
+
+// imageExtensions are the accepted upload content types and the extension they are stored with.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+// ImageKey is the blob key of the original image of an item.
+func ImageKey(name, contentType string) (string, error) {
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return "", errors.Wrapf(ErrUnsupportedImage, "content type %q", contentType)
+	}
+
+	return "items/" + name + ext, nil
+}
+
+// ThumbnailKey is the blob key of the thumbnail generated from the image stored at key.
+func ThumbnailKey(key string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + ".thumb.png"
+}
+
+// MakeThumbnail scales the image down to width, keeping its aspect ratio, and encodes it as PNG.
+// Images narrower than width are only re-encoded. Images of more than maxPixels are refused before they are
+// decoded, a few bytes of header may claim a size which takes gigabytes to decode into.
+func MakeThumbnail(data []byte, width, maxPixels int) ([]byte, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrUnsupportedImage, err.Error())
+	}
+
+	if pixels := int64(cfg.Width) * int64(cfg.Height); pixels > int64(maxPixels) {
+		return nil, errors.Wrapf(ErrImageTooLarge, "%dx%d pixels, at most %d", cfg.Width, cfg.Height, maxPixels)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(ErrUnsupportedImage, err.Error())
+	}
+
+	bounds := src.Bounds()
+	if bounds.Dx() > width {
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+		src = dst
+	}
+
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, src); err != nil {
+		return nil, errors.Wrap(err, "png.Encode")
+	}
+
+	return buf.Bytes(), nil
+}
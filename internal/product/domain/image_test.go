@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+
+	return buf.Bytes()
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	t.Parallel()
+
+	data, err := domain.MakeThumbnail(encodePNG(t, 320, 200), 160, 1<<20)
+	require.NoError(t, err)
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 160, cfg.Width)
+	assert.Equal(t, 100, cfg.Height)
+
+	_, err = domain.MakeThumbnail([]byte("not an image"), 160, 1<<20)
+	assert.ErrorIs(t, err, domain.ErrUnsupportedImage)
+}
+
+func TestMakeThumbnailRefusesImagesOfTooManyPixels(t *testing.T) {
+	t.Parallel()
+
+	// a 1x1 PNG whose header claims 100000x100000 pixels, which would take 40 GB to decode into
+	data := encodePNG(t, 1, 1)
+	ihdr := data[12:29]
+	binary.BigEndian.PutUint32(ihdr[4:8], 100000)
+	binary.BigEndian.PutUint32(ihdr[8:12], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(ihdr))
+
+	_, err := domain.MakeThumbnail(data, 160, 1<<24)
+	assert.ErrorIs(t, err, domain.ErrImageTooLarge)
+
+	_, err = domain.MakeThumbnail(encodePNG(t, 320, 200), 160, 320*200-1)
+	assert.ErrorIs(t, err, domain.ErrImageTooLarge)
+}
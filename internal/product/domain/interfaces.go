@@ -7,6 +7,14 @@ import (
 type (
 	ProductRepo interface {
 		GetAll(context.Context) ([]*ItemTypeDto, error)
-		GetByTypes(context.Context, []string) ([]*ItemDto, error)
+		// GetByName returns nil when there is no item with the name.
+		GetByName(context.Context, string) (*ItemTypeDto, error)
+		Update(context.Context, *ItemTypeDto) error
+		// ReplaceAll swaps the whole catalog at once, readers never see a partial import.
+		ReplaceAll(context.Context, []*ItemTypeDto) error
+	}
+
+	MenuRepo interface {
+		Get(context.Context) (*Menu, error)
 	}
 )
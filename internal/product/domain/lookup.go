@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// LookupReasonUnknown is an item type which is not in the catalog.
+	LookupReasonUnknown = "unknown"
+	// LookupReasonUnavailable is an item which is in the catalog but can't be ordered at the time.
+	LookupReasonUnavailable = "unavailable"
+)
+
+// ItemLookupMiss is a looked up item type which can't be ordered, Name is empty for unknown types.
+// ValidUntil is when an unavailable item may come on the menu, zero when it never does.
+type ItemLookupMiss struct {
+	Type       int
+	Name       string
+	Reason     string
+	ValidUntil time.Time
+}
+
+// LookupItems prices each of the item types once, in the order they are asked for.
+func LookupItems(items []*ItemTypeDto, itemTypes []int) ([]*ItemDto, []*ItemLookupMiss) {
+	byType := lo.KeyBy(items, func(item *ItemTypeDto) int {
+		return item.Type
+	})
+
+	found := make([]*ItemDto, 0, len(itemTypes))
+	missing := make([]*ItemLookupMiss, 0)
+
+	for _, itemType := range lo.Uniq(itemTypes) {
+		item, ok := byType[itemType]
+
+		switch {
+		case !ok:
+			missing = append(missing, &ItemLookupMiss{Type: itemType, Reason: LookupReasonUnknown})
+		case !item.Available:
+			missing = append(missing, &ItemLookupMiss{
+				Type:       itemType,
+				Name:       item.Name,
+				Reason:     LookupReasonUnavailable,
+				ValidUntil: item.ValidUntil,
+			})
+		default:
+			found = append(found, &ItemDto{Price: item.Price, Type: item.Type, Name: item.Name, ValidUntil: item.ValidUntil})
+		}
+	}
+
+	return found, missing
+}
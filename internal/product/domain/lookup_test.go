@@ -0,0 +1,24 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+func TestLookupItems(t *testing.T) {
+	t.Parallel()
+
+	items := []*domain.ItemTypeDto{
+		{Name: "LATTE", Type: 5, Price: 4.5, Available: true},
+		{Name: "CROISSANT", Type: 7, Price: 3.25, Available: false},
+	}
+
+	found, missing := domain.LookupItems(items, []int{5, 7, 42, 5})
+	assert.Equal(t, []*domain.ItemDto{{Type: 5, Price: 4.5, Name: "LATTE"}}, found)
+	assert.Equal(t, []*domain.ItemLookupMiss{
+		{Type: 7, Name: "CROISSANT", Reason: domain.LookupReasonUnavailable},
+		{Type: 42, Reason: domain.LookupReasonUnknown},
+	}, missing)
+}
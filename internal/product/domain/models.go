@@ -1,13 +1,27 @@
 package domain
 
+import "time"
+
 type ItemTypeDto struct {
-	Name  string  `json:"name"`
-	Type  int     `json:"type"`
-	Price float64 `json:"price"`
-	Image string  `json:"image"`
+	Name      string  `json:"name"`
+	Type      int     `json:"type"`
+	Price     float64 `json:"price"`
+	Image     string  `json:"image"`
+	SKU       string  `json:"sku"`
+	Station   string  `json:"station"`
+	Available bool    `json:"available"`
+	// Category is one of beverages, food or seasonal.
+	Category  string   `json:"category"`
+	Tags      []string `json:"tags"`
+	Allergens []string `json:"allergens"`
+	// ValidUntil is when the menu next changes the price or the availability of the item, zero when it never does.
+	ValidUntil time.Time `json:"-"`
 }
 
 type ItemDto struct {
 	Price float64 `json:"price"`
 	Type  int     `json:"type"`
+	Name  string  `json:"name"`
+	// ValidUntil is when the price next changes, zero when it never does.
+	ValidUntil time.Time `json:"-"`
 }
@@ -0,0 +1,261 @@
+package domain
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// ScheduleKindMenu limits the items to its windows, e.g. breakfast-only items.
+	ScheduleKindMenu = "menu"
+	// ScheduleKindPrice replaces the price of the items within its windows, e.g. weekend specials.
+	ScheduleKindPrice = "price"
+	// ScheduleKindDiscount takes a percentage off the price of the items within its windows, e.g. happy hours.
+	ScheduleKindDiscount = "discount"
+)
+
+var ErrInvalidSchedule = errors.New("invalid price schedule")
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// PriceSchedule applies to Items at Locations on Days between From and To ("HH:MM", local time of the location).
+// Empty Items, Locations or Days match all of them, a window with To before From runs past midnight.
+type PriceSchedule struct {
+	Name      string
+	Kind      string
+	Items     []string
+	Locations []string
+	Days      []string
+	From      string
+	To        string
+	Price     float64
+	Discount  float64
+
+	items     map[string]bool
+	locations map[string]bool
+	days      map[time.Weekday]bool
+	from, to  int
+}
+
+// Menu evaluates the price schedules in the time zone of each location, locations without one use UTC.
+type Menu struct {
+	schedules []*PriceSchedule
+	timezones map[string]*time.Location
+}
+
+// NewMenu validates the schedules, timezones maps a location name to an IANA time zone.
+func NewMenu(timezones map[string]string, schedules []*PriceSchedule) (*Menu, error) {
+	m := &Menu{
+		schedules: schedules,
+		timezones: make(map[string]*time.Location, len(timezones)),
+	}
+
+	for name, zone := range timezones {
+		tz, err := time.LoadLocation(zone)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidSchedule, "timezone of %s: %v", name, err)
+		}
+
+		m.timezones[strings.ToUpper(name)] = tz
+	}
+
+	for _, s := range schedules {
+		if err := s.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// Apply returns copies of the items as they are sold at the location at the given time.
+// Items outside all of their menu windows are unavailable, fixed prices go before discounts
+// and only the largest active discount is taken. ValidUntil of a copy is the next start or end
+// of a window of its schedules, so what is sold at that time may be cached until then.
+func (m *Menu) Apply(items []*ItemTypeDto, location string, at time.Time) []*ItemTypeDto {
+	location = strings.ToUpper(location)
+
+	tz, ok := m.timezones[location]
+	if !ok {
+		tz = time.UTC
+	}
+
+	local := at.In(tz)
+	results := make([]*ItemTypeDto, 0, len(items))
+
+	for _, item := range items {
+		copied := *item
+
+		var (
+			onMenu, inWindow bool
+			price            *PriceSchedule
+			discount         float64
+			validUntil       time.Time
+		)
+
+		for _, s := range m.schedules {
+			if !s.covers(item.Name, location) {
+				continue
+			}
+
+			active := s.activeAt(local)
+
+			if next := s.nextChange(local); validUntil.IsZero() || next.Before(validUntil) {
+				validUntil = next
+			}
+
+			switch s.Kind {
+			case ScheduleKindMenu:
+				onMenu = true
+				inWindow = inWindow || active
+			case ScheduleKindPrice:
+				if active && price == nil {
+					price = s
+				}
+			case ScheduleKindDiscount:
+				if active && s.Discount > discount {
+					discount = s.Discount
+				}
+			}
+		}
+
+		if onMenu && !inWindow {
+			copied.Available = false
+		}
+
+		if price != nil {
+			copied.Price = price.Price
+		}
+
+		if discount > 0 {
+			copied.Price = math.Round(copied.Price*(100-discount)) / 100
+		}
+
+		copied.ValidUntil = validUntil
+
+		results = append(results, &copied)
+	}
+
+	return results
+}
+
+func (s *PriceSchedule) compile() error {
+	invalid := func(format string, args ...interface{}) error {
+		return errors.Wrapf(ErrInvalidSchedule, "%s: "+format, append([]interface{}{s.Name}, args...)...)
+	}
+
+	switch s.Kind {
+	case ScheduleKindMenu:
+	case ScheduleKindPrice:
+		if s.Price <= 0 {
+			return invalid("price must be greater than 0")
+		}
+	case ScheduleKindDiscount:
+		if s.Discount <= 0 || s.Discount > 100 {
+			return invalid("discount must be a percentage between 0 and 100")
+		}
+	default:
+		return invalid("kind must be %s, %s or %s", ScheduleKindMenu, ScheduleKindPrice, ScheduleKindDiscount)
+	}
+
+	s.items = make(map[string]bool, len(s.Items))
+	for _, name := range s.Items {
+		s.items[strings.ToUpper(name)] = true
+	}
+
+	s.locations = make(map[string]bool, len(s.Locations))
+	for _, name := range s.Locations {
+		s.locations[strings.ToUpper(name)] = true
+	}
+
+	s.days = make(map[time.Weekday]bool, len(s.Days))
+	for _, name := range s.Days {
+		day, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return invalid("unknown day %q", name)
+		}
+
+		s.days[day] = true
+	}
+
+	var err error
+
+	if s.from, err = parseClock(s.From, 0); err != nil {
+		return invalid("from: %v", err)
+	}
+
+	if s.to, err = parseClock(s.To, 24*60); err != nil {
+		return invalid("to: %v", err)
+	}
+
+	return nil
+}
+
+func (s *PriceSchedule) covers(item, location string) bool {
+	return (len(s.items) == 0 || s.items[strings.ToUpper(item)]) &&
+		(len(s.locations) == 0 || s.locations[location])
+}
+
+// activeAt tells whether local falls in the window, a window past midnight belongs to the day it starts.
+func (s *PriceSchedule) activeAt(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	if s.from <= s.to {
+		return s.onDay(day) && minute >= s.from && minute < s.to
+	}
+
+	if minute >= s.from {
+		return s.onDay(day)
+	}
+
+	return minute < s.to && s.onDay((day+6)%7)
+}
+
+// nextChange is the next time after local the window starts or ends on any day, the days are left out
+// so a day the window doesn't run only costs a lookup which finds the same prices.
+func (s *PriceSchedule) nextChange(local time.Time) time.Time {
+	var next time.Time
+
+	for _, minute := range []int{s.from, s.to} {
+		at := time.Date(local.Year(), local.Month(), local.Day(), 0, minute, 0, 0, local.Location())
+		if !at.After(local) {
+			at = time.Date(local.Year(), local.Month(), local.Day()+1, 0, minute, 0, 0, local.Location())
+		}
+
+		if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+
+	return next
+}
+
+func (s *PriceSchedule) onDay(day time.Weekday) bool {
+	return len(s.days) == 0 || s.days[day]
+}
+
+// parseClock returns the minutes since midnight of an "HH:MM" time, or fallback when it is empty.
+func parseClock(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, errors.Errorf("%q is not HH:MM", value)
+	}
+
+	return clock.Hour()*60 + clock.Minute(), nil
+}
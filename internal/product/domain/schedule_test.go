@@ -0,0 +1,165 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+func newTestMenu(t *testing.T) *domain.Menu {
+	t.Helper()
+
+	menu, err := domain.NewMenu(
+		map[string]string{"ATLANTA": "America/New_York"},
+		[]*domain.PriceSchedule{
+			{Name: "breakfast", Kind: domain.ScheduleKindMenu, Items: []string{"CROISSANT"}, From: "06:00", To: "11:00"},
+			{Name: "weekend", Kind: domain.ScheduleKindPrice, Items: []string{"MUFFIN"}, Days: []string{"sat", "sun"}, Price: 2.5},
+			{
+				Name:      "happy-hour",
+				Kind:      domain.ScheduleKindDiscount,
+				Items:     []string{"LATTE", "MUFFIN"},
+				Locations: []string{"atlanta"},
+				Days:      []string{"mon", "tue", "wed", "thu", "fri", "sat"},
+				From:      "15:00",
+				To:        "17:00",
+				Discount:  20,
+			},
+		},
+	)
+	require.NoError(t, err)
+
+	return menu
+}
+
+func menuItems() []*domain.ItemTypeDto {
+	return []*domain.ItemTypeDto{
+		{Name: "LATTE", Type: 5, Price: 4.5, Available: true},
+		{Name: "CROISSANT", Type: 7, Price: 3.25, Available: true},
+		{Name: "MUFFIN", Type: 8, Price: 3, Available: true},
+	}
+}
+
+func TestMenuApply(t *testing.T) {
+	t.Parallel()
+
+	menu := newTestMenu(t)
+
+	// 2022-07-04 is a Monday, 19:30 UTC is 15:30 in Atlanta
+	cases := []struct {
+		name      string
+		location  string
+		at        time.Time
+		prices    []float64
+		available []bool
+	}{
+		{
+			name:      "breakfast",
+			location:  "ATLANTA",
+			at:        time.Date(2022, 7, 4, 12, 0, 0, 0, time.UTC),
+			prices:    []float64{4.5, 3.25, 3},
+			available: []bool{true, true, true},
+		},
+		{
+			name:      "happy hour in the time zone of the location",
+			location:  "ATLANTA",
+			at:        time.Date(2022, 7, 4, 19, 30, 0, 0, time.UTC),
+			prices:    []float64{3.6, 3.25, 2.4},
+			available: []bool{true, false, true},
+		},
+		{
+			name:      "no happy hour at other locations",
+			location:  "CHARLOTTE",
+			at:        time.Date(2022, 7, 4, 15, 30, 0, 0, time.UTC),
+			prices:    []float64{4.5, 3.25, 3},
+			available: []bool{true, false, true},
+		},
+		{
+			name:      "weekend special with happy hour on top",
+			location:  "ATLANTA",
+			at:        time.Date(2022, 7, 9, 20, 0, 0, 0, time.UTC),
+			prices:    []float64{3.6, 3.25, 2},
+			available: []bool{true, false, true},
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			items := menuItems()
+			results := menu.Apply(items, c.location, c.at)
+
+			for i, item := range results {
+				assert.Equal(t, c.prices[i], item.Price, item.Name)
+				assert.Equal(t, c.available[i], item.Available, item.Name)
+			}
+
+			// the catalog itself is left alone
+			assert.Equal(t, menuItems(), items)
+		})
+	}
+}
+
+func TestMenuApplyValidUntil(t *testing.T) {
+	t.Parallel()
+
+	menu := newTestMenu(t)
+
+	// 08:00 on a Monday in Atlanta, breakfast ends at 11:00 and the happy hour starts at 15:00
+	results := menu.Apply(menuItems(), "ATLANTA", time.Date(2022, 7, 4, 12, 0, 0, 0, time.UTC))
+
+	assert.True(t, results[0].ValidUntil.Equal(time.Date(2022, 7, 4, 19, 0, 0, 0, time.UTC)))
+	assert.True(t, results[1].ValidUntil.Equal(time.Date(2022, 7, 4, 15, 0, 0, 0, time.UTC)))
+	assert.True(t, results[2].ValidUntil.Equal(time.Date(2022, 7, 4, 19, 0, 0, 0, time.UTC)))
+
+	// at the end of the breakfast the next change is the breakfast of the next day
+	results = menu.Apply(menuItems(), "ATLANTA", time.Date(2022, 7, 4, 15, 0, 0, 0, time.UTC))
+	assert.True(t, results[1].ValidUntil.Equal(time.Date(2022, 7, 5, 10, 0, 0, 0, time.UTC)))
+
+	// no schedule changes a latte at other locations
+	results = menu.Apply(menuItems(), "CHARLOTTE", time.Date(2022, 7, 4, 12, 0, 0, 0, time.UTC))
+	assert.True(t, results[0].ValidUntil.IsZero())
+}
+
+func TestMenuWindowPastMidnight(t *testing.T) {
+	t.Parallel()
+
+	menu, err := domain.NewMenu(nil, []*domain.PriceSchedule{
+		{Name: "late", Kind: domain.ScheduleKindMenu, Items: []string{"LATTE"}, Days: []string{"fri"}, From: "22:00", To: "02:00"},
+	})
+	require.NoError(t, err)
+
+	// 2022-07-08 is a Friday
+	for at, available := range map[time.Time]bool{
+		time.Date(2022, 7, 8, 23, 0, 0, 0, time.UTC): true,
+		time.Date(2022, 7, 9, 1, 0, 0, 0, time.UTC):  true,
+		time.Date(2022, 7, 9, 23, 0, 0, 0, time.UTC): false,
+		time.Date(2022, 7, 8, 1, 0, 0, 0, time.UTC):  false,
+	} {
+		results := menu.Apply(menuItems(), "ATLANTA", at)
+		assert.Equal(t, available, results[0].Available, at.String())
+	}
+}
+
+func TestNewMenuRejectsInvalidSchedules(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []*domain.PriceSchedule{
+		{Name: "kind", Kind: "free"},
+		{Name: "price", Kind: domain.ScheduleKindPrice},
+		{Name: "discount", Kind: domain.ScheduleKindDiscount, Discount: 120},
+		{Name: "day", Kind: domain.ScheduleKindMenu, Days: []string{"someday"}},
+		{Name: "clock", Kind: domain.ScheduleKindMenu, From: "7am"},
+	} {
+		_, err := domain.NewMenu(nil, []*domain.PriceSchedule{s})
+		assert.ErrorIs(t, err, domain.ErrInvalidSchedule, s.Name)
+	}
+
+	_, err := domain.NewMenu(map[string]string{"ATLANTA": "Mars/Olympus"}, nil)
+	assert.ErrorIs(t, err, domain.ErrInvalidSchedule)
+}
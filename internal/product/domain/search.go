@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+const (
+	SearchSortType  = "type"
+	SearchSortName  = "name"
+	SearchSortPrice = "price"
+
+	DefaultSearchPageSize = 20
+	MaxSearchPageSize     = 100
+)
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchQuery matches the items which contain every word of Text (in their name, SKU, category or tags),
+// are in Category, have all the Tags and none of the ExcludeAllergens. Empty fields match everything.
+type SearchQuery struct {
+	Text             string
+	Category         string
+	Tags             []string
+	ExcludeAllergens []string
+	// SortBy is type (the menu order), name or price, ties are broken by type so pages are stable.
+	SortBy     string
+	Descending bool
+	// Page counts from 1.
+	Page     int
+	PageSize int
+}
+
+type SearchResult struct {
+	Items    []*ItemTypeDto
+	Total    int
+	Page     int
+	PageSize int
+}
+
+func SearchItems(items []*ItemTypeDto, q SearchQuery) (*SearchResult, error) {
+	if q.Category != "" && !lo.Contains(categories, strings.ToLower(q.Category)) {
+		return nil, errors.Wrapf(ErrInvalidSearch, "category must be one of %s", strings.Join(categories, ", "))
+	}
+
+	less, err := searchOrder(q.SortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Page < 0 || q.PageSize < 0 {
+		return nil, errors.Wrap(ErrInvalidSearch, "page and page size can't be negative")
+	}
+
+	if q.Page == 0 {
+		q.Page = 1
+	}
+
+	if q.PageSize == 0 {
+		q.PageSize = DefaultSearchPageSize
+	}
+
+	if q.PageSize > MaxSearchPageSize {
+		q.PageSize = MaxSearchPageSize
+	}
+
+	words := strings.Fields(strings.ToLower(q.Text))
+	tags := normalizeCatalogList(q.Tags)
+	allergens := normalizeCatalogList(q.ExcludeAllergens)
+
+	matches := lo.Filter(items, func(item *ItemTypeDto, _ int) bool {
+		if q.Category != "" && !strings.EqualFold(item.Category, q.Category) {
+			return false
+		}
+
+		if !lo.Every(item.Tags, tags) || lo.Some(item.Allergens, allergens) {
+			return false
+		}
+
+		text := searchText(item)
+
+		return lo.EveryBy(words, func(word string) bool {
+			return strings.Contains(text, word)
+		})
+	})
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if q.Descending {
+			a, b = b, a
+		}
+
+		if less(a, b) {
+			return true
+		}
+
+		if less(b, a) {
+			return false
+		}
+
+		return matches[i].Type < matches[j].Type
+	})
+
+	from := lo.Min([]int{(q.Page - 1) * q.PageSize, len(matches)})
+	to := lo.Min([]int{from + q.PageSize, len(matches)})
+
+	return &SearchResult{
+		Items:    matches[from:to],
+		Total:    len(matches),
+		Page:     q.Page,
+		PageSize: q.PageSize,
+	}, nil
+}
+
+func searchOrder(sortBy string) (func(a, b *ItemTypeDto) bool, error) {
+	switch strings.ToLower(sortBy) {
+	case SearchSortType, "":
+		return func(a, b *ItemTypeDto) bool { return a.Type < b.Type }, nil
+	case SearchSortName:
+		return func(a, b *ItemTypeDto) bool { return a.Name < b.Name }, nil
+	case SearchSortPrice:
+		return func(a, b *ItemTypeDto) bool { return a.Price < b.Price }, nil
+	default:
+		return nil, errors.Wrapf(ErrInvalidSearch, "sort by must be %s, %s or %s", SearchSortType, SearchSortName, SearchSortPrice)
+	}
+}
+
+// searchText is what the words of a search are looked up in, "coffee black" finds COFFEE_BLACK.
+func searchText(item *ItemTypeDto) string {
+	parts := append([]string{strings.ReplaceAll(item.Name, "_", " "), item.SKU, item.Category}, item.Tags...)
+
+	return strings.ToLower(strings.Join(parts, " "))
+}
@@ -0,0 +1,68 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+func searchItems() []*domain.ItemTypeDto {
+	return []*domain.ItemTypeDto{
+		{Name: "COFFEE_BLACK", Type: 1, Price: 3, SKU: "CS-0001", Category: domain.CategoryBeverages,
+			Tags: []string{domain.TagGlutenFree, domain.TagVegan}},
+		{Name: "LATTE", Type: 5, Price: 4.5, SKU: "CS-0005", Category: domain.CategoryBeverages,
+			Tags: []string{domain.TagGlutenFree}, Allergens: []string{"milk"}},
+		{Name: "CROISSANT", Type: 7, Price: 3.25, SKU: "CS-0007", Category: domain.CategoryFood,
+			Allergens: []string{"eggs", "gluten", "milk"}},
+		{Name: "MUFFIN", Type: 8, Price: 3, SKU: "CS-0008", Category: domain.CategoryFood,
+			Tags: []string{domain.TagContainsNuts}, Allergens: []string{"nuts"}},
+	}
+}
+
+func searchNames(t *testing.T, q domain.SearchQuery) []string {
+	t.Helper()
+
+	result, err := domain.SearchItems(searchItems(), q)
+	assert.NoError(t, err)
+
+	return lo.Map(result.Items, func(item *domain.ItemTypeDto, _ int) string { return item.Name })
+}
+
+func TestSearchItems(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"COFFEE_BLACK"}, searchNames(t, domain.SearchQuery{Text: "coffee black"}))
+	assert.Equal(t, []string{"CROISSANT", "MUFFIN"}, searchNames(t, domain.SearchQuery{Category: "food"}))
+	assert.Equal(t, []string{"COFFEE_BLACK"}, searchNames(t, domain.SearchQuery{Tags: []string{"Vegan", "gluten-free"}}))
+	assert.Equal(t, []string{"COFFEE_BLACK", "MUFFIN"}, searchNames(t, domain.SearchQuery{ExcludeAllergens: []string{"milk"}}))
+
+	// equal prices keep the menu order either way
+	assert.Equal(t, []string{"COFFEE_BLACK", "MUFFIN", "CROISSANT", "LATTE"}, searchNames(t, domain.SearchQuery{SortBy: "price"}))
+	assert.Equal(t, []string{"LATTE", "CROISSANT", "COFFEE_BLACK", "MUFFIN"},
+		searchNames(t, domain.SearchQuery{SortBy: "price", Descending: true}))
+
+	result, err := domain.SearchItems(searchItems(), domain.SearchQuery{SortBy: "name", Page: 2, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, result.Total)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "MUFFIN", result.Items[0].Name)
+
+	result, err = domain.SearchItems(searchItems(), domain.SearchQuery{Page: 3, PageSize: 3})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Items)
+}
+
+func TestSearchItemsRejectsInvalidQueries(t *testing.T) {
+	t.Parallel()
+
+	for _, q := range []domain.SearchQuery{
+		{Category: "drinks"},
+		{SortBy: "popularity"},
+		{Page: -1},
+	} {
+		_, err := domain.SearchItems(searchItems(), q)
+		assert.ErrorIs(t, err, domain.ErrInvalidSearch)
+	}
+}
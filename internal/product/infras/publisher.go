@@ -0,0 +1,29 @@
+package infras
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/thangchung/go-coffeeshop/internal/product/usecases/products"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+)
+
+var CatalogEventPublisherSet = wire.NewSet(NewCatalogEventPublisher)
+
+type catalogEventPublisher struct {
+	pub messaging.Publisher
+}
+
+func NewCatalogEventPublisher(pub messaging.Publisher) products.CatalogEventPublisher {
+	return &catalogEventPublisher{
+		pub: pub,
+	}
+}
+
+func (p *catalogEventPublisher) Configure(opts ...messaging.Option) {
+	p.pub.Configure(opts...)
+}
+
+func (p *catalogEventPublisher) Publish(ctx context.Context, body []byte, contentType string) error {
+	return p.pub.Publish(ctx, body, contentType)
+}
@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+)
+
+var _ domain.MenuRepo = (*menuFileRepo)(nil)
+
+var MenuRepositorySet = wire.NewSet(NewMenuRepo)
+
+// MenuFile is the YAML file with the price schedules, without it items are sold at their catalog price all day.
+type MenuFile string
+
+type menuFileRepo struct {
+	menu *domain.Menu
+}
+
+type menuFile struct {
+	Timezones map[string]string `yaml:"timezones"`
+	Schedules []struct {
+		Name      string   `yaml:"name"`
+		Kind      string   `yaml:"kind"`
+		Items     []string `yaml:"items"`
+		Locations []string `yaml:"locations"`
+		Days      []string `yaml:"days"`
+		From      string   `yaml:"from"`
+		To        string   `yaml:"to"`
+		Price     float64  `yaml:"price"`
+		Discount  float64  `yaml:"discount"`
+	} `yaml:"schedules"`
+}
+
+// NewMenuRepo loads the schedules once, a broken file stops the service from starting.
+func NewMenuRepo(file MenuFile) (domain.MenuRepo, error) {
+	content := menuFile{}
+
+	if file != "" {
+		if err := cleanenv.ReadConfig(string(file), &content); err != nil {
+			return nil, errors.Wrapf(err, "cleanenv.ReadConfig(%q)", file)
+		}
+	}
+
+	schedules := make([]*domain.PriceSchedule, 0, len(content.Schedules))
+	for _, s := range content.Schedules {
+		schedules = append(schedules, &domain.PriceSchedule{
+			Name:      s.Name,
+			Kind:      s.Kind,
+			Items:     s.Items,
+			Locations: s.Locations,
+			Days:      s.Days,
+			From:      s.From,
+			To:        s.To,
+			Price:     s.Price,
+			Discount:  s.Discount,
+		})
+	}
+
+	menu, err := domain.NewMenu(content.Timezones, schedules)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.NewMenu")
+	}
+
+	return &menuFileRepo{menu: menu}, nil
+}
+
+func (m *menuFileRepo) Get(ctx context.Context) (*domain.Menu, error) {
+	return m.menu, nil
+}
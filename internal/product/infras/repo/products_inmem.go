@@ -2,8 +2,13 @@ package repo
 
 import (
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
 
 	"github.com/google/wire"
+	"github.com/pkg/errors"
 	"github.com/thangchung/go-coffeeshop/internal/product/domain"
 )
 
@@ -11,104 +16,277 @@ var _ domain.ProductRepo = (*productInMemRepo)(nil)
 
 var RepositorySet = wire.NewSet(NewOrderRepo)
 
+// CatalogFile is the JSON file the catalog is saved to on every change and loaded from on start.
+// Without it the imports and the uploaded images are lost when the service restarts.
+type CatalogFile string
+
 type productInMemRepo struct {
+	mu        sync.RWMutex
+	file      CatalogFile
 	itemTypes map[string]*domain.ItemTypeDto
 }
 
-func NewOrderRepo() domain.ProductRepo {
-	return &productInMemRepo{
+// NewOrderRepo starts from the saved catalog, or from the seeded one when nothing has been saved yet.
+func NewOrderRepo(file CatalogFile) (domain.ProductRepo, error) {
+	repo := &productInMemRepo{
+		file: file,
 		itemTypes: map[string]*domain.ItemTypeDto{
 			"CAPPUCCINO": {
-				Name:  "CAPPUCCINO",
-				Type:  0,
-				Price: 4.5,
-				Image: "img/CAPPUCCINO.png",
+				Name:      "CAPPUCCINO",
+				Type:      0,
+				Price:     4.5,
+				Image:     "items/CAPPUCCINO.png",
+				SKU:       "CS-0000",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree},
+				Allergens: []string{"milk"},
 			},
 			"COFFEE_BLACK": {
-				Name:  "COFFEE_BLACK",
-				Type:  1,
-				Price: 3,
-				Image: "img/COFFEE_BLACK.png",
+				Name:      "COFFEE_BLACK",
+				Type:      1,
+				Price:     3,
+				Image:     "items/COFFEE_BLACK.png",
+				SKU:       "CS-0001",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree, domain.TagVegan},
+				Allergens: []string{},
 			},
 			"COFFEE_WITH_ROOM": {
-				Name:  "COFFEE_WITH_ROOM",
-				Type:  2,
-				Price: 3,
-				Image: "img/COFFEE_WITH_ROOM.png",
+				Name:      "COFFEE_WITH_ROOM",
+				Type:      2,
+				Price:     3,
+				Image:     "items/COFFEE_WITH_ROOM.png",
+				SKU:       "CS-0002",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree},
+				Allergens: []string{"milk"},
 			},
 			"ESPRESSO": {
-				Name:  "ESPRESSO",
-				Type:  3,
-				Price: 3.5,
-				Image: "img/ESPRESSO.png",
+				Name:      "ESPRESSO",
+				Type:      3,
+				Price:     3.5,
+				Image:     "items/ESPRESSO.png",
+				SKU:       "CS-0003",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree, domain.TagVegan},
+				Allergens: []string{},
 			},
 			"ESPRESSO_DOUBLE": {
-				Name:  "ESPRESSO_DOUBLE",
-				Type:  4,
-				Price: 4.5,
-				Image: "img/ESPRESSO_DOUBLE.png",
+				Name:      "ESPRESSO_DOUBLE",
+				Type:      4,
+				Price:     4.5,
+				Image:     "items/ESPRESSO_DOUBLE.png",
+				SKU:       "CS-0004",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree, domain.TagVegan},
+				Allergens: []string{},
 			},
 			"LATTE": {
-				Name:  "LATTE",
-				Type:  5,
-				Price: 4.5,
-				Image: "img/LATTE.png",
+				Name:      "LATTE",
+				Type:      5,
+				Price:     4.5,
+				Image:     "items/LATTE.png",
+				SKU:       "CS-0005",
+				Station:   domain.StationBarista,
+				Available: true,
+				Category:  domain.CategoryBeverages,
+				Tags:      []string{domain.TagGlutenFree},
+				Allergens: []string{"milk"},
 			},
 			"CAKEPOP": {
-				Name:  "CAKEPOP",
-				Type:  6,
-				Price: 2.5,
-				Image: "img/CAKEPOP.png",
+				Name:      "CAKEPOP",
+				Type:      6,
+				Price:     2.5,
+				Image:     "items/CAKEPOP.png",
+				SKU:       "CS-0006",
+				Station:   domain.StationKitchen,
+				Available: true,
+				Category:  domain.CategorySeasonal,
+				Tags:      []string{},
+				Allergens: []string{"eggs", "gluten", "milk"},
 			},
 			"CROISSANT": {
-				Name:  "CROISSANT",
-				Type:  7,
-				Price: 3.25,
-				Image: "img/CROISSANT.png",
+				Name:      "CROISSANT",
+				Type:      7,
+				Price:     3.25,
+				Image:     "items/CROISSANT.png",
+				SKU:       "CS-0007",
+				Station:   domain.StationKitchen,
+				Available: true,
+				Category:  domain.CategoryFood,
+				Tags:      []string{},
+				Allergens: []string{"eggs", "gluten", "milk"},
 			},
 			"MUFFIN": {
-				Name:  "MUFFIN",
-				Type:  8,
-				Price: 3,
-				Image: "img/MUFFIN.png",
+				Name:      "MUFFIN",
+				Type:      8,
+				Price:     3,
+				Image:     "items/MUFFIN.png",
+				SKU:       "CS-0008",
+				Station:   domain.StationKitchen,
+				Available: true,
+				Category:  domain.CategoryFood,
+				Tags:      []string{domain.TagContainsNuts},
+				Allergens: []string{"eggs", "gluten", "milk", "nuts"},
 			},
 			"CROISSANT_CHOCOLATE": {
-				Name:  "CROISSANT_CHOCOLATE",
-				Type:  9,
-				Price: 3.5,
-				Image: "img/CROISSANT_CHOCOLATE.png",
+				Name:      "CROISSANT_CHOCOLATE",
+				Type:      9,
+				Price:     3.5,
+				Image:     "items/CROISSANT_CHOCOLATE.png",
+				SKU:       "CS-0009",
+				Station:   domain.StationKitchen,
+				Available: true,
+				Category:  domain.CategoryFood,
+				Tags:      []string{},
+				Allergens: []string{"eggs", "gluten", "milk", "soy"},
 			},
 		},
 	}
+
+	if file == "" {
+		return repo, nil
+	}
+
+	data, err := os.ReadFile(string(file))
+	if errors.Is(err, os.ErrNotExist) {
+		return repo, nil
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "os.ReadFile(%q)", file)
+	}
+
+	var items []*domain.ItemTypeDto
+	if err = json.Unmarshal(data, &items); err != nil {
+		return nil, errors.Wrapf(err, "json.Unmarshal(%q)", file)
+	}
+
+	repo.itemTypes = byName(items)
+
+	return repo, nil
 }
 
 func (p *productInMemRepo) GetAll(ctx context.Context) ([]*domain.ItemTypeDto, error) {
-	results := make([]*domain.ItemTypeDto, 0)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	results := make([]*domain.ItemTypeDto, 0, len(p.itemTypes))
 
 	for _, v := range p.itemTypes {
-		results = append(results, &domain.ItemTypeDto{
-			Name:  v.Name,
-			Type:  v.Type,
-			Price: v.Price,
-			Image: v.Image,
-		})
+		copied := *v
+		results = append(results, &copied)
 	}
 
+	domain.SortCatalog(results)
+
 	return results, nil
 }
 
-func (p *productInMemRepo) GetByTypes(ctx context.Context, itemTypes []string) ([]*domain.ItemDto, error) {
-	results := make([]*domain.ItemDto, 0)
+func (p *productInMemRepo) GetByName(ctx context.Context, name string) (*domain.ItemTypeDto, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	item, ok := p.itemTypes[name]
+	if !ok {
+		return nil, nil
+	}
+
+	copied := *item
+
+	return &copied, nil
+}
+
+func (p *productInMemRepo) Update(ctx context.Context, item *domain.ItemTypeDto) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.itemTypes[item.Name]; !ok {
+		return errors.Wrapf(domain.ErrItemNotFound, "%q", item.Name)
+	}
+
+	itemTypes := make(map[string]*domain.ItemTypeDto, len(p.itemTypes))
+	for name, v := range p.itemTypes {
+		itemTypes[name] = v
+	}
+
+	copied := *item
+	itemTypes[item.Name] = &copied
+
+	return p.save(itemTypes)
+}
 
-	for _, itemType := range itemTypes {
-		item := p.itemTypes[itemType]
-		if item.Name != "" {
-			results = append(results, &domain.ItemDto{
-				Price: item.Price,
-				Type:  item.Type,
-			})
+func (p *productInMemRepo) ReplaceAll(ctx context.Context, items []*domain.ItemTypeDto) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.save(byName(items))
+}
+
+// save writes the catalog to the file before the readers see it, so a failed write changes nothing.
+func (p *productInMemRepo) save(itemTypes map[string]*domain.ItemTypeDto) error {
+	if p.file != "" {
+		items := make([]*domain.ItemTypeDto, 0, len(itemTypes))
+		for _, item := range itemTypes {
+			items = append(items, item)
+		}
+
+		domain.SortCatalog(items)
+
+		data, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return errors.Wrap(err, "json.Marshal")
+		}
+
+		if err = writeFile(string(p.file), data); err != nil {
+			return err
 		}
 	}
 
-	return results, nil
+	p.itemTypes = itemTypes
+
+	return nil
+}
+
+func byName(items []*domain.ItemTypeDto) map[string]*domain.ItemTypeDto {
+	itemTypes := make(map[string]*domain.ItemTypeDto, len(items))
+
+	for _, item := range items {
+		copied := *item
+		itemTypes[item.Name] = &copied
+	}
+
+	return itemTypes
+}
+
+// writeFile replaces file at once, a crash in the middle leaves the previous catalog.
+func writeFile(file string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "os.CreateTemp")
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return errors.Wrap(err, "file.Write")
+	}
+
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "file.Close")
+	}
+
+	return errors.Wrap(os.Rename(tmp.Name(), file), "os.Rename")
 }
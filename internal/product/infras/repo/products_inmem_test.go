@@ -0,0 +1,53 @@
+package repo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+	"github.com/thangchung/go-coffeeshop/internal/product/infras/repo"
+)
+
+func TestCatalogFileKeepsTheChangesAcrossRestarts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	file := repo.CatalogFile(filepath.Join(t.TempDir(), "catalog.json"))
+
+	products, err := repo.NewOrderRepo(file)
+	require.NoError(t, err)
+
+	// nothing saved yet, the service starts from the seeded catalog
+	seeded, err := products.GetAll(ctx)
+	require.NoError(t, err)
+	require.NotEmpty(t, seeded)
+
+	matcha := &domain.ItemTypeDto{Name: "MATCHA", Type: 10, Price: 5, SKU: "CS-0010", Station: domain.StationBarista, Available: true}
+	require.NoError(t, products.ReplaceAll(ctx, []*domain.ItemTypeDto{matcha}))
+
+	matcha.Image = "items/MATCHA.png"
+	require.NoError(t, products.Update(ctx, matcha))
+
+	restarted, err := repo.NewOrderRepo(file)
+	require.NoError(t, err)
+
+	items, err := restarted.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.ItemTypeDto{matcha}, items)
+}
+
+func TestCatalogWithoutFileStaysInMemory(t *testing.T) {
+	t.Parallel()
+
+	products, err := repo.NewOrderRepo("")
+	require.NoError(t, err)
+
+	require.NoError(t, products.ReplaceAll(context.Background(), nil))
+
+	items, err := products.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -2,11 +2,39 @@ package products
 
 import (
 	"context"
+	"time"
 
 	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 )
 
-type UseCase interface {
-	GetItemTypes(context.Context) ([]*domain.ItemTypeDto, error)
-	GetItemsByType(context.Context, string) ([]*domain.ItemDto, error)
-}
+type (
+	ImageConfig struct {
+		ThumbnailWidth int
+		MaxBytes       int
+		// MaxPixels is the largest width times height of an image which is decoded.
+		MaxPixels int
+	}
+
+	CatalogEventPublisher interface {
+		Configure(...messaging.Option)
+		Publish(context.Context, []byte, string) error
+	}
+
+	UseCase interface {
+		// GetItemTypes and GetItemsByType return the menu of the location at the given time.
+		GetItemTypes(ctx context.Context, location string, at time.Time) ([]*domain.ItemTypeDto, error)
+		GetItemsByType(ctx context.Context, itemTypes, location string, at time.Time) ([]*domain.ItemDto, error)
+		LookupItems(
+			ctx context.Context,
+			itemTypes []int,
+			location string,
+			at time.Time,
+		) ([]*domain.ItemDto, []*domain.ItemLookupMiss, error)
+		SearchItems(ctx context.Context, query domain.SearchQuery, location string, at time.Time) (*domain.SearchResult, error)
+		ImportCatalog(ctx context.Context, format string, data []byte, dryRun, replace bool) (*domain.CatalogImportResult, error)
+		ExportCatalog(ctx context.Context, format string) (data []byte, contentType string, err error)
+		UploadItemImage(ctx context.Context, name, contentType string, data []byte) (*domain.ItemTypeDto, error)
+		GetItemImage(ctx context.Context, name string, thumbnail bool) (data []byte, contentType string, err error)
+	}
+)
@@ -2,11 +2,20 @@ package products
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/wire"
 	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	events "github.com/thangchung/go-coffeeshop/internal/pkg/event"
 	"github.com/thangchung/go-coffeeshop/internal/product/domain"
+	"github.com/thangchung/go-coffeeshop/pkg/blob"
+	"golang.org/x/exp/slog"
+	"golang.org/x/sync/singleflight"
 )
 
 var _ UseCase = (*service)(nil)
@@ -14,31 +23,317 @@ var _ UseCase = (*service)(nil)
 var UseCaseSet = wire.NewSet(NewService)
 
 type service struct {
-	repo domain.ProductRepo
+	repo       domain.ProductRepo
+	menuRepo   domain.MenuRepo
+	catalogPub CatalogEventPublisher
+	images     blob.Store
+	imageCfg   ImageConfig
+	// writeMu keeps two writers from changing the same snapshot of the catalog
+	writeMu sync.Mutex
+	// thumbnails makes the readers of a missing thumbnail wait for one of them to generate it
+	thumbnails singleflight.Group
 }
 
-func NewService(repo domain.ProductRepo) UseCase {
+func NewService(
+	repo domain.ProductRepo,
+	menuRepo domain.MenuRepo,
+	catalogPub CatalogEventPublisher,
+	images blob.Store,
+	imageCfg ImageConfig,
+) UseCase {
 	return &service{
-		repo: repo,
+		repo:       repo,
+		menuRepo:   menuRepo,
+		catalogPub: catalogPub,
+		images:     images,
+		imageCfg:   imageCfg,
 	}
 }
 
-func (s *service) GetItemTypes(ctx context.Context) ([]*domain.ItemTypeDto, error) {
-	results, err := s.repo.GetAll(ctx)
+func (s *service) GetItemTypes(ctx context.Context, location string, at time.Time) ([]*domain.ItemTypeDto, error) {
+	results, err := s.menuAt(ctx, location, at)
 	if err != nil {
 		return nil, errors.Wrap(err, "service.GetItemTypes")
 	}
 
-	return results, nil
+	return lo.Filter(results, func(item *domain.ItemTypeDto, _ int) bool {
+		return item.Available
+	}), nil
 }
 
-func (s *service) GetItemsByType(ctx context.Context, itemTypes string) ([]*domain.ItemDto, error) {
-	types := strings.Split(itemTypes, ",")
-
-	results, err := s.repo.GetByTypes(ctx, types)
+func (s *service) GetItemsByType(
+	ctx context.Context,
+	itemTypes, location string,
+	at time.Time,
+) ([]*domain.ItemDto, error) {
+	results, err := s.menuAt(ctx, location, at)
 	if err != nil {
 		return nil, errors.Wrap(err, "service.GetItemsByType")
 	}
 
-	return results, nil
+	byName := lo.KeyBy(results, func(item *domain.ItemTypeDto) string {
+		return item.Name
+	})
+
+	items := make([]*domain.ItemDto, 0)
+
+	for _, itemType := range strings.Split(itemTypes, ",") {
+		item, ok := byName[itemType]
+		if ok && item.Available {
+			items = append(items, &domain.ItemDto{
+				Price:      item.Price,
+				Type:       item.Type,
+				Name:       item.Name,
+				ValidUntil: item.ValidUntil,
+			})
+		}
+	}
+
+	return items, nil
+}
+
+func (s *service) LookupItems(
+	ctx context.Context,
+	itemTypes []int,
+	location string,
+	at time.Time,
+) ([]*domain.ItemDto, []*domain.ItemLookupMiss, error) {
+	results, err := s.menuAt(ctx, location, at)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "service.LookupItems")
+	}
+
+	found, missing := domain.LookupItems(results, itemTypes)
+
+	return found, missing, nil
+}
+
+func (s *service) SearchItems(
+	ctx context.Context,
+	query domain.SearchQuery,
+	location string,
+	at time.Time,
+) (*domain.SearchResult, error) {
+	results, err := s.GetItemTypes(ctx, location, at)
+	if err != nil {
+		return nil, errors.Wrap(err, "service.SearchItems")
+	}
+
+	found, err := domain.SearchItems(results, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.SearchItems")
+	}
+
+	return found, nil
+}
+
+// menuAt is the catalog with the price schedules of the location applied.
+func (s *service) menuAt(ctx context.Context, location string, at time.Time) ([]*domain.ItemTypeDto, error) {
+	items, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	menu, err := s.menuRepo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return menu.Apply(items, location, at), nil
+}
+
+func (s *service) ImportCatalog(
+	ctx context.Context,
+	format string,
+	data []byte,
+	dryRun, replace bool,
+) (*domain.CatalogImportResult, error) {
+	rows, rowErrs, err := domain.ParseCatalog(format, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.ParseCatalog")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	current, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "service.ImportCatalog")
+	}
+
+	next := domain.MergeCatalog(current, rows, replace)
+	rowErrs = append(rowErrs, domain.ValidateRetainedSKUs(rows, next)...)
+	result := &domain.CatalogImportResult{
+		Errors:  rowErrs,
+		Changes: domain.DiffCatalog(current, next),
+	}
+
+	// nothing is applied unless every row is valid
+	if dryRun || len(rowErrs) > 0 {
+		return result, nil
+	}
+
+	if err = s.repo.ReplaceAll(ctx, next); err != nil {
+		return nil, errors.Wrap(err, "service.ImportCatalog")
+	}
+
+	result.Applied = true
+
+	s.publishCatalogChanged(ctx, result.Changes)
+
+	return result, nil
+}
+
+// publishCatalogChanged lets the services which cache the catalog drop it, on every import and image upload.
+// The price schedules need no event, the cached items are valid until the next change of the menu.
+// The change has already been applied, a failure is only logged, the caches expire on their own.
+func (s *service) publishCatalogChanged(ctx context.Context, changes []*domain.CatalogChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	eventBytes, err := json.Marshal(&events.CatalogChanged{
+		Items: lo.Map(changes, func(c *domain.CatalogChange, _ int) string {
+			return c.Name
+		}),
+		Created: time.Now(),
+	})
+	if err != nil {
+		slog.Error("failed to marshal CatalogChanged", "error", err)
+
+		return
+	}
+
+	if err = s.catalogPub.Publish(ctx, eventBytes, "text/plain"); err != nil {
+		slog.Error("failed to publish CatalogChanged", "error", err)
+	}
+}
+
+func (s *service) ExportCatalog(ctx context.Context, format string) ([]byte, string, error) {
+	items, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "service.ExportCatalog")
+	}
+
+	data, contentType, err := domain.EncodeCatalog(format, items)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "domain.EncodeCatalog")
+	}
+
+	return data, contentType, nil
+}
+
+// UploadItemImage stores the image and its thumbnail, then points the item to it.
+// When contentType is empty it is sniffed from data.
+func (s *service) UploadItemImage(
+	ctx context.Context,
+	name, contentType string,
+	data []byte,
+) (*domain.ItemTypeDto, error) {
+	if len(data) > s.imageCfg.MaxBytes {
+		return nil, errors.Wrapf(domain.ErrImageTooLarge, "%d bytes, at most %d", len(data), s.imageCfg.MaxBytes)
+	}
+
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	key, err := domain.ImageKey(name, contentType)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.ImageKey")
+	}
+
+	thumbnail, err := domain.MakeThumbnail(data, s.imageCfg.ThumbnailWidth, s.imageCfg.MaxPixels)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.MakeThumbnail")
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	item, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, errors.Wrap(err, "service.UploadItemImage")
+	}
+
+	if item == nil {
+		return nil, errors.Wrapf(domain.ErrItemNotFound, "%q", name)
+	}
+
+	if err = s.images.Put(ctx, key, data); err != nil {
+		return nil, errors.Wrap(err, "images.Put")
+	}
+
+	if err = s.images.Put(ctx, domain.ThumbnailKey(key), thumbnail); err != nil {
+		return nil, errors.Wrap(err, "images.Put[thumbnail]")
+	}
+
+	item.Image = key
+
+	if err = s.repo.Update(ctx, item); err != nil {
+		return nil, errors.Wrap(err, "service.UploadItemImage")
+	}
+
+	s.publishCatalogChanged(ctx, []*domain.CatalogChange{{Action: domain.CatalogChangeUpdate, Name: item.Name}})
+
+	return item, nil
+}
+
+// GetItemImage returns the image of the item, a missing thumbnail (e.g. of a seeded image) is generated on the way.
+func (s *service) GetItemImage(ctx context.Context, name string, thumbnail bool) ([]byte, string, error) {
+	item, err := s.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "service.GetItemImage")
+	}
+
+	if item == nil || item.Image == "" {
+		return nil, "", errors.Wrapf(domain.ErrItemNotFound, "%q has no image", name)
+	}
+
+	if !thumbnail {
+		data, contentType, err := s.images.Get(ctx, item.Image)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "images.Get")
+		}
+
+		return data, contentType, nil
+	}
+
+	thumbnailKey := domain.ThumbnailKey(item.Image)
+
+	data, contentType, err := s.images.Get(ctx, thumbnailKey)
+	if err == nil {
+		return data, contentType, nil
+	}
+
+	if !errors.Is(err, blob.ErrNotFound) {
+		return nil, "", errors.Wrap(err, "images.Get[thumbnail]")
+	}
+
+	generated, err, _ := s.thumbnails.Do(thumbnailKey, func() (interface{}, error) {
+		return s.generateThumbnail(ctx, item.Image, thumbnailKey)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return generated.([]byte), "image/png", nil
+}
+
+func (s *service) generateThumbnail(ctx context.Context, key, thumbnailKey string) ([]byte, error) {
+	original, _, err := s.images.Get(ctx, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "images.Get")
+	}
+
+	data, err := domain.MakeThumbnail(original, s.imageCfg.ThumbnailWidth, s.imageCfg.MaxPixels)
+	if err != nil {
+		return nil, errors.Wrap(err, "domain.MakeThumbnail")
+	}
+
+	if err = s.images.Put(ctx, thumbnailKey, data); err != nil {
+		return nil, errors.Wrap(err, "images.Put[thumbnail]")
+	}
+
+	return data, nil
 }
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/metrics"
+	"golang.org/x/exp/slog"
+)
+
+const (
+	_readTimeout     = 5 * time.Second
+	_shutdownTimeout = 5 * time.Second
+)
+
+// Register serves the metrics on /metrics, the liveness on /healthz and the readiness on /readyz.
+func Register(mux *http.ServeMux, checker *health.Checker) {
+	mux.Handle("/metrics", metrics.Handler())
+	mux.Handle("/healthz", health.LiveHandler())
+	mux.Handle("/readyz", checker.ReadyHandler())
+}
+
+// Serve listens for the admin endpoints until ctx is done, it does nothing when the port is zero.
+func Serve(ctx context.Context, cfg configs.Admin, checker *health.Checker) {
+	if cfg.Port == 0 {
+		return
+	}
+
+	mux := http.NewServeMux()
+	Register(mux, checker)
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: _readTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), _shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed to shut down the admin server", "error", err)
+		}
+	}()
+
+	slog.Info("🩺 serve admin endpoints", "address", server.Addr)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to serve the admin endpoints", "error", err)
+	}
+}
@@ -0,0 +1,297 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	_secret     = "a-secret-of-at-least-32-bytes-long"
+	_issuer     = "https://login.coffeeshop.test"
+	_cashierID  = "till-1"
+	_cashier    = "cashier-key"
+	_placeOrder = "/go.coffeeshop.proto.counterapi.CounterService/PlaceOrder"
+	_itemTypes  = "/go.coffeeshop.proto.productapi.ProductService/GetItemTypes"
+)
+
+type tokenClaims struct {
+	jwt.Claims
+	Roles []string `json:"roles,omitempty"`
+}
+
+func sign(t *testing.T, key jose.SigningKey, kid string, claims tokenClaims) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if kid != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), kid)
+	}
+
+	signer, err := jose.NewSigner(key, opts)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+
+	return "Bearer " + token
+}
+
+func validClaims(roles ...string) tokenClaims {
+	return tokenClaims{
+		Claims: jwt.Claims{
+			Subject: "alice",
+			Issuer:  _issuer,
+			Expiry:  jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: roles,
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	return file
+}
+
+func apiKeysFile(t *testing.T) string {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(_cashier))
+
+	return writeFile(t, "api_keys.yml", `
+keys:
+  - name: `+_cashierID+`
+    sha256: `+hex.EncodeToString(sum[:])+`
+    roles: [cashier]
+`)
+}
+
+func newAuthenticator(t *testing.T) *auth.Authenticator {
+	t.Helper()
+
+	authn, err := auth.NewAuthenticator(configs.Auth{
+		Enabled:     true,
+		HMACSecret:  _secret,
+		Issuer:      _issuer,
+		APIKeysFile: apiKeysFile(t),
+	})
+	require.NoError(t, err)
+
+	return authn
+}
+
+func TestAuthenticateHMACToken(t *testing.T) {
+	t.Parallel()
+
+	authn := newAuthenticator(t)
+	key := jose.SigningKey{Algorithm: jose.HS256, Key: []byte(_secret)}
+
+	id, err := authn.Authenticate(auth.Credentials{Authorization: sign(t, key, "", validClaims("barista", "unknown"))})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", id.Subject)
+	assert.Equal(t, []gen.Role{gen.Role_BARISTA}, id.Roles)
+
+	expired := validClaims("barista")
+	expired.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+	otherIssuer := validClaims("barista")
+	otherIssuer.Issuer = "https://elsewhere.test"
+
+	noExpiry := validClaims("barista")
+	noExpiry.Expiry = nil
+
+	for name, authorization := range map[string]string{
+		"expired":      sign(t, key, "", expired),
+		"other issuer": sign(t, key, "", otherIssuer),
+		"no expiry":    sign(t, key, "", noExpiry),
+		"wrong secret": sign(t, jose.SigningKey{Algorithm: jose.HS256, Key: []byte("another-secret-of-32-bytes-long!!")}, "", validClaims()),
+		"not bearer":   "Basic YWxpY2U6c2VjcmV0",
+		"malformed":    "Bearer not.a.token",
+	} {
+		_, err := authn.Authenticate(auth.Credentials{Authorization: authorization})
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials, name)
+	}
+}
+
+func TestAuthenticateJWKSToken(t *testing.T) {
+	t.Parallel()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: &private.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig"},
+	}})
+	require.NoError(t, err)
+
+	authn, err := auth.NewAuthenticator(configs.Auth{
+		Enabled:  true,
+		JWKSFile: writeFile(t, "jwks.json", string(jwks)),
+		Issuer:   _issuer,
+	})
+	require.NoError(t, err)
+
+	key := jose.SigningKey{Algorithm: jose.RS256, Key: private}
+
+	id, err := authn.Authenticate(auth.Credentials{Authorization: sign(t, key, "k1", validClaims("manager"))})
+	require.NoError(t, err)
+	assert.Equal(t, []gen.Role{gen.Role_MANAGER}, id.Roles)
+
+	_, err = authn.Authenticate(auth.Credentials{Authorization: sign(t, key, "k2", validClaims("manager"))})
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	// there is no HMAC secret, a token can't be signed with the public key as one
+	_, err = authn.Authenticate(auth.Credentials{
+		Authorization: sign(t, jose.SigningKey{Algorithm: jose.HS256, Key: []byte(_secret)}, "k1", validClaims("admin")),
+	})
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	t.Parallel()
+
+	authn := newAuthenticator(t)
+
+	id, err := authn.Authenticate(auth.Credentials{APIKey: _cashier})
+	require.NoError(t, err)
+	assert.Equal(t, &auth.Identity{Subject: _cashierID, Roles: []gen.Role{gen.Role_CASHIER}}, id)
+
+	_, err = authn.Authenticate(auth.Credentials{APIKey: "guessed"})
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authn.Authenticate(auth.Credentials{})
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+}
+
+func TestNewAuthenticatorNeedsKeysWhenEnabled(t *testing.T) {
+	t.Parallel()
+
+	_, err := auth.NewAuthenticator(configs.Auth{Enabled: true})
+	assert.Error(t, err)
+
+	_, err = auth.NewAuthenticator(configs.Auth{Enabled: true, HMACSecret: "too-short"})
+	assert.ErrorContains(t, err, "at least 32")
+
+	authn, err := auth.NewAuthenticator(configs.Auth{})
+	require.NoError(t, err)
+	assert.False(t, authn.Enabled())
+}
+
+func TestUnaryServerInterceptorChecksTheRolesOfTheMethods(t *testing.T) {
+	t.Parallel()
+
+	interceptor := auth.UnaryServerInterceptor("counter-service", newAuthenticator(t))
+	key := jose.SigningKey{Algorithm: jose.HS256, Key: []byte(_secret)}
+
+	call := func(method string, md metadata.MD) (*auth.Identity, codes.Code) {
+		var id *auth.Identity
+
+		_, err := interceptor(
+			metadata.NewIncomingContext(context.Background(), md),
+			nil,
+			&grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, _ any) (any, error) {
+				id, _ = auth.FromContext(ctx)
+
+				return nil, nil
+			},
+		)
+
+		return id, status.Code(err)
+	}
+
+	_, code := call(_itemTypes, metadata.MD{})
+	assert.Equal(t, codes.OK, code, "public methods take anonymous calls")
+
+	_, code = call(_placeOrder, metadata.MD{})
+	assert.Equal(t, codes.Unauthenticated, code)
+
+	_, code = call(_itemTypes, metadata.Pairs(auth.HeaderAPIKey, "guessed"))
+	assert.Equal(t, codes.Unauthenticated, code, "bad credentials are refused on public methods too")
+
+	id, code := call(_placeOrder, metadata.Pairs(auth.HeaderAPIKey, _cashier))
+	assert.Equal(t, codes.OK, code)
+	assert.Equal(t, _cashierID, id.Subject)
+
+	_, code = call(_placeOrder, metadata.Pairs(auth.HeaderAuthorization, sign(t, key, "", validClaims("barista"))))
+	assert.Equal(t, codes.PermissionDenied, code)
+
+	_, code = call(_placeOrder, metadata.Pairs(auth.HeaderAuthorization, sign(t, key, "", validClaims("admin"))))
+	assert.Equal(t, codes.OK, code)
+
+	_, code = call("/go.coffeeshop.proto.productapi.ProductService/NotAnnotated", metadata.Pairs(auth.HeaderAPIKey, _cashier))
+	assert.Equal(t, codes.PermissionDenied, code, "methods without a rule are for admins")
+
+	_, code = call("/grpc.health.v1.Health/Check", metadata.MD{})
+	assert.Equal(t, codes.OK, code)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptorKeepsReflectionForAdmins(t *testing.T) {
+	t.Parallel()
+
+	interceptor := auth.StreamServerInterceptor("product-service", newAuthenticator(t))
+	key := jose.SigningKey{Algorithm: jose.HS256, Key: []byte(_secret)}
+	reflection := "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo"
+
+	call := func(method string, md metadata.MD) (*auth.Identity, codes.Code) {
+		var id *auth.Identity
+
+		err := interceptor(
+			nil,
+			&fakeStream{ctx: metadata.NewIncomingContext(context.Background(), md)},
+			&grpc.StreamServerInfo{FullMethod: method, IsServerStream: true},
+			func(_ any, ss grpc.ServerStream) error {
+				id, _ = auth.FromContext(ss.Context())
+
+				return nil
+			},
+		)
+
+		return id, status.Code(err)
+	}
+
+	_, code := call(reflection, metadata.MD{})
+	assert.Equal(t, codes.Unauthenticated, code)
+
+	_, code = call(reflection, metadata.Pairs(auth.HeaderAPIKey, _cashier))
+	assert.Equal(t, codes.PermissionDenied, code, "methods without a rule are for admins")
+
+	id, code := call(reflection, metadata.Pairs(auth.HeaderAuthorization, sign(t, key, "", validClaims("admin"))))
+	assert.Equal(t, codes.OK, code)
+	assert.NotNil(t, id)
+
+	_, code = call("/grpc.health.v1.Health/Watch", metadata.MD{})
+	assert.Equal(t, codes.OK, code)
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+)
+
+const (
+	// HeaderAuthorization carries the bearer token of a person, over HTTP and in the gRPC metadata.
+	HeaderAuthorization = "authorization"
+	// HeaderAPIKey carries the API key of a device, over HTTP and in the gRPC metadata.
+	HeaderAPIKey = "x-api-key"
+
+	_bearer = "bearer "
+	// _leeway is how far the clocks of the issuer and the services may be apart.
+	_leeway = 30 * time.Second
+	// _minHMACSecret is the size of the output of SHA-256, a shorter secret makes the HS256 tokens easier to forge.
+	_minHMACSecret = 32
+)
+
+var (
+	ErrNoCredentials      = errors.New("no credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrPermissionDenied   = errors.New("permission denied")
+)
+
+// Credentials are what a caller presents, the Authorization header as it is and the API key.
+type Credentials struct {
+	Authorization string
+	APIKey        string
+}
+
+// Authenticator tells who presented some credentials, a disabled one lets every call through anonymously.
+type Authenticator struct {
+	enabled    bool
+	hmacSecret []byte
+	jwks       []jose.JSONWebKey
+	issuer     string
+	audience   string
+	apiKeys    map[[sha256.Size]byte]*Identity
+	now        func() time.Time
+}
+
+// apiKeysFile lists the devices, a key is kept as the hex SHA-256 of it so the file gives none of them away.
+type apiKeysFile struct {
+	Keys []struct {
+		Name   string   `yaml:"name"`
+		SHA256 string   `yaml:"sha256"`
+		Roles  []string `yaml:"roles"`
+	} `yaml:"keys"`
+}
+
+// NewAuthenticator loads the keys of cfg, an enabled authenticator needs at least one way to verify a caller
+// and an HMAC secret of at least 32 bytes.
+func NewAuthenticator(cfg configs.Auth) (*Authenticator, error) {
+	a := &Authenticator{
+		enabled:  cfg.Enabled,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		apiKeys:  map[[sha256.Size]byte]*Identity{},
+		now:      time.Now,
+	}
+
+	if !cfg.Enabled {
+		return a, nil
+	}
+
+	if cfg.HMACSecret == "" && cfg.JWKSFile == "" && cfg.APIKeysFile == "" {
+		return nil, errors.New("auth is enabled without an HMAC secret, a JWKS file or an API keys file")
+	}
+
+	if cfg.HMACSecret != "" {
+		if len(cfg.HMACSecret) < _minHMACSecret {
+			return nil, errors.Errorf("the HMAC secret has %d bytes, it needs at least %d", len(cfg.HMACSecret), _minHMACSecret)
+		}
+
+		a.hmacSecret = []byte(cfg.HMACSecret)
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+
+		a.jwks = keys
+	}
+
+	if cfg.APIKeysFile != "" {
+		if err := a.loadAPIKeys(cfg.APIKeysFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// Enabled tells whether the callers are checked at all.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate verifies the API key, or else the bearer token, of a caller.
+// It fails with ErrNoCredentials when there are neither and with ErrInvalidCredentials when they don't verify.
+func (a *Authenticator) Authenticate(creds Credentials) (*Identity, error) {
+	switch {
+	case creds.APIKey != "":
+		id, ok := a.apiKeys[sha256.Sum256([]byte(creds.APIKey))]
+		if !ok {
+			return nil, errors.Wrap(ErrInvalidCredentials, "the API key is unknown")
+		}
+
+		return id, nil
+	case creds.Authorization != "":
+		if len(creds.Authorization) <= len(_bearer) || !strings.EqualFold(creds.Authorization[:len(_bearer)], _bearer) {
+			return nil, errors.Wrap(ErrInvalidCredentials, "the authorization is not a bearer token")
+		}
+
+		return a.verifyToken(strings.TrimSpace(creds.Authorization[len(_bearer):]))
+	default:
+		return nil, ErrNoCredentials
+	}
+}
+
+func (a *Authenticator) verifyToken(raw string) (*Identity, error) {
+	token, err := jwt.ParseSigned(raw)
+	if err != nil || len(token.Headers) != 1 {
+		return nil, errors.Wrap(ErrInvalidCredentials, "the token is malformed")
+	}
+
+	key, err := a.keyOf(token.Headers[0])
+	if err != nil {
+		return nil, err
+	}
+
+	claims := jwt.Claims{}
+	custom := struct {
+		Roles []string `json:"roles"`
+	}{}
+
+	if err := token.Claims(key, &claims, &custom); err != nil {
+		return nil, errors.Wrap(ErrInvalidCredentials, "the token doesn't verify")
+	}
+
+	// a token without an expiry would be good forever once it leaked
+	if claims.Expiry == nil || claims.Subject == "" {
+		return nil, errors.Wrap(ErrInvalidCredentials, "the token has no exp or sub")
+	}
+
+	expected := jwt.Expected{Issuer: a.issuer, Time: a.now()}
+	if a.audience != "" {
+		expected.Audience = jwt.Audience{a.audience}
+	}
+
+	switch err := claims.ValidateWithLeeway(expected, _leeway); {
+	case errors.Is(err, jwt.ErrExpired):
+		return nil, errors.Wrap(ErrInvalidCredentials, "the token is expired")
+	case err != nil:
+		return nil, errors.Wrap(ErrInvalidCredentials, "the token is not for this service or not valid yet")
+	}
+
+	return &Identity{Subject: claims.Subject, Roles: parseRoles(custom.Roles)}, nil
+}
+
+// keyOf is the key a token is verified with, the HMAC secret for the HS algorithms and a key of the JWKS for the others.
+// The algorithm of the token has to fit the key, so an RSA public key can't be passed off as an HMAC secret.
+func (a *Authenticator) keyOf(header jose.Header) (any, error) {
+	alg := jose.SignatureAlgorithm(header.Algorithm)
+
+	switch alg {
+	case jose.HS256, jose.HS384, jose.HS512:
+		if a.hmacSecret == nil {
+			return nil, errors.Wrap(ErrInvalidCredentials, "the token is signed with a secret")
+		}
+
+		return a.hmacSecret, nil
+	}
+
+	for _, key := range a.jwks {
+		if header.KeyID != "" && key.KeyID != header.KeyID {
+			continue
+		}
+
+		if key.Algorithm == "" || key.Algorithm == string(alg) {
+			return key.Key, nil
+		}
+	}
+
+	return nil, errors.Wrapf(ErrInvalidCredentials, "no key for the token, kid %q alg %s", header.KeyID, alg)
+}
+
+// loadJWKS reads the public keys of a JWKS file, the private halves of the keys are dropped when the file has them.
+func loadJWKS(file string) ([]jose.JSONWebKey, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrap(err, "os.ReadFile")
+	}
+
+	set := jose.JSONWebKeySet{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrapf(err, "%s is not a JWKS", file)
+	}
+
+	keys := make([]jose.JSONWebKey, 0, len(set.Keys))
+
+	for _, key := range set.Keys {
+		// signing keys only, the secrets go into hmac_secret
+		if key.Use == "enc" {
+			continue
+		}
+
+		public := key.Public()
+		if !public.Valid() {
+			return nil, errors.Errorf("%s: key %q is not an RSA, EC or Ed25519 key", file, key.KeyID)
+		}
+
+		keys = append(keys, public)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.Errorf("%s has no signing keys", file)
+	}
+
+	return keys, nil
+}
+
+func (a *Authenticator) loadAPIKeys(file string) error {
+	content := apiKeysFile{}
+	if err := cleanenv.ReadConfig(file, &content); err != nil {
+		return errors.Wrapf(err, "cleanenv.ReadConfig(%q)", file)
+	}
+
+	for _, key := range content.Keys {
+		digest, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(digest) != sha256.Size {
+			return errors.Errorf("%s: the sha256 of %q is not a hex SHA-256", file, key.Name)
+		}
+
+		var sum [sha256.Size]byte
+		copy(sum[:], digest)
+
+		if _, ok := a.apiKeys[sum]; ok {
+			return errors.Errorf("%s: %q has the key of another device", file, key.Name)
+		}
+
+		a.apiKeys[sum] = &Identity{Subject: key.Name, Roles: parseRoles(key.Roles)}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// _healthService is served by every gRPC server for the probes, which have no credentials.
+var _healthService = "/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/"
+
+var ErrorRules = []grpcerror.Rule{
+	{Target: ErrNoCredentials, Code: codes.Unauthenticated, Reason: "UNAUTHENTICATED"},
+	{Target: ErrInvalidCredentials, Code: codes.Unauthenticated, Reason: "UNAUTHENTICATED"},
+	{Target: ErrPermissionDenied, Code: codes.PermissionDenied, Reason: "PERMISSION_DENIED"},
+}
+
+// UnaryServerInterceptor lets a call through when the access rule of its method allows the caller,
+// the handlers find the identity of the caller with FromContext. Domain names the service in ErrorInfo.
+//
+// A public method takes anonymous calls, any other one needs credentials with one of its roles,
+// and a method without a rule is only for admins. Credentials which don't verify are refused on every method.
+func UnaryServerInterceptor(domain string, authn *Authenticator) grpc.UnaryServerInterceptor {
+	rules := accessRules()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, domain, authn, rules, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor checks the streaming calls like UnaryServerInterceptor does the unary ones,
+// e.g. the reflection service, which has no rule and so is only for admins.
+func StreamServerInterceptor(domain string, authn *Authenticator) grpc.StreamServerInterceptor {
+	rules := accessRules()
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), domain, authn, rules, info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize returns ctx with the identity of the caller when the rule of the method allows it, the status to answer otherwise.
+func authorize(
+	ctx context.Context,
+	domain string,
+	authn *Authenticator,
+	rules map[string]*gen.Access,
+	method string,
+) (context.Context, error) {
+	if !authn.Enabled() || strings.HasPrefix(method, _healthService) {
+		return ctx, nil
+	}
+
+	access := rules[method]
+
+	id, err := authn.Authenticate(credentialsOf(ctx))
+
+	switch {
+	case errors.Is(err, ErrNoCredentials) && access.GetPublic():
+		return ctx, nil
+	case err != nil:
+		slog.Debug("refused a call", "method", method, "error", err)
+
+		return nil, grpcerror.ToStatus(err, domain, ErrorRules...).Err()
+	}
+
+	if !access.GetPublic() && !id.HasAnyRole(access.GetRoles()...) {
+		err := errors.Wrapf(ErrPermissionDenied, "%s may not call %s", id.Subject, method)
+
+		return nil, grpcerror.ToStatus(err, domain, ErrorRules...).Err()
+	}
+
+	return NewContext(ctx, id), nil
+}
+
+// identityStream hands the context with the identity of the caller to the stream handlers.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor passes the credentials of the call being handled on to the calls made for it,
+// so a service calls the others on behalf of its caller. Calls outside of a request go out anonymously.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if in, ok := metadata.FromIncomingContext(ctx); ok {
+			out, _ := metadata.FromOutgoingContext(ctx)
+
+			for _, key := range []string{HeaderAuthorization, HeaderAPIKey} {
+				if values := in.Get(key); len(values) > 0 && len(out.Get(key)) == 0 {
+					ctx = metadata.AppendToOutgoingContext(ctx, key, values[0])
+				}
+			}
+		}
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// WithCredentials sends creds with the calls made with ctx, e.g. by a command line client.
+func WithCredentials(ctx context.Context, creds Credentials) context.Context {
+	if creds.Authorization != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, HeaderAuthorization, creds.Authorization)
+	}
+
+	if creds.APIKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, HeaderAPIKey, creds.APIKey)
+	}
+
+	return ctx
+}
+
+func credentialsOf(ctx context.Context) Credentials {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+
+	return Credentials{Authorization: first(HeaderAuthorization), APIKey: first(HeaderAPIKey)}
+}
+
+// accessRules are the access options of the methods of every registered proto, by full method name.
+func accessRules() map[string]*gen.Access {
+	rules := map[string]*gen.Access{}
+
+	protoregistry.GlobalFiles.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+
+			for j := 0; j < service.Methods().Len(); j++ {
+				method := service.Methods().Get(j)
+
+				opts, ok := method.Options().(*descriptorpb.MethodOptions)
+				if !ok || !proto.HasExtension(opts, gen.E_Access) {
+					continue
+				}
+
+				access, ok := proto.GetExtension(opts, gen.E_Access).(*gen.Access)
+				if ok {
+					rules["/"+string(service.FullName())+"/"+string(method.Name())] = access
+				}
+			}
+		}
+
+		return true
+	})
+
+	return rules
+}
@@ -0,0 +1,56 @@
+// Package auth verifies the callers of the services and checks their roles against the access rules
+// which the methods declare in the protos, see proto/auth.proto.
+//
+// The gateway verifies the credentials of a request and passes them on in the metadata of the calls it makes for it,
+// the services verify them again instead of trusting whoever reaches their port, and pass them on to the services they
+// call in turn.
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/proto/gen"
+)
+
+// Identity is who made a call and what they may do.
+type Identity struct {
+	// Subject is the sub claim of a token or the name of an API key.
+	Subject string
+	Roles   []gen.Role
+}
+
+// HasAnyRole tells whether the identity has one of roles, an admin has all of them.
+func (id *Identity) HasAnyRole(roles ...gen.Role) bool {
+	return lo.Contains(id.Roles, gen.Role_ADMIN) || lo.Some(id.Roles, roles)
+}
+
+type identityKey struct{}
+
+// NewContext carries the identity of the caller to the handlers.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext is the identity of the caller, ok is false for anonymous calls and when auth is off.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+
+	return id, ok
+}
+
+// parseRoles reads role names like "cashier" or "MANAGER", the ones the services don't know are left out,
+// a token may carry the roles of other applications too.
+func parseRoles(names []string) []gen.Role {
+	roles := make([]gen.Role, 0, len(names))
+
+	for _, name := range names {
+		role, ok := gen.Role_value[strings.ToUpper(strings.TrimSpace(name))]
+		if ok && gen.Role(role) != gen.Role_ROLE_UNSPECIFIED {
+			roles = append(roles, gen.Role(role))
+		}
+	}
+
+	return lo.Uniq(roles)
+}
@@ -0,0 +1,16 @@
+package blob
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps binary objects under slash separated keys, e.g. "items/LATTE.png".
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	// Get returns the object and its content type, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, string, error)
+}
@@ -0,0 +1,109 @@
+package blob
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type StoreDir string
+
+type localStore struct {
+	root string
+}
+
+var _ Store = (*localStore)(nil)
+
+// NewLocalStore keeps the objects as files below dir, which is created when missing.
+func NewLocalStore(dir StoreDir) (Store, error) {
+	root, err := filepath.Abs(string(dir))
+	if err != nil {
+		return nil, errors.Wrap(err, "filepath.Abs")
+	}
+
+	if err = os.MkdirAll(root, 0o755); err != nil {
+		return nil, errors.Wrap(err, "os.MkdirAll")
+	}
+
+	return &localStore{root: root}, nil
+}
+
+func (s *localStore) Put(ctx context.Context, key string, data []byte) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return errors.Wrap(err, "os.MkdirAll")
+	}
+
+	// write next to the target and rename, readers never see a half written file.
+	// Every call has a temp file of its own, so concurrent writers of a key don't mix their data
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "os.CreateTemp")
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return errors.Wrap(err, "file.Write")
+	}
+
+	if err = tmp.Close(); err != nil {
+		return errors.Wrap(err, "file.Close")
+	}
+
+	// CreateTemp makes the file private, the objects are served to everyone
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return errors.Wrap(err, "os.Chmod")
+	}
+
+	if err = os.Rename(tmp.Name(), file); err != nil {
+		return errors.Wrap(err, "os.Rename")
+	}
+
+	return nil
+}
+
+func (s *localStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", errors.Wrapf(ErrNotFound, "%q", key)
+	}
+
+	if err != nil {
+		return nil, "", errors.Wrap(err, "os.ReadFile")
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	return data, contentType, nil
+}
+
+// path maps a key to a file below root, keys escaping root are rejected.
+func (s *localStore) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || strings.Contains(key, "\\") {
+		return "", errors.Errorf("blob: invalid key %q", key)
+	}
+
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
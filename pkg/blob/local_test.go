@@ -0,0 +1,70 @@
+package blob_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/blob"
+)
+
+func TestLocalStoreKeepsKeysBelowRoot(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store, err := blob.NewLocalStore(blob.StoreDir(root))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	err = store.Put(ctx, "../../items/LATTE.png", []byte("latte"))
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(root, "items", "LATTE.png"))
+	assert.NoError(t, err)
+
+	data, contentType, err := store.Get(ctx, "items/LATTE.png")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("latte"), data)
+	assert.Equal(t, "image/png", contentType)
+
+	_, _, err = store.Get(ctx, "items/MOCHA.png")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestLocalStoreConcurrentPutsOfAKey(t *testing.T) {
+	t.Parallel()
+
+	root := t.TempDir()
+	store, err := blob.NewLocalStore(blob.StoreDir(root))
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	contents := [][]byte{[]byte("short"), []byte("a much longer thumbnail")}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func(data []byte) {
+			defer wg.Done()
+
+			assert.NoError(t, store.Put(ctx, "items/LATTE.thumb.png", data))
+		}(contents[i%len(contents)])
+	}
+
+	wg.Wait()
+
+	// one of the writes wins as a whole and no temp file is left behind
+	data, _, err := store.Get(ctx, "items/LATTE.thumb.png")
+	assert.NoError(t, err)
+	assert.Contains(t, contents, data)
+
+	files, err := os.ReadDir(filepath.Join(root, "items"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+}
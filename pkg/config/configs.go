@@ -1,5 +1,7 @@
 package configs
 
+import "time"
+
 type (
 	App struct {
 		Name    string `env-required:"true" yaml:"name"    env:"APP_NAME"`
@@ -14,4 +16,76 @@ type (
 	Log struct {
 		Level string `env-required:"true" yaml:"log_level"   env:"LOG_LEVEL"`
 	}
+
+	// GRPC is the gRPC server of a service, it listens on the HTTP host and port.
+	GRPC struct {
+		// Timeout is the deadline of the requests which come without one.
+		Timeout time.Duration `env-default:"30s" yaml:"timeout" env:"GRPC_TIMEOUT"`
+		TLS     TLS           `yaml:"tls" env-prefix:"GRPC_TLS_"`
+	}
+
+	// Tracing exports the spans of a service, Exporter is otlp (OTLP over gRPC to Endpoint), stdout or none.
+	// The trace context is passed on with none too, so the other services still see one trace.
+	Tracing struct {
+		Exporter    string  `env-default:"none"           yaml:"exporter"     env:"TRACING_EXPORTER"`
+		Endpoint    string  `env-default:"localhost:4317" yaml:"endpoint"     env:"TRACING_ENDPOINT"`
+		Insecure    bool    `env-default:"true"           yaml:"insecure"     env:"TRACING_INSECURE"`
+		SampleRatio float64 `env-default:"1"              yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+
+	// PG is the database of a service and the pool of its connections. Driver is postgres (lib/pq behind database/sql)
+	// or pgx (a pgx pool which caches the prepared statements of every connection) or sqlite (a file named by the DSN,
+	// served over one connection, for local runs and tests).
+	// PoolMaxIdle is how many idle connections database/sql keeps, PoolMinConns how many the pgx pool keeps open.
+	PG struct {
+		Driver          string        `env-default:"postgres" yaml:"driver"             env:"PG_DRIVER"`
+		DsnURL          string        `env-required:"true"    yaml:"dsn_url"            env:"PG_DSN_URL"`
+		PoolMax         int           `env-required:"true"    yaml:"pool_max"           env:"PG_POOL_MAX"`
+		PoolMaxIdle     int           `env-default:"2"        yaml:"pool_max_idle"      env:"PG_POOL_MAX_IDLE"`
+		PoolMinConns    int           `env-default:"0"        yaml:"pool_min_conns"     env:"PG_POOL_MIN_CONNS"`
+		ConnMaxLifetime time.Duration `env-default:"30m"      yaml:"conn_max_lifetime"  env:"PG_CONN_MAX_LIFETIME"`
+		ConnMaxIdleTime time.Duration `env-default:"5m"       yaml:"conn_max_idle_time" env:"PG_CONN_MAX_IDLE_TIME"`
+	}
+
+	// Preparation is how long the barista and the kitchen take to make an item,
+	// TimeScale multiplies the time of every item and 0 makes them at once.
+	Preparation struct {
+		TimeScale float64 `env-default:"1" yaml:"time_scale" env:"PREPARATION_TIME_SCALE"`
+	}
+
+	// Admin serves the Prometheus metrics and the health probes of a service on a port of their own,
+	// a zero port turns it off.
+	Admin struct {
+		Host string `env-default:"0.0.0.0" yaml:"host" env:"ADMIN_HOST"`
+		Port int    `env-default:"0"       yaml:"port" env:"ADMIN_PORT"`
+	}
+
+	// Auth verifies the callers of a service, every call is allowed while it isn't enabled.
+	// A person sends a JWT in the Authorization header, signed with HMACSecret or a key of JWKSFile,
+	// a device sends an API key of APIKeysFile in the X-API-Key header. Issuer and Audience are checked when set.
+	Auth struct {
+		Enabled     bool   `env-default:"false" yaml:"enabled"       env:"AUTH_ENABLED"`
+		HMACSecret  string `env-default:""      yaml:"hmac_secret"   env:"AUTH_HMAC_SECRET"`
+		JWKSFile    string `env-default:""      yaml:"jwks_file"     env:"AUTH_JWKS_FILE"`
+		Issuer      string `env-default:""      yaml:"issuer"        env:"AUTH_ISSUER"`
+		Audience    string `env-default:""      yaml:"audience"      env:"AUTH_AUDIENCE"`
+		APIKeysFile string `env-default:""      yaml:"api_keys_file" env:"AUTH_API_KEYS_FILE"`
+	}
+
+	// CORS lets the pages of AllowedOrigins call the gateway from a browser, * allows every origin.
+	CORS struct {
+		AllowedOrigins []string `env-default:"http://localhost:8888" env-separator:"," yaml:"allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	}
+
+	// TLS secures a gRPC server or client, the connection is plain text unless it is enabled.
+	// A server with a CAFile only accepts clients with a certificate signed by it (mutual TLS),
+	// a client verifies the server against CAFile (the system roots when empty) and presents CertFile when set.
+	TLS struct {
+		Enabled  bool   `yaml:"enabled"   env:"ENABLED"`
+		CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+		KeyFile  string `yaml:"key_file"  env:"KEY_FILE"`
+		CAFile   string `yaml:"ca_file"   env:"CA_FILE"`
+		// ServerName is the name a client expects in the server certificate, the host of the address when empty.
+		ServerName string `yaml:"server_name" env:"SERVER_NAME"`
+	}
 )
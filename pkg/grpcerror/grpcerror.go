@@ -0,0 +1,149 @@
+package grpcerror
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+)
+
+const (
+	ReasonInvalidArgument = "INVALID_ARGUMENT"
+	ReasonInternal        = "INTERNAL"
+)
+
+// label is how the code wraps errors on the way up, e.g. "uc.PlaceOrder" or "productGRPCServer-GetItemImage",
+// it means nothing to a client and is left out of the message it gets.
+var label = regexp.MustCompile(`^[A-Za-z_][\w.\-\[\]]*(\(.*\))?$`)
+
+// Rule maps an error, matched with errors.Is, to the status the client gets.
+// Reason is the UPPER_SNAKE_CASE reason of the ErrorInfo detail, e.g. ORDER_NOT_FOUND.
+type Rule struct {
+	Target error
+	Code   codes.Code
+	Reason string
+}
+
+// UnaryServerInterceptor turns the errors of the handlers into statuses.
+// Statuses (e.g. from BadRequest or a downstream service) are passed on, errors matching a rule get its code,
+// and anything else is logged and hidden behind codes.Internal. Domain names the service in ErrorInfo.
+func UnaryServerInterceptor(domain string, rules ...Rule) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			st := ToStatus(err, domain, rules...)
+			if st.Code() == codes.Internal {
+				slog.Error("failed to handle request", "error", err, "method", info.FullMethod)
+			}
+
+			return nil, st.Err()
+		}
+
+		return resp, nil
+	}
+}
+
+// ToStatus is the status a client gets for err.
+func ToStatus(err error, domain string, rules ...Rule) *status.Status {
+	var grpcStatus interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcStatus) {
+		return grpcStatus.GRPCStatus()
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, context.Canceled.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+	}
+
+	for _, rule := range rules {
+		if errors.Is(err, rule.Target) {
+			return withDetails(status.New(rule.Code, publicMessage(err)), &errdetails.ErrorInfo{
+				Reason: rule.Reason,
+				Domain: domain,
+			})
+		}
+	}
+
+	return withDetails(status.New(codes.Internal, "internal error"), &errdetails.ErrorInfo{
+		Reason: ReasonInternal,
+		Domain: domain,
+	})
+}
+
+// BadRequest rejects a request with the fields which are wrong.
+func BadRequest(domain string, violations ...*errdetails.BadRequest_FieldViolation) error {
+	descriptions := make([]string, 0, len(violations))
+	for _, v := range violations {
+		// a rule of the whole message has no field
+		if v.Field == "" {
+			descriptions = append(descriptions, v.Description)
+
+			continue
+		}
+
+		descriptions = append(descriptions, v.Field+": "+v.Description)
+	}
+
+	st := status.New(codes.InvalidArgument, strings.Join(descriptions, ", "))
+
+	return withDetails(
+		st,
+		&errdetails.BadRequest{FieldViolations: violations},
+		&errdetails.ErrorInfo{Reason: ReasonInvalidArgument, Domain: domain},
+	).Err()
+}
+
+func FieldViolation(field, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// withDetails falls back to the bare status, the details are a courtesy to the client.
+func withDetails(st *status.Status, details ...protoiface.MessageV1) *status.Status {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st
+	}
+
+	return detailed
+}
+
+// publicMessage is the message of err without the labels it was wrapped in, e.g.
+// `"LATTE" has no image: item not found` out of `service.GetItemImage: "LATTE" has no image: item not found`.
+func publicMessage(err error) string {
+	parts := make([]string, 0)
+
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		next := errors.Unwrap(e)
+		if next == nil {
+			parts = append(parts, e.Error())
+
+			break
+		}
+
+		// an error which doesn't print its cause last is kept as it is
+		if !strings.HasSuffix(e.Error(), next.Error()) {
+			parts = append(parts, e.Error())
+
+			break
+		}
+
+		own := strings.TrimSuffix(strings.TrimSuffix(e.Error(), next.Error()), ": ")
+		if own != "" && !label.MatchString(own) {
+			parts = append(parts, own)
+		}
+	}
+
+	return strings.Join(parts, ": ")
+}
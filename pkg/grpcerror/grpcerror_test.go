@@ -0,0 +1,100 @@
+package grpcerror_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errOrderNotFound = errors.New("order not found")
+
+var rules = []grpcerror.Rule{
+	{Target: errOrderNotFound, Code: codes.NotFound, Reason: "ORDER_NOT_FOUND"},
+}
+
+func TestToStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		err     error
+		code    codes.Code
+		message string
+		reason  string
+	}{
+		{
+			name:    "rule without the labels",
+			err:     errors.Wrap(errors.Wrapf(errOrderNotFound, "order %s", "42"), "uc.GetOrderTimeline"),
+			code:    codes.NotFound,
+			message: "order 42: order not found",
+			reason:  "ORDER_NOT_FOUND",
+		},
+		{
+			name:    "internal is hidden",
+			err:     errors.Wrap(errors.New("pq: connection refused"), "repo.GetAll"),
+			code:    codes.Internal,
+			message: "internal error",
+			reason:  grpcerror.ReasonInternal,
+		},
+		{
+			name:    "canceled",
+			err:     errors.Wrap(context.Canceled, "repo.GetAll"),
+			code:    codes.Canceled,
+			message: context.Canceled.Error(),
+		},
+		{
+			name:    "status is passed on",
+			err:     errors.Wrap(status.Error(codes.Unavailable, "product service is down"), "productClient.LookupItems"),
+			code:    codes.Unavailable,
+			message: "product service is down",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			st := grpcerror.ToStatus(tt.err, "counter-service", rules...)
+			assert.Equal(t, tt.code, st.Code())
+			assert.Equal(t, tt.message, st.Message())
+
+			if tt.reason != "" {
+				assert.Len(t, st.Details(), 1)
+
+				info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+				assert.True(t, ok)
+				assert.Equal(t, tt.reason, info.Reason)
+				assert.Equal(t, "counter-service", info.Domain)
+			}
+		})
+	}
+}
+
+func TestBadRequest(t *testing.T) {
+	t.Parallel()
+
+	err := grpcerror.BadRequest("counter-service",
+		grpcerror.FieldViolation("order_id", "must be a UUID"),
+		grpcerror.FieldViolation("barista_items[1].item_type", "42 is unknown"),
+	)
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "order_id: must be a UUID, barista_items[1].item_type: 42 is unknown", st.Message())
+	assert.Len(t, st.Details(), 2)
+
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	assert.True(t, ok)
+	assert.Len(t, badRequest.FieldViolations, 2)
+	assert.Equal(t, "barista_items[1].item_type", badRequest.FieldViolations[1].Field)
+
+	// a status already made by a handler keeps its details through the interceptor
+	assert.Equal(t, st.Proto(), grpcerror.ToStatus(errors.Wrap(err, "uc.PlaceOrder"), "counter-service").Proto())
+}
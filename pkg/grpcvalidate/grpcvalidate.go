@@ -0,0 +1,44 @@
+package grpcvalidate
+
+import (
+	"context"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/bufbuild/protovalidate-go"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcerror"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor checks the requests against the buf.validate rules of their messages before the handlers
+// get them, an invalid request is rejected with a BadRequest of its violations. Domain names the service in ErrorInfo.
+func UnaryServerInterceptor(domain string) (grpc.UnaryServerInterceptor, error) {
+	validator, err := protovalidate.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "protovalidate.New")
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		if err := validator.Validate(msg); err != nil {
+			var invalid *protovalidate.ValidationError
+			if !errors.As(err, &invalid) {
+				return nil, errors.Wrap(err, "validator.Validate")
+			}
+
+			return nil, grpcerror.BadRequest(domain, lo.Map(invalid.Violations,
+				func(v *validate.Violation, _ int) *errdetails.BadRequest_FieldViolation {
+					return grpcerror.FieldViolation(v.FieldPath, v.Message)
+				})...)
+		}
+
+		return handler(ctx, req)
+	}, nil
+}
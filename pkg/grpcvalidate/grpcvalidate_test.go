@@ -0,0 +1,102 @@
+package grpcvalidate_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcvalidate"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func validOrder() *gen.PlaceOrderRequest {
+	return &gen.PlaceOrderRequest{
+		Location:        2,
+		LoyaltyMemberId: "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		BaristaItems:    []*gen.CommandItem{{ItemType: 5}},
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	t.Parallel()
+
+	interceptor, err := grpcvalidate.UnaryServerInterceptor("counter-service")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(*gen.PlaceOrderRequest)
+		fields []string
+	}{
+		{
+			name:   "valid",
+			modify: func(*gen.PlaceOrderRequest) {},
+		},
+		{
+			name:   "item types past the enum are left to the catalog",
+			modify: func(r *gen.PlaceOrderRequest) { r.KitchenItems = []*gen.CommandItem{{ItemType: 42}} },
+		},
+		{
+			name: "out of range",
+			modify: func(r *gen.PlaceOrderRequest) {
+				r.Location = 7
+				r.BaristaItems[0].ItemType = -1
+			},
+			fields: []string{"location", "barista_items[0].item_type"},
+		},
+		{
+			name:   "not a uuid",
+			modify: func(r *gen.PlaceOrderRequest) { r.LoyaltyMemberId = "42" },
+			fields: []string{"loyalty_member_id"},
+		},
+		{
+			name:   "no items",
+			modify: func(r *gen.PlaceOrderRequest) { r.BaristaItems = nil },
+			fields: []string{""},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := validOrder()
+			tt.modify(req)
+
+			handled := false
+			_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{},
+				func(context.Context, any) (any, error) {
+					handled = true
+
+					return &gen.PlaceOrderResponse{}, nil
+				})
+
+			if len(tt.fields) == 0 {
+				assert.NoError(t, err)
+				assert.True(t, handled)
+
+				return
+			}
+
+			assert.False(t, handled)
+
+			st := status.Convert(err)
+			assert.Equal(t, codes.InvalidArgument, st.Code())
+
+			badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+			assert.True(t, ok)
+
+			fields := make([]string, 0, len(badRequest.FieldViolations))
+			for _, v := range badRequest.FieldViolations {
+				fields = append(fields, v.Field)
+			}
+
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+}
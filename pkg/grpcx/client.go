@@ -0,0 +1,185 @@
+package grpcx
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	_defaultCallTimeout = 10 * time.Second
+	_clientPingInterval = 30 * time.Second
+)
+
+type client struct {
+	timeout time.Duration
+	tls     configs.TLS
+	dialer  func(ctx context.Context, addr string) (net.Conn, error)
+	unary   []grpc.UnaryClientInterceptor
+}
+
+// Dial connects to a service with DialOptions.
+func Dial(target string, opts ...ClientOption) (*grpc.ClientConn, error) {
+	dialOpts, err := DialOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := grpc.Dial(target, dialOpts...)
+	if err != nil {
+		return nil, errors.Wrap(err, "grpc.Dial")
+	}
+
+	return conn, nil
+}
+
+// DialOptions trace and measure the calls, give the ones without a deadline the configured timeout, log them, and retry
+// the methods which are declared idempotent in their protos while the service is unavailable.
+func DialOptions(opts ...ClientOption) ([]grpc.DialOption, error) {
+	c := &client{timeout: _defaultCallTimeout}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	creds, err := clientCredentials(c.tls)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceConfig, err := retryServiceConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(
+			append([]grpc.UnaryClientInterceptor{clientMetrics.UnaryClientInterceptor(), timeoutCall(c.timeout), logCall}, c.unary...)...,
+		),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                _clientPingInterval,
+			Timeout:             _pingTimeout,
+			PermitWithoutStream: true,
+		}),
+	}
+
+	if c.dialer != nil {
+		dialOpts = append(dialOpts, grpc.WithContextDialer(c.dialer))
+	}
+
+	return dialOpts, nil
+}
+
+func timeoutCall(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func logCall(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	slog.Debug("called", "method", method, "target", cc.Target(),
+		"code", status.Code(err).String(), "duration", time.Since(start))
+
+	return err
+}
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+// retryServiceConfig retries the methods with an idempotency_level of every registered proto,
+// the others can't tell whether a failed call took effect.
+func retryServiceConfig() (string, error) {
+	names := make([]methodName, 0)
+
+	protoregistry.GlobalFiles.RangeFiles(func(file protoreflect.FileDescriptor) bool {
+		for i := 0; i < file.Services().Len(); i++ {
+			service := file.Services().Get(i)
+
+			for j := 0; j < service.Methods().Len(); j++ {
+				method := service.Methods().Get(j)
+
+				opts, ok := method.Options().(*descriptorpb.MethodOptions)
+				if ok && opts.GetIdempotencyLevel() != descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN {
+					names = append(names, methodName{Service: string(service.FullName()), Method: string(method.Name())})
+				}
+			}
+		}
+
+		return true
+	})
+
+	if len(names) == 0 {
+		return "{}", nil
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if names[i].Service != names[j].Service {
+			return names[i].Service < names[j].Service
+		}
+
+		return names[i].Method < names[j].Method
+	})
+
+	serviceConfig := map[string]any{
+		"methodConfig": []map[string]any{{
+			"name": names,
+			"retryPolicy": map[string]any{
+				"maxAttempts":          3,
+				"initialBackoff":       "0.1s",
+				"maxBackoff":           "1s",
+				"backoffMultiplier":    2,
+				"retryableStatusCodes": []string{"UNAVAILABLE"},
+			},
+		}},
+	}
+
+	data, err := json.Marshal(serviceConfig)
+	if err != nil {
+		return "", errors.Wrap(err, "json.Marshal")
+	}
+
+	return string(data), nil
+}
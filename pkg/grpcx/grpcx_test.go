@@ -0,0 +1,114 @@
+package grpcx_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeProductServer struct {
+	gen.UnimplementedProductServiceServer
+
+	mu          sync.Mutex
+	unavailable int
+	calls       int
+	deadline    bool
+}
+
+func (f *fakeProductServer) GetItemTypes(ctx context.Context, _ *gen.GetItemTypesRequest) (*gen.GetItemTypesResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	_, f.deadline = ctx.Deadline()
+
+	if f.calls <= f.unavailable {
+		return nil, status.Error(codes.Unavailable, "starting")
+	}
+
+	return &gen.GetItemTypesResponse{}, nil
+}
+
+// ImportCatalog has side effects, so it isn't retried.
+func (f *fakeProductServer) ImportCatalog(context.Context, *gen.ImportCatalogRequest) (*gen.ImportCatalogResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+
+	return nil, status.Error(codes.Unavailable, "starting")
+}
+
+func (f *fakeProductServer) SearchItems(context.Context, *gen.SearchItemsRequest) (*gen.SearchItemsResponse, error) {
+	panic("search is broken")
+}
+
+func serve(t *testing.T, product *fakeProductServer) *grpc.ClientConn {
+	t.Helper()
+
+	server, _, err := grpcx.NewServer(grpcx.ServerConfig(configs.GRPC{Timeout: time.Second}))
+	assert.NoError(t, err)
+
+	gen.RegisterProductServiceServer(server, product)
+
+	listener := bufconn.Listen(1 << 20)
+
+	go func() { _ = server.Serve(listener) }()
+
+	t.Cleanup(server.Stop)
+
+	dialOpts, err := grpcx.DialOptions()
+	assert.NoError(t, err)
+
+	conn, err := grpc.Dial("bufnet", append(dialOpts, grpc.WithContextDialer(
+		func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) },
+	))...)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestServerRecoversAndServesHealth(t *testing.T) {
+	t.Parallel()
+
+	conn := serve(t, &fakeProductServer{})
+
+	_, err := gen.NewProductServiceClient(conn).SearchItems(context.Background(), &gen.SearchItemsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	res, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+}
+
+func TestClientRetriesIdempotentCalls(t *testing.T) {
+	t.Parallel()
+
+	product := &fakeProductServer{unavailable: 2}
+	client := gen.NewProductServiceClient(serve(t, product))
+
+	_, err := client.GetItemTypes(context.Background(), &gen.GetItemTypesRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, product.calls)
+	assert.True(t, product.deadline)
+
+	product.calls = 0
+
+	_, err = client.ImportCatalog(context.Background(), &gen.ImportCatalogRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 1, product.calls)
+}
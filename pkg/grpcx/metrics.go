@@ -0,0 +1,22 @@
+package grpcx
+
+import (
+	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// The metrics are shared by every server and client of the process, they are registered once.
+var (
+	serverMetrics = grpcprom.NewServerMetrics(grpcprom.WithServerHandlingTimeHistogram())
+	clientMetrics = grpcprom.NewClientMetrics(grpcprom.WithClientHandlingTimeHistogram())
+)
+
+func init() {
+	prometheus.MustRegister(serverMetrics, clientMetrics)
+}
+
+// InitializeMetrics reports zero for every method of the registered services, so they show up before the first call.
+func InitializeMetrics(server *grpc.Server) {
+	serverMetrics.InitializeMetrics(server)
+}
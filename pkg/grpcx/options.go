@@ -0,0 +1,63 @@
+package grpcx
+
+import (
+	"context"
+	"net"
+	"time"
+
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"google.golang.org/grpc"
+)
+
+type ServerOption func(*server)
+
+// ServerConfig takes the request timeout and TLS of the service config.
+func ServerConfig(cfg configs.GRPC) ServerOption {
+	return func(s *server) {
+		s.timeout = cfg.Timeout
+		s.tls = cfg.TLS
+	}
+}
+
+// UnaryInterceptors run after the standard ones, in the given order.
+func UnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ServerOption {
+	return func(s *server) {
+		s.unary = append(s.unary, interceptors...)
+	}
+}
+
+// StreamInterceptors run after the standard ones, in the given order.
+func StreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ServerOption {
+	return func(s *server) {
+		s.stream = append(s.stream, interceptors...)
+	}
+}
+
+type ClientOption func(*client)
+
+func ClientTLS(cfg configs.TLS) ClientOption {
+	return func(c *client) {
+		c.tls = cfg
+	}
+}
+
+// CallTimeout is the deadline of the calls which are made without one.
+func CallTimeout(timeout time.Duration) ClientOption {
+	return func(c *client) {
+		c.timeout = timeout
+	}
+}
+
+// Dialer connects with dial instead of the network, e.g. to a bufconn listener of a server in the same process.
+func Dialer(dial func(ctx context.Context, addr string) (net.Conn, error)) ClientOption {
+	return func(c *client) {
+		c.dialer = dial
+	}
+}
+
+// UnaryClientInterceptors run after the standard ones, in the given order.
+func UnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) ClientOption {
+	return func(c *client) {
+		c.unary = append(c.unary, interceptors...)
+	}
+}
@@ -0,0 +1,140 @@
+package grpcx
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	_defaultServerTimeout = 30 * time.Second
+	// _minPingInterval is what the clients may ping, they ping every _clientPingInterval.
+	_minPingInterval    = 10 * time.Second
+	_serverPingInterval = 2 * time.Minute
+	_pingTimeout        = 20 * time.Second
+)
+
+type server struct {
+	timeout time.Duration
+	tls     configs.TLS
+	unary   []grpc.UnaryServerInterceptor
+	stream  []grpc.StreamServerInterceptor
+}
+
+// NewServer builds a gRPC server which traces, measures and logs the requests, recovers from panics and gives the requests
+// without a deadline the configured timeout, before it runs the interceptors of the service.
+// It serves the standard health service, the caller sets it to NOT_SERVING when it stops.
+func NewServer(opts ...ServerOption) (*grpc.Server, *health.Server, error) {
+	s := &server{timeout: _defaultServerTimeout}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{
+			serverMetrics.UnaryServerInterceptor(),
+			recoverUnary,
+			logUnary,
+			timeoutUnary(s.timeout),
+		}, s.unary...)...),
+		grpc.ChainStreamInterceptor(append([]grpc.StreamServerInterceptor{
+			serverMetrics.StreamServerInterceptor(),
+			recoverStream,
+		}, s.stream...)...),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:    _serverPingInterval,
+			Timeout: _pingTimeout,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             _minPingInterval,
+			PermitWithoutStream: true,
+		}),
+	}
+
+	if s.tls.Enabled {
+		creds, err := serverCredentials(s.tls)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+
+	grpcServer := grpc.NewServer(serverOpts...)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	return grpcServer, healthServer, nil
+}
+
+func recoverUnary(
+	ctx context.Context,
+	req any,
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("recovered from a panic", "panic", r, "method", info.FullMethod, "stack", string(debug.Stack()))
+
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("recovered from a panic", "panic", r, "method", info.FullMethod, "stack", string(debug.Stack()))
+
+			err = status.Error(codes.Internal, "internal error")
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	args := []any{"method", info.FullMethod, "code", code.String(), "duration", time.Since(start)}
+
+	if code == codes.OK {
+		slog.Info("handled request", args...)
+	} else {
+		slog.Warn("handled request", append(args, "error", err)...)
+	}
+
+	return resp, err
+}
+
+func timeoutUnary(timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
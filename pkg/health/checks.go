@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+var ErrConnectionClosed = errors.New("connection closed")
+
+// SQL pings the database.
+func SQL(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return errors.Wrap(db.PingContext(ctx), "db.PingContext")
+	}
+}
+
+// AMQP opens and closes a channel, the broker may refuse it even though the connection is open.
+func AMQP(conn *amqp.Connection) Check {
+	return func(context.Context) error {
+		if conn.IsClosed() {
+			return ErrConnectionClosed
+		}
+
+		ch, err := conn.Channel()
+		if err != nil {
+			return errors.Wrap(err, "conn.Channel")
+		}
+
+		return errors.Wrap(ch.Close(), "ch.Close")
+	}
+}
+
+// GRPC asks the health service of a downstream service whether it serves.
+func GRPC(conn *grpc.ClientConn) Check {
+	return func(ctx context.Context) error {
+		res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil {
+			return errors.Wrap(err, "health.Check")
+		}
+
+		if res.Status != healthpb.HealthCheckResponse_SERVING {
+			return errors.Errorf("%s is %s", conn.Target(), res.Status)
+		}
+
+		return nil
+	}
+}
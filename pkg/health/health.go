@@ -0,0 +1,199 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	_defaultTimeout = 2 * time.Second
+)
+
+// Check tells whether a dependency can be used, the error says why not.
+type Check func(context.Context) error
+
+// Result is the outcome of the check of one dependency.
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+	// Optional dependencies are reported, but the service is up without them.
+	Optional bool `json:"optional,omitempty"`
+}
+
+// Report is the readiness of a service, it is up when all of its dependencies are.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+var ErrNotReady = errors.New("not ready")
+
+type Option func(*Checker)
+
+// Timeout bounds every check, a dependency which doesn't answer in time is down.
+func Timeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		c.timeout = timeout
+	}
+}
+
+// Checker runs the checks of the dependencies of a service.
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]registered
+}
+
+type registered struct {
+	check    Check
+	optional bool
+}
+
+func NewChecker(opts ...Option) *Checker {
+	c := &Checker{
+		timeout: _defaultTimeout,
+		checks:  make(map[string]registered),
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Register adds the check of a dependency, the name is its key in the report.
+func (c *Checker) Register(name string, check Check) {
+	c.register(name, registered{check: check})
+}
+
+// RegisterOptional adds the check of a dependency the service can do without for a while, e.g. behind a cache.
+func (c *Checker) RegisterOptional(name string, check Check) {
+	c.register(name, registered{check: check, optional: true})
+}
+
+func (c *Checker) register(name string, r registered) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks[name] = r
+}
+
+// Check runs all the checks at once, a service without any is up.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := make(map[string]registered, len(c.checks))
+
+	for name, r := range c.checks {
+		checks[name] = r
+	}
+	c.mu.RUnlock()
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+
+	for name, r := range checks {
+		wg.Add(1)
+
+		go func(name string, r registered) {
+			defer wg.Done()
+
+			result := c.run(ctx, r.check)
+			result.Optional = r.optional
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[name] = result
+			if result.Status != StatusUp && !r.optional {
+				report.Status = StatusDown
+			}
+		}(name, r)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+func (c *Checker) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := Result{Status: StatusUp, Duration: time.Since(start).String()}
+
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// Ready is the readiness of the whole service as the check of a dependency,
+// so a process running several services reports each of them under its name.
+func (c *Checker) Ready(ctx context.Context) error {
+	report := c.Check(ctx)
+	if report.Status == StatusUp {
+		return nil
+	}
+
+	return errors.Wrapf(ErrNotReady, "%s down", strings.Join(down(report), ", "))
+}
+
+// Watch keeps the overall status of the gRPC health service in line with the checks until ctx is done,
+// so the clients which probe over gRPC see what /readyz reports.
+func (c *Checker) Watch(ctx context.Context, interval time.Duration, server *health.Server) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		report := c.Check(ctx)
+
+		if report.Status == StatusUp {
+			server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		} else {
+			slog.Warn("not ready", "down", down(report))
+			server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func down(report Report) []string {
+	names := make([]string, 0)
+
+	for name, result := range report.Checks {
+		if result.Status != StatusUp {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,101 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func up(context.Context) error { return nil }
+
+func down(context.Context) error { return errors.New("connection refused") }
+
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func TestReadyReportsEveryDependency(t *testing.T) {
+	t.Parallel()
+
+	checker := health.NewChecker(health.Timeout(10 * time.Millisecond))
+	checker.Register("postgres", up)
+	checker.RegisterOptional("product", down)
+
+	rec := httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var report health.Report
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Equal(t, health.StatusUp, report.Status)
+	assert.Equal(t, health.StatusUp, report.Checks["postgres"].Status)
+	assert.Equal(t, health.StatusDown, report.Checks["product"].Status)
+	assert.Equal(t, "connection refused", report.Checks["product"].Error)
+
+	// a dependency which doesn't answer in time is down, the required ones take the service down
+	checker.Register("rabbitmq", hang)
+
+	rec = httptest.NewRecorder()
+	checker.ReadyHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	assert.Equal(t, health.StatusDown, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["rabbitmq"].Error)
+}
+
+func TestReadyNamesTheDependenciesWhichAreDown(t *testing.T) {
+	t.Parallel()
+
+	counter := health.NewChecker()
+	counter.Register("postgres", up)
+	counter.RegisterOptional("product", down)
+
+	assert.NoError(t, counter.Ready(context.Background()))
+
+	counter.Register("broker", down)
+
+	err := counter.Ready(context.Background())
+	assert.ErrorIs(t, err, health.ErrNotReady)
+	assert.EqualError(t, err, "broker, product down: not ready")
+}
+
+func TestWatchSetsTheGRPCStatus(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checker := health.NewChecker()
+	checker.Register("consumer", down)
+
+	server := grpchealth.NewServer()
+
+	go checker.Watch(ctx, time.Millisecond, server)
+
+	assert.Eventually(t, func() bool {
+		res, err := server.Check(ctx, &healthpb.HealthCheckRequest{})
+
+		return err == nil && res.Status == healthpb.HealthCheckResponse_NOT_SERVING
+	}, time.Second, time.Millisecond)
+
+	checker.Register("consumer", up)
+
+	assert.Eventually(t, func() bool {
+		res, err := server.Check(ctx, &healthpb.HealthCheckRequest{})
+
+		return err == nil && res.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,39 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/exp/slog"
+)
+
+// LiveHandler answers as long as the process serves HTTP. It doesn't look at the dependencies,
+// an orchestrator restarts a service which isn't live and that doesn't fix a database which is down.
+func LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+	})
+}
+
+// ReadyHandler answers 200 with the report when all the dependencies are up, 503 otherwise.
+func (c *Checker) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Check(r.Context())
+
+		code := http.StatusOK
+		if report.Status != StatusUp {
+			code = http.StatusServiceUnavailable
+		}
+
+		writeJSON(w, code, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("failed to write the health report", "error", err)
+	}
+}
@@ -5,6 +5,7 @@ package logger
 // https://thedevelopercafe.com/articles/logging-in-go-with-slog-a7bb489755c2
 
 import (
+	"context"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -40,28 +41,30 @@ func ConvertLogLevel(level string) logrus.Level {
 	return l
 }
 
-func (h *LogrusHandler) Enabled(_ slog.Level) bool {
+func (h *LogrusHandler) Enabled(_ context.Context, _ slog.Level) bool {
 	// support all logging levels
 	return true
 }
 
-func (h *LogrusHandler) Handle(rec slog.Record) error {
+func (h *LogrusHandler) Handle(_ context.Context, rec slog.Record) error {
 	fields := make(map[string]interface{}, rec.NumAttrs())
 
-	rec.Attrs(func(a slog.Attr) {
+	rec.Attrs(func(a slog.Attr) bool {
 		fields[a.Key] = a.Value.Any()
+
+		return true
 	})
 
 	entry := h.logger.WithFields(fields)
 
 	switch rec.Level {
-	case slog.DebugLevel:
+	case slog.LevelDebug:
 		entry.Debug(rec.Message)
-	case slog.InfoLevel.Level():
+	case slog.LevelInfo:
 		entry.Info(rec.Message)
-	case slog.WarnLevel:
+	case slog.LevelWarn:
 		entry.Warn(rec.Message)
-	case slog.ErrorLevel:
+	case slog.LevelError:
 		entry.Error(rec.Message)
 	}
 
@@ -0,0 +1,221 @@
+// Package inmem is a message bus inside the process with the routing of RabbitMQ direct exchanges.
+// A message published to an exchange is copied to every queue bound to it with the routing key,
+// the subscribers of a queue compete for its messages and a message nacked or rejected
+// with requeue goes back to the head of its queue as redelivered.
+package inmem
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+)
+
+const (
+	_system = "inmem"
+
+	_defaultWorkerPoolSize = 4
+	_idlePollInterval      = 5 * time.Millisecond
+)
+
+var (
+	ErrClosed          = errors.New("bus closed")
+	ErrUnknownDelivery = errors.New("unknown delivery tag")
+)
+
+type binding struct {
+	exchange, key string
+}
+
+type message struct {
+	id, messageType, contentType string
+	exchange, routingKey         string
+	headers                      map[string]any
+	body                         []byte
+	timestamp                    time.Time
+	redelivered                  bool
+}
+
+var _ messaging.Broker = (*Bus)(nil)
+
+type Bus struct {
+	mu        sync.Mutex
+	queues    map[string]*queue
+	bindings  map[binding][]*queue
+	transient int
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		queues:   make(map[string]*queue),
+		bindings: make(map[binding][]*queue),
+		closed:   make(chan struct{}),
+	}
+}
+
+// Close ends the subscriptions, the publishers fail with ErrClosed from now on.
+func (b *Bus) Close() {
+	b.closeOnce.Do(func() {
+		close(b.closed)
+	})
+}
+
+// Check fails with ErrClosed once the bus is closed.
+func (b *Bus) Check(context.Context) error {
+	if b.isClosed() {
+		return ErrClosed
+	}
+
+	return nil
+}
+
+// WaitIdle waits until every queue is empty and every delivery is settled.
+func (b *Bus) WaitIdle(ctx context.Context) error {
+	ticker := time.NewTicker(_idlePollInterval)
+	defer ticker.Stop()
+
+	for !b.idle() {
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "inmem.WaitIdle")
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (b *Bus) idle() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, q := range b.queues {
+		if !q.idle() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Bus) isClosed() bool {
+	select {
+	case <-b.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// declare creates the queue of cfg unless it exists and binds it to the exchange,
+// a transient queue is always a new one.
+func (b *Bus) declare(cfg messaging.Config) *queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	name := cfg.QueueName
+	if cfg.Transient || name == "" {
+		b.transient++
+		name = "inmem.gen-" + strconv.Itoa(b.transient)
+	}
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = newQueue(name)
+		b.queues[name] = q
+	}
+
+	key := binding{exchange: cfg.ExchangeName, key: cfg.BindingKey}
+	for _, bound := range b.bindings[key] {
+		if bound == q {
+			return q
+		}
+	}
+
+	b.bindings[key] = append(b.bindings[key], q)
+
+	return q
+}
+
+func (b *Bus) delete(q *queue) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.queues, q.name)
+
+	for key, queues := range b.bindings {
+		for i, bound := range queues {
+			if bound == q {
+				b.bindings[key] = append(queues[:i:i], queues[i+1:]...)
+
+				break
+			}
+		}
+	}
+}
+
+// route copies m to the queues bound to its exchange with its routing key, it is dropped when there are none.
+func (b *Bus) route(m *message) {
+	b.mu.Lock()
+	queues := append([]*queue(nil), b.bindings[binding{exchange: m.exchange, key: m.routingKey}]...)
+	b.mu.Unlock()
+
+	for _, q := range queues {
+		copied := *m
+		q.push(&copied)
+	}
+}
+
+type publisher struct {
+	bus *Bus
+	cfg messaging.Config
+}
+
+var _ messaging.Publisher = (*publisher)(nil)
+
+func (b *Bus) NewPublisher(opts ...messaging.Option) (messaging.Publisher, error) {
+	if b.isClosed() {
+		return nil, ErrClosed
+	}
+
+	return (&publisher{bus: b}).Configure(opts...), nil
+}
+
+func (p *publisher) Configure(opts ...messaging.Option) messaging.Publisher {
+	for _, opt := range opts {
+		opt(&p.cfg)
+	}
+
+	return p
+}
+
+func (p *publisher) Publish(ctx context.Context, body []byte, contentType string) error {
+	if p.bus.isClosed() {
+		return ErrClosed
+	}
+
+	headers := map[string]any{}
+
+	_, span := messaging.StartPublishSpan(ctx, _system, p.cfg.ExchangeName, p.cfg.BindingKey, headers)
+	defer span.End()
+
+	p.bus.route(&message{
+		id:          uuid.New().String(),
+		messageType: p.cfg.MessageTypeName,
+		contentType: contentType,
+		exchange:    p.cfg.ExchangeName,
+		routingKey:  p.cfg.BindingKey,
+		headers:     headers,
+		body:        append([]byte(nil), body...),
+		timestamp:   time.Now(),
+	})
+
+	return nil
+}
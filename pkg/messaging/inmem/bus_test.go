@@ -0,0 +1,200 @@
+package inmem_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+)
+
+const _timeout = 5 * time.Second
+
+// recorder acks every delivery and keeps their bodies.
+type recorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (r *recorder) Worker(_ context.Context, deliveries <-chan messaging.Delivery) {
+	for d := range deliveries {
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(d.Body))
+		r.mu.Unlock()
+
+		_ = d.Ack(false)
+	}
+}
+
+func (r *recorder) Bodies() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.bodies...)
+}
+
+func subscribe(t *testing.T, sub messaging.Subscriber, fn messaging.Worker) {
+	t.Helper()
+
+	go func() {
+		assert.NoError(t, sub.Subscribe(fn))
+	}()
+
+	require.Eventually(t, func() bool { return sub.Check(context.Background()) == nil }, _timeout, time.Millisecond)
+}
+
+func newPublisher(t *testing.T, bus *inmem.Bus, opts ...messaging.Option) messaging.Publisher {
+	t.Helper()
+
+	pub, err := bus.NewPublisher(opts...)
+	require.NoError(t, err)
+
+	return pub
+}
+
+func newSubscriber(t *testing.T, bus *inmem.Bus, opts ...messaging.Option) messaging.Subscriber {
+	t.Helper()
+
+	sub, err := bus.NewSubscriber(opts...)
+	require.NoError(t, err)
+
+	return sub
+}
+
+func waitIdle(t *testing.T, bus *inmem.Bus) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), _timeout)
+	defer cancel()
+
+	require.NoError(t, bus.WaitIdle(ctx))
+}
+
+func TestPublishIsRoutedByExchangeAndRoutingKey(t *testing.T) {
+	t.Parallel()
+
+	bus := inmem.NewBus()
+	defer bus.Close()
+
+	barista, kitchen, audit := &recorder{}, &recorder{}, &recorder{}
+
+	subscribe(t, newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.QueueName("barista"),
+		messaging.BindingKey("barista")), barista.Worker)
+	subscribe(t, newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.QueueName("kitchen"),
+		messaging.BindingKey("kitchen")), kitchen.Worker)
+	subscribe(t, newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.BindingKey("barista"),
+		messaging.TransientQueue()), audit.Worker)
+
+	pub := newPublisher(t, bus, messaging.ExchangeName("orders"), messaging.BindingKey("barista"))
+	require.NoError(t, pub.Publish(context.Background(), []byte("latte"), "text/plain"))
+
+	pub.Configure(messaging.BindingKey("kitchen"))
+	require.NoError(t, pub.Publish(context.Background(), []byte("croissant"), "text/plain"))
+
+	waitIdle(t, bus)
+
+	assert.Equal(t, []string{"latte"}, barista.Bodies())
+	assert.Equal(t, []string{"croissant"}, kitchen.Bodies())
+	assert.Equal(t, []string{"latte"}, audit.Bodies())
+}
+
+func TestNamedQueueKeepsMessagesUntilSubscribed(t *testing.T) {
+	t.Parallel()
+
+	bus := inmem.NewBus()
+	defer bus.Close()
+
+	sub := newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.QueueName("counter"), messaging.BindingKey("counter"))
+	assert.ErrorIs(t, sub.Check(context.Background()), messaging.ErrNotConsuming)
+
+	pub := newPublisher(t, bus, messaging.ExchangeName("orders"), messaging.BindingKey("counter"))
+	require.NoError(t, pub.Publish(context.Background(), []byte("order-1"), "text/plain"))
+
+	counter := &recorder{}
+	subscribe(t, sub, counter.Worker)
+	waitIdle(t, bus)
+
+	assert.Equal(t, []string{"order-1"}, counter.Bodies())
+}
+
+func TestRequeuedMessageIsRedelivered(t *testing.T) {
+	t.Parallel()
+
+	bus := inmem.NewBus()
+	defer bus.Close()
+
+	var (
+		mu       sync.Mutex
+		attempts []bool
+	)
+
+	// the first attempt fails and goes back to the queue, a rejected message without requeue is gone
+	worker := func(_ context.Context, deliveries <-chan messaging.Delivery) {
+		for d := range deliveries {
+			mu.Lock()
+			attempts = append(attempts, d.Redelivered)
+			mu.Unlock()
+
+			switch {
+			case string(d.Body) == "poison":
+				assert.NoError(t, d.Reject(false))
+			case !d.Redelivered:
+				assert.NoError(t, d.Nack(false, true))
+			default:
+				assert.NoError(t, d.Ack(false))
+				assert.ErrorIs(t, d.Ack(false), inmem.ErrUnknownDelivery)
+			}
+		}
+	}
+
+	subscribe(t, newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.QueueName("q"),
+		messaging.BindingKey("k"), messaging.WorkerPoolSize(1)), worker)
+
+	pub := newPublisher(t, bus, messaging.ExchangeName("orders"), messaging.BindingKey("k"))
+	require.NoError(t, pub.Publish(context.Background(), []byte("order"), "text/plain"))
+	waitIdle(t, bus)
+
+	require.NoError(t, pub.Publish(context.Background(), []byte("poison"), "text/plain"))
+	waitIdle(t, bus)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []bool{false, true, false}, attempts)
+}
+
+func TestCloseEndsTheSubscriptions(t *testing.T) {
+	t.Parallel()
+
+	bus := inmem.NewBus()
+	sub := newSubscriber(t, bus, messaging.ExchangeName("orders"), messaging.TransientQueue())
+	pub := newPublisher(t, bus, messaging.ExchangeName("orders"))
+
+	done := make(chan error)
+
+	go func() {
+		done <- sub.Subscribe((&recorder{}).Worker)
+	}()
+
+	require.Eventually(t, func() bool { return sub.Check(context.Background()) == nil }, _timeout, time.Millisecond)
+
+	bus.Close()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(_timeout):
+		t.Fatal("Subscribe didn't return after Close")
+	}
+
+	assert.ErrorIs(t, sub.Check(context.Background()), messaging.ErrNotConsuming)
+	assert.ErrorIs(t, pub.Publish(context.Background(), nil, "text/plain"), inmem.ErrClosed)
+	assert.ErrorIs(t, bus.Check(context.Background()), inmem.ErrClosed)
+
+	_, err := bus.NewSubscriber()
+	assert.ErrorIs(t, err, inmem.ErrClosed)
+}
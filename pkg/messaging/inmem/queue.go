@@ -0,0 +1,80 @@
+package inmem
+
+import "sync"
+
+type queue struct {
+	name string
+
+	mu      sync.Mutex
+	ready   []*message
+	nextTag uint64
+	// inFlight counts the deliveries handed to the workers which they haven't settled yet
+	inFlight int
+	// wake tells a waiting subscriber there is something to pop
+	wake chan struct{}
+}
+
+func newQueue(name string) *queue {
+	return &queue{
+		name: name,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func (q *queue) push(m *message) {
+	q.mu.Lock()
+	q.ready = append(q.ready, m)
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+// requeue puts back the messages at the head of the queue in their order, then settles them.
+func (q *queue) requeue(messages []*message) {
+	q.mu.Lock()
+	for _, m := range messages {
+		m.redelivered = true
+	}
+
+	q.ready = append(append([]*message(nil), messages...), q.ready...)
+	q.inFlight -= len(messages)
+	q.mu.Unlock()
+
+	q.signal()
+}
+
+func (q *queue) pop() (*message, uint64, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.ready) == 0 {
+		return nil, 0, false
+	}
+
+	m := q.ready[0]
+	q.ready = q.ready[1:]
+	q.nextTag++
+	q.inFlight++
+
+	return m, q.nextTag, true
+}
+
+func (q *queue) settle(n int) {
+	q.mu.Lock()
+	q.inFlight -= n
+	q.mu.Unlock()
+}
+
+func (q *queue) idle() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.ready) == 0 && q.inFlight == 0
+}
+
+func (q *queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
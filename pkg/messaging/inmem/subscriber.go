@@ -0,0 +1,241 @@
+package inmem
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+)
+
+type subscriber struct {
+	bus *Bus
+	cfg messaging.Config
+
+	mu      sync.Mutex
+	running bool
+}
+
+var _ messaging.Subscriber = (*subscriber)(nil)
+
+// NewSubscriber declares the queue right away when it is named,
+// so it keeps the messages published before Subscribe like a durable RabbitMQ queue.
+func (b *Bus) NewSubscriber(opts ...messaging.Option) (messaging.Subscriber, error) {
+	if b.isClosed() {
+		return nil, ErrClosed
+	}
+
+	s := &subscriber{
+		bus: b,
+		cfg: messaging.Config{WorkerPoolSize: _defaultWorkerPoolSize},
+	}
+
+	return s.Configure(opts...), nil
+}
+
+func (s *subscriber) Configure(opts ...messaging.Option) messaging.Subscriber {
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+
+	if s.cfg.QueueName != "" && !s.cfg.Transient {
+		s.bus.declare(s.cfg)
+	}
+
+	return s
+}
+
+// Subscribe hands the deliveries to a pool of workers until the bus is closed,
+// the deliveries left unsettled then go back to the queue.
+func (s *subscriber) Subscribe(fn messaging.Worker) error {
+	if s.bus.isClosed() {
+		return ErrClosed
+	}
+
+	q := s.bus.declare(s.cfg)
+	if s.cfg.Transient {
+		defer s.bus.delete(q)
+	}
+
+	sub := &subscription{queue: q, unacked: make(map[uint64]*message)}
+	deliveries := make(chan messaging.Delivery)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.cfg.WorkerPoolSize; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			fn(ctx, deliveries)
+		}()
+	}
+
+	s.setRunning(true)
+	defer s.setRunning(false)
+
+	s.dispatch(q, sub, deliveries)
+
+	close(deliveries)
+	wg.Wait()
+	sub.requeueAll()
+
+	return nil
+}
+
+func (s *subscriber) dispatch(q *queue, sub *subscription, deliveries chan<- messaging.Delivery) {
+	for {
+		m, tag, ok := q.pop()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-s.bus.closed:
+				return
+			}
+		}
+
+		sub.track(tag, m)
+
+		select {
+		case deliveries <- sub.delivery(tag, m):
+		case <-s.bus.closed:
+			_ = sub.Reject(tag, true)
+
+			return
+		}
+	}
+}
+
+func (s *subscriber) Check(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running || s.bus.isClosed() {
+		return errors.Wrapf(messaging.ErrNotConsuming, "queue %s", s.cfg.QueueName)
+	}
+
+	return nil
+}
+
+func (s *subscriber) setRunning(running bool) {
+	s.mu.Lock()
+	s.running = running
+	s.mu.Unlock()
+}
+
+// subscription settles the deliveries of one Subscribe call, like the channel of a RabbitMQ consumer.
+type subscription struct {
+	queue *queue
+
+	mu      sync.Mutex
+	unacked map[uint64]*message
+}
+
+var _ messaging.Acknowledger = (*subscription)(nil)
+
+func (s *subscription) track(tag uint64, m *message) {
+	s.mu.Lock()
+	s.unacked[tag] = m
+	s.mu.Unlock()
+}
+
+func (s *subscription) delivery(tag uint64, m *message) messaging.Delivery {
+	return messaging.Delivery{
+		Acknowledger: s,
+		Headers:      m.headers,
+		ContentType:  m.contentType,
+		MessageID:    m.id,
+		Timestamp:    m.timestamp,
+		Type:         m.messageType,
+		Body:         m.body,
+		DeliveryTag:  tag,
+		Redelivered:  m.redelivered,
+		Exchange:     m.exchange,
+		RoutingKey:   m.routingKey,
+		System:       _system,
+	}
+}
+
+func (s *subscription) Ack(tag uint64, multiple bool) error {
+	messages, err := s.take(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	s.queue.settle(len(messages))
+
+	return nil
+}
+
+func (s *subscription) Nack(tag uint64, multiple, requeue bool) error {
+	messages, err := s.take(tag, multiple)
+	if err != nil {
+		return err
+	}
+
+	if requeue {
+		s.queue.requeue(messages)
+	} else {
+		s.queue.settle(len(messages))
+	}
+
+	return nil
+}
+
+func (s *subscription) Reject(tag uint64, requeue bool) error {
+	return s.Nack(tag, false, requeue)
+}
+
+// take removes the unacked deliveries up to tag, or only tag, in their order.
+func (s *subscription) take(tag uint64, multiple bool) ([]*message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := make([]uint64, 0, 1)
+
+	for t := range s.unacked {
+		if t == tag || (multiple && t < tag) {
+			tags = append(tags, t)
+		}
+	}
+
+	if len(tags) == 0 {
+		return nil, errors.Wrapf(ErrUnknownDelivery, "tag %d", tag)
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	messages := make([]*message, 0, len(tags))
+
+	for _, t := range tags {
+		messages = append(messages, s.unacked[t])
+		delete(s.unacked, t)
+	}
+
+	return messages, nil
+}
+
+func (s *subscription) requeueAll() {
+	s.mu.Lock()
+	tags := make([]uint64, 0, len(s.unacked))
+
+	for t := range s.unacked {
+		tags = append(tags, t)
+	}
+	s.mu.Unlock()
+
+	if len(tags) == 0 {
+		return
+	}
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	// the last tag takes all the others with it
+	_ = s.Nack(tags[len(tags)-1], true, true)
+}
@@ -0,0 +1,103 @@
+// Package messaging is what the services see of the message broker,
+// pkg/rabbitmq implements it with RabbitMQ and pkg/messaging/inmem in process.
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNotConsuming   = errors.New("not consuming")
+	ErrNoAcknowledger = errors.New("delivery has no acknowledger")
+)
+
+// Acknowledger settles the deliveries of a broker, *amqp.Channel is one.
+type Acknowledger interface {
+	Ack(tag uint64, multiple bool) error
+	Nack(tag uint64, multiple, requeue bool) error
+	Reject(tag uint64, requeue bool) error
+}
+
+// Delivery is a message handed to a worker, the worker settles it with Ack, Nack or Reject.
+type Delivery struct {
+	Acknowledger Acknowledger
+
+	Headers     map[string]any
+	ContentType string
+	MessageID   string
+	Timestamp   time.Time
+	Type        string
+	Body        []byte
+
+	DeliveryTag uint64
+	Redelivered bool
+	Exchange    string
+	RoutingKey  string
+	// System names the broker the delivery comes from, e.g. rabbitmq.
+	System string
+}
+
+func (d Delivery) Ack(multiple bool) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+
+	return d.Acknowledger.Ack(d.DeliveryTag, multiple)
+}
+
+func (d Delivery) Nack(multiple, requeue bool) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+
+	return d.Acknowledger.Nack(d.DeliveryTag, multiple, requeue)
+}
+
+func (d Delivery) Reject(requeue bool) error {
+	if d.Acknowledger == nil {
+		return ErrNoAcknowledger
+	}
+
+	return d.Acknowledger.Reject(d.DeliveryTag, requeue)
+}
+
+// Worker handles deliveries until the channel is closed, a subscriber runs a pool of them.
+type Worker func(ctx context.Context, deliveries <-chan Delivery)
+
+type Publisher interface {
+	Configure(...Option) Publisher
+	Publish(ctx context.Context, body []byte, contentType string) error
+}
+
+type Subscriber interface {
+	Configure(...Option) Subscriber
+	// Subscribe hands the deliveries of the queue to the workers, it blocks until the subscription ends.
+	Subscribe(fn Worker) error
+	// Check fails with ErrNotConsuming unless the subscription is running.
+	Check(context.Context) error
+}
+
+// Broker makes the publishers and subscribers of one connection to the message broker.
+type Broker interface {
+	NewPublisher(...Option) (Publisher, error)
+	NewSubscriber(...Option) (Subscriber, error)
+	// Check fails when the broker can't be reached.
+	Check(context.Context) error
+}
+
+var (
+	PublisherSet  = wire.NewSet(NewPublisher)
+	SubscriberSet = wire.NewSet(NewSubscriber)
+)
+
+func NewPublisher(broker Broker) (Publisher, error) {
+	return broker.NewPublisher()
+}
+
+func NewSubscriber(broker Broker) (Subscriber, error) {
+	return broker.NewSubscriber()
+}
@@ -0,0 +1,60 @@
+package messaging
+
+// Config is the routing of a publisher or a subscriber, the publishers ignore the queue settings.
+type Config struct {
+	ExchangeName    string
+	QueueName       string
+	BindingKey      string
+	MessageTypeName string
+	ConsumerTag     string
+	WorkerPoolSize  int
+	// Transient queues belong to one subscriber and are gone with it.
+	Transient bool
+}
+
+type Option func(*Config)
+
+func ExchangeName(exchangeName string) Option {
+	return func(c *Config) {
+		c.ExchangeName = exchangeName
+	}
+}
+
+func QueueName(queueName string) Option {
+	return func(c *Config) {
+		c.QueueName = queueName
+	}
+}
+
+func BindingKey(bindingKey string) Option {
+	return func(c *Config) {
+		c.BindingKey = bindingKey
+	}
+}
+
+func MessageTypeName(messageTypeName string) Option {
+	return func(c *Config) {
+		c.MessageTypeName = messageTypeName
+	}
+}
+
+func ConsumerTag(consumerTag string) Option {
+	return func(c *Config) {
+		c.ConsumerTag = consumerTag
+	}
+}
+
+func WorkerPoolSize(workerPoolSize int) Option {
+	return func(c *Config) {
+		c.WorkerPoolSize = workerPoolSize
+	}
+}
+
+// TransientQueue makes every subscriber get its own queue, which is gone with it,
+// so each instance of a service sees every message, e.g. to drop its caches.
+func TransientQueue() Option {
+	return func(c *Config) {
+		c.QueueName = ""
+		c.Transient = true
+	}
+}
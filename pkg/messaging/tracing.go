@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const _tracerName = "github.com/thangchung/go-coffeeshop/pkg/messaging"
+
+// headerCarrier passes the trace context in the headers of a message.
+type headerCarrier map[string]any
+
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+
+	return value
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// StartPublishSpan starts the span of a message sent to exchange of the system broker
+// and puts its trace context into headers.
+func StartPublishSpan(
+	ctx context.Context,
+	system, exchange, routingKey string,
+	headers map[string]any,
+) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(_tracerName).Start(ctx, exchange+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystem(system),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(exchange),
+			semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+		),
+	)
+
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+
+	return ctx, span
+}
+
+// StartConsumeSpan continues the trace of the publisher of a delivery, the worker ends the span once it is handled.
+func StartConsumeSpan(ctx context.Context, delivery Delivery) (context.Context, trace.Span) {
+	if delivery.Headers != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier(delivery.Headers))
+	}
+
+	return otel.Tracer(_tracerName).Start(ctx, delivery.Exchange+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystem(delivery.System),
+			semconv.MessagingOperationProcess,
+			semconv.MessagingDestinationName(delivery.Exchange),
+			semconv.MessagingRabbitmqDestinationRoutingKey(delivery.RoutingKey),
+			semconv.MessagingMessageID(delivery.MessageID),
+		),
+	)
+}
@@ -0,0 +1,46 @@
+package messaging_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/tracing"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTraceCrossesTheBroker(t *testing.T) {
+	t.Parallel()
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	shutdown, err := tracing.Init(context.Background(), configs.App{Name: "test"}, configs.Tracing{SampleRatio: 1},
+		tracing.WithSpanExporter(exporter))
+	assert.NoError(t, err)
+
+	headers := map[string]any{}
+
+	_, publish := messaging.StartPublishSpan(context.Background(), "rabbitmq", "barista-order-exchange", "barista-order-routing-key", headers)
+	publish.End()
+
+	assert.Contains(t, headers, "traceparent")
+
+	_, process := messaging.StartConsumeSpan(context.Background(), messaging.Delivery{
+		Headers:  headers,
+		Exchange: "barista-order-exchange",
+		System:   "rabbitmq",
+	})
+	process.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "barista-order-exchange publish", spans[0].Name)
+	assert.Equal(t, spans[0].SpanContext.TraceID(), spans[1].SpanContext.TraceID())
+	assert.Equal(t, spans[0].SpanContext.SpanID(), spans[1].Parent.SpanID())
+
+	assert.NoError(t, shutdown(context.Background()))
+}
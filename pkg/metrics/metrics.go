@@ -0,0 +1,15 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the metrics of the services.
+const Namespace = "coffeeshop"
+
+// Handler exposes the metrics of the default registry.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import "github.com/pkg/errors"
+
+const _uniqueViolation = "23505"
+
+// sqlStater is an error of PostgreSQL with its SQLSTATE code, *pq.Error of lib/pq and *pgconn.PgError of pgx are.
+type sqlStater interface {
+	SQLState() string
+}
+
+// IsUniqueViolation tells whether err, or an error it wraps, is a unique constraint violation, with either driver.
+func IsUniqueViolation(err error) bool {
+	var stater sqlStater
+
+	return errors.As(err, &stater) && stater.SQLState() == _uniqueViolation
+}
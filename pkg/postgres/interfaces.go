@@ -5,5 +5,7 @@ import "database/sql"
 type DBEngine interface {
 	GetDB() *sql.DB
 	Configure(...Option) DBEngine
+	// Stats reports the connections of the pool, they are exported as metrics too.
+	Stats() sql.DBStats
 	Close()
 }
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thangchung/go-coffeeshop/pkg/metrics"
+)
+
+// poolCollector exports the stats of a pgx pool, they are read when the metrics are scraped.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns, idleConns, totalConns, maxConns  *prometheus.Desc
+	acquireCount, emptyAcquireCount, acquireSeconds *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool, name string) *poolCollector {
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(
+			prometheus.BuildFQName(metrics.Namespace, "pgxpool", metric), help, nil, prometheus.Labels{"db_name": name},
+		)
+	}
+
+	return &poolCollector{
+		pool:              pool,
+		acquiredConns:     desc("acquired_connections", "Connections in use."),
+		idleConns:         desc("idle_connections", "Idle connections."),
+		totalConns:        desc("total_connections", "Open connections, in use, idle or being opened."),
+		maxConns:          desc("max_connections", "Maximum size of the pool."),
+		acquireCount:      desc("acquires_total", "Connections acquired from the pool."),
+		emptyAcquireCount: desc("empty_acquires_total", "Acquires which waited since the pool had no idle connection."),
+		acquireSeconds:    desc("acquire_seconds_total", "Time spent acquiring connections."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquireCount, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireSeconds, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+}
@@ -1,17 +1,80 @@
 package postgres
 
-import "time"
+import (
+	"time"
+
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+)
 
 type Option func(*postgres)
 
+// ConnAttempts is how many times the database is pinged before giving up.
 func ConnAttempts(attempts int) Option {
 	return func(p *postgres) {
 		p.connAttempts = attempts
 	}
 }
 
+// ConnTimeout is the first backoff between the pings, it doubles after every failed one.
 func ConnTimeout(timeout time.Duration) Option {
 	return func(p *postgres) {
 		p.connTimeout = timeout
 	}
 }
+
+func MaxOpenConns(n int) Option {
+	return func(p *postgres) {
+		p.maxOpenConns = n
+	}
+}
+
+// MaxIdleConns is how many idle connections database/sql keeps, the pgx pool closes them after ConnMaxIdleTime.
+func MaxIdleConns(n int) Option {
+	return func(p *postgres) {
+		p.maxIdleConns = n
+	}
+}
+
+// MinConns is how many connections the pgx pool keeps open even when they are idle, it has no effect with lib/pq.
+func MinConns(n int) Option {
+	return func(p *postgres) {
+		p.minConns = n
+	}
+}
+
+func ConnMaxLifetime(d time.Duration) Option {
+	return func(p *postgres) {
+		p.connMaxLifetime = d
+	}
+}
+
+func ConnMaxIdleTime(d time.Duration) Option {
+	return func(p *postgres) {
+		p.connMaxIdleTime = d
+	}
+}
+
+// StatementCacheCapacity is how many prepared statements the pgx pool keeps per connection.
+func StatementCacheCapacity(n int) Option {
+	return func(p *postgres) {
+		p.statementCacheCapacity = n
+	}
+}
+
+// Name labels the metrics of the pool, it tells apart the pools of one process.
+func Name(name string) Option {
+	return func(p *postgres) {
+		p.name = name
+	}
+}
+
+// Pool applies the pool limits of the config.
+func Pool(cfg configs.PG) Option {
+	return func(p *postgres) {
+		p.maxOpenConns = cfg.PoolMax
+		p.maxIdleConns = cfg.PoolMaxIdle
+		p.minConns = cfg.PoolMinConns
+		p.connMaxLifetime = cfg.ConnMaxLifetime
+		p.connMaxIdleTime = cfg.ConnMaxIdleTime
+	}
+}
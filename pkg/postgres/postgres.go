@@ -1,54 +1,212 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
-	"log"
 	"time"
 
+	"github.com/XSAM/otelsql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 	"golang.org/x/exp/slog"
+
+	// the database/sql driver of DriverPostgres
+	_ "github.com/lib/pq"
 )
 
 const (
-	_defaultConnAttempts = 3
-	_defaultConnTimeout  = time.Second
+	DriverPostgres = "postgres"
+	DriverPgx      = "pgx"
+
+	_defaultConnAttempts           = 5
+	_defaultConnTimeout            = time.Second
+	_defaultMaxOpenConns           = 10
+	_defaultMaxIdleConns           = 2
+	_defaultConnMaxLifetime        = 30 * time.Minute
+	_defaultConnMaxIdleTime        = 5 * time.Minute
+	_defaultStatementCacheCapacity = 512
+	_defaultName                   = "postgres"
+
+	_maxBackoff  = 10 * time.Second
+	_pingTimeout = 5 * time.Second
 )
 
+var ErrUnknownDriver = errors.New("unknown driver")
+
 type DBConnString string
 
 type postgres struct {
-	connAttempts int
-	connTimeout  time.Duration
+	connAttempts           int
+	connTimeout            time.Duration
+	maxOpenConns           int
+	maxIdleConns           int
+	minConns               int
+	connMaxLifetime        time.Duration
+	connMaxIdleTime        time.Duration
+	statementCacheCapacity int
+	name                   string
 
 	db *sql.DB
+	// pool is the pgx pool behind db, nil with lib/pq
+	pool       *pgxpool.Pool
+	collectors []prometheus.Collector
 }
 
 var _ DBEngine = (*postgres)(nil)
 
-func NewPostgresDB(url DBConnString) (DBEngine, error) {
-	slog.Info("CONN", "connect string", url)
+// New connects with the driver, DriverPostgres, DriverPgx or DriverSQLite.
+func New(driver string, url DBConnString, opts ...Option) (DBEngine, error) {
+	switch driver {
+	case DriverPostgres, "":
+		return NewPostgresDB(url, opts...)
+	case DriverPgx:
+		return NewPgxDB(url, opts...)
+	case DriverSQLite:
+		return NewSQLiteDB(url, opts...)
+	default:
+		return nil, errors.Wrap(ErrUnknownDriver, driver)
+	}
+}
+
+// NewPostgresDB connects through lib/pq, it fails when the database can't be pinged after all the attempts.
+func NewPostgresDB(url DBConnString, opts ...Option) (DBEngine, error) {
+	pg := newPostgres(opts...)
+
+	db, err := otelsql.Open("postgres", string(url), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+	if err != nil {
+		return nil, errors.Wrap(err, "otelsql.Open")
+	}
+
+	pg.db = db
+	pg.applyPool()
+
+	if err := pg.connect(db.PingContext); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	pg.register(collectors.NewDBStatsCollector(db, pg.name))
+
+	slog.Info("📰 connected to postgresdb 🎉", "driver", DriverPostgres, "max_open_conns", pg.maxOpenConns)
+
+	return pg, nil
+}
+
+// NewPgxDB connects through a pgx pool, which caches the prepared statements of every connection.
+// The queries still go through database/sql, so the same code runs on both drivers.
+func NewPgxDB(url DBConnString, opts ...Option) (DBEngine, error) {
+	pg := newPostgres(opts...)
+
+	cfg, err := pgxpool.ParseConfig(string(url))
+	if err != nil {
+		return nil, errors.Wrap(err, "pgxpool.ParseConfig")
+	}
+
+	cfg.MaxConns = int32(pg.maxOpenConns)
+	cfg.MinConns = int32(pg.minConns)
+	cfg.MaxConnLifetime = pg.connMaxLifetime
+	cfg.MaxConnIdleTime = pg.connMaxIdleTime
+	cfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	cfg.ConnConfig.StatementCacheCapacity = pg.statementCacheCapacity
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "pgxpool.NewWithConfig")
+	}
+
+	if err := pg.connect(pool.Ping); err != nil {
+		pool.Close()
 
+		return nil, err
+	}
+
+	// the pool keeps the idle connections, database/sql would hold on to them otherwise
+	pg.pool = pool
+	pg.db = otelsql.OpenDB(stdlib.GetPoolConnector(pool), otelsql.WithAttributes(semconv.DBSystemPostgreSQL))
+	pg.db.SetMaxIdleConns(0)
+
+	pg.register(collectors.NewDBStatsCollector(pg.db, pg.name), newPoolCollector(pool, pg.name))
+
+	slog.Info("📰 connected to postgresdb 🎉", "driver", DriverPgx, "max_open_conns", pg.maxOpenConns)
+
+	return pg, nil
+}
+
+func newPostgres(opts ...Option) *postgres {
 	pg := &postgres{
-		connAttempts: _defaultConnAttempts,
-		connTimeout:  _defaultConnTimeout,
+		connAttempts:           _defaultConnAttempts,
+		connTimeout:            _defaultConnTimeout,
+		maxOpenConns:           _defaultMaxOpenConns,
+		maxIdleConns:           _defaultMaxIdleConns,
+		connMaxLifetime:        _defaultConnMaxLifetime,
+		connMaxIdleTime:        _defaultConnMaxIdleTime,
+		statementCacheCapacity: _defaultStatementCacheCapacity,
+		name:                   _defaultName,
+	}
+
+	for _, opt := range opts {
+		opt(pg)
 	}
 
-	var err error
-	for pg.connAttempts > 0 {
-		pg.db, err = sql.Open("postgres", string(url))
-		if err != nil {
-			break
+	return pg
+}
+
+// connect pings until the database answers, backing off exponentially between the attempts.
+func (p *postgres) connect(ping func(context.Context) error) error {
+	backoff := p.connTimeout
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), _pingTimeout)
+		err := ping(ctx)
+		cancel()
+
+		if err == nil {
+			return nil
 		}
 
-		log.Printf("Postgres is trying to connect, attempts left: %d", pg.connAttempts)
+		if attempt >= p.connAttempts {
+			return errors.Wrapf(err, "ping postgres after %d attempts", attempt)
+		}
+
+		slog.Warn("postgres is not reachable, backing off", "error", err, "attempt", attempt, "backoff", backoff)
 
-		time.Sleep(pg.connTimeout)
+		time.Sleep(backoff)
 
-		pg.connAttempts--
+		if backoff *= 2; backoff > _maxBackoff {
+			backoff = _maxBackoff
+		}
 	}
+}
 
-	slog.Info("📰 connected to postgresdb 🎉")
+// applyPool sets the limits of the database/sql pool, the pgx pool gets them when it is created.
+func (p *postgres) applyPool() {
+	if p.pool != nil {
+		return
+	}
 
-	return pg, nil
+	p.db.SetMaxOpenConns(p.maxOpenConns)
+	p.db.SetMaxIdleConns(p.maxIdleConns)
+	p.db.SetConnMaxLifetime(p.connMaxLifetime)
+	p.db.SetConnMaxIdleTime(p.connMaxIdleTime)
+}
+
+// register exports the stats of the pool, a second pool with the same name keeps the metrics of the first.
+func (p *postgres) register(cs ...prometheus.Collector) {
+	for _, c := range cs {
+		if err := prometheus.Register(c); err != nil {
+			slog.Warn("failed to register the pool metrics", "error", err, "name", p.name)
+
+			continue
+		}
+
+		p.collectors = append(p.collectors, c)
+	}
 }
 
 func (p *postgres) Configure(opts ...Option) DBEngine {
@@ -56,6 +214,10 @@ func (p *postgres) Configure(opts ...Option) DBEngine {
 		opt(p)
 	}
 
+	if p.db != nil {
+		p.applyPool()
+	}
+
 	return p
 }
 
@@ -63,8 +225,20 @@ func (p *postgres) GetDB() *sql.DB {
 	return p.db
 }
 
+func (p *postgres) Stats() sql.DBStats {
+	return p.db.Stats()
+}
+
 func (p *postgres) Close() {
+	for _, c := range p.collectors {
+		prometheus.Unregister(c)
+	}
+
 	if p.db != nil {
 		p.db.Close()
 	}
+
+	if p.pool != nil {
+		p.pool.Close()
+	}
 }
@@ -0,0 +1,48 @@
+package postgres_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+// nothing listens on port 1, so every ping is refused
+const _unreachable = postgres.DBConnString("host=127.0.0.1 port=1 user=postgres dbname=postgres sslmode=disable")
+
+func TestNewFailsWhenTheDatabaseIsUnreachable(t *testing.T) {
+	t.Parallel()
+
+	for _, driver := range []string{postgres.DriverPostgres, postgres.DriverPgx} {
+		start := time.Now()
+
+		_, err := postgres.New(driver, _unreachable,
+			postgres.ConnAttempts(3), postgres.ConnTimeout(10*time.Millisecond))
+
+		assert.ErrorContains(t, err, "ping postgres after 3 attempts", driver)
+		// it backs off 10ms then 20ms between the attempts
+		assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond, driver)
+	}
+}
+
+func TestNewRejectsAnUnknownDriver(t *testing.T) {
+	t.Parallel()
+
+	_, err := postgres.New("mysql", _unreachable)
+	assert.ErrorIs(t, err, postgres.ErrUnknownDriver)
+}
+
+func TestIsUniqueViolationWithEitherDriver(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, postgres.IsUniqueViolation(&pq.Error{Code: "23505"}))
+	assert.True(t, postgres.IsUniqueViolation(fmt.Errorf("append: %w", &pgconn.PgError{Code: "23505"})))
+	assert.False(t, postgres.IsUniqueViolation(&pgconn.PgError{Code: "23503"}))
+	assert.False(t, postgres.IsUniqueViolation(errors.New("23505")))
+	assert.False(t, postgres.IsUniqueViolation(nil))
+}
@@ -0,0 +1,35 @@
+// Package postgrestest provides a postgres.DBEngine over go-sqlmock for the tests of the repositories and the apps.
+package postgrestest
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+type mockEngine struct {
+	db *sql.DB
+}
+
+var _ postgres.DBEngine = (*mockEngine)(nil)
+
+func (m *mockEngine) GetDB() *sql.DB                                 { return m.db }
+func (m *mockEngine) Configure(...postgres.Option) postgres.DBEngine { return m }
+func (m *mockEngine) Stats() sql.DBStats                             { return m.db.Stats() }
+func (m *mockEngine) Close()                                         { m.db.Close() }
+
+// NewMockEngine is an engine whose queries are expected on the returned mock, it is closed when the test ends.
+func NewMockEngine(t testing.TB) (postgres.DBEngine, sqlmock.Sqlmock) {
+	t.Helper()
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return &mockEngine{db: db}, mock
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/XSAM/otelsql"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/samber/lo"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"golang.org/x/exp/slog"
+
+	// the database/sql driver of DriverSQLite
+	_ "modernc.org/sqlite"
+)
+
+const DriverSQLite = "sqlite"
+
+// _sqlitePragmas are added to the DSN unless it sets them,
+// the timestamps are written in a format SQLite compares and parses back.
+var _sqlitePragmas = []string{
+	"_pragma=busy_timeout(5000)",
+	"_pragma=foreign_keys(1)",
+	"_pragma=journal_mode(WAL)",
+	"_time_format=sqlite",
+}
+
+// NewSQLiteDB opens a SQLite file, the url is a path or a file: URI.
+// SQLite has a single writer, so the pool keeps one connection and the repositories must run on Conn.
+func NewSQLiteDB(url DBConnString, opts ...Option) (DBEngine, error) {
+	pg := newPostgres(opts...)
+	pg.maxOpenConns = 1
+	pg.maxIdleConns = 1
+	pg.connMaxLifetime = 0
+	pg.connMaxIdleTime = 0
+
+	db, err := otelsql.Open("sqlite", sqliteDSN(string(url)), otelsql.WithAttributes(semconv.DBSystemSqlite))
+	if err != nil {
+		return nil, errors.Wrap(err, "otelsql.Open")
+	}
+
+	pg.db = db
+	pg.applyPool()
+
+	if err := pg.connect(db.PingContext); err != nil {
+		db.Close()
+
+		return nil, err
+	}
+
+	pg.register(collectors.NewDBStatsCollector(db, pg.name))
+
+	slog.Info("📰 connected to sqlite 🎉", "driver", DriverSQLite)
+
+	return pg, nil
+}
+
+func sqliteDSN(dsn string) string {
+	query := ""
+	if i := strings.IndexByte(dsn, '?'); i >= 0 {
+		query = dsn[i+1:]
+	}
+
+	params, _ := url.ParseQuery(query)
+
+	for _, pragma := range _sqlitePragmas {
+		key, value, _ := strings.Cut(pragma, "=")
+		if key == "_pragma" {
+			name, _, _ := strings.Cut(value, "(")
+			if lo.ContainsBy(params[key], func(p string) bool { return strings.HasPrefix(p, name+"(") }) {
+				continue
+			}
+		} else if params.Has(key) {
+			continue
+		}
+
+		if strings.Contains(dsn, "?") {
+			dsn += "&" + pragma
+		} else {
+			dsn += "?" + pragma
+		}
+	}
+
+	return dsn
+}
@@ -0,0 +1,45 @@
+package postgres_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+)
+
+func TestSQLiteRunsTheUnitOfWorkOnOneConnection(t *testing.T) {
+	t.Parallel()
+
+	pg, err := postgres.New(postgres.DriverSQLite,
+		postgres.DBConnString(filepath.Join(t.TempDir(), "coffeeshop.db")), postgres.Name("sqlite-test"))
+	require.NoError(t, err)
+	t.Cleanup(pg.Close)
+
+	ctx := context.Background()
+	_, err = pg.GetDB().ExecContext(ctx, "CREATE TABLE orders (id TEXT PRIMARY KEY, created TIMESTAMP NOT NULL)")
+	require.NoError(t, err)
+
+	created := time.Date(2023, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	// a query outside the transaction would wait for the only connection forever
+	err = postgres.NewUnitOfWork(pg).WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := postgres.Conn(ctx, pg).ExecContext(ctx,
+			"INSERT INTO orders (id, created) VALUES (?, ?)", "order-1", created); err != nil {
+			return err
+		}
+
+		var count int
+
+		return postgres.Conn(ctx, pg).QueryRowContext(ctx, "SELECT count(*) FROM orders").Scan(&count)
+	})
+	require.NoError(t, err)
+
+	var got time.Time
+	require.NoError(t, pg.GetDB().QueryRowContext(ctx, "SELECT created FROM orders WHERE id = ?", "order-1").Scan(&got))
+	assert.True(t, created.Equal(got), got)
+	assert.Equal(t, 1, pg.Stats().MaxOpenConnections)
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/wire"
+	"github.com/pkg/errors"
+	"golang.org/x/exp/slog"
+)
+
+// DBTX is what the sqlc queriers run on, both *sql.DB and *sql.Tx satisfy it.
+type DBTX interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+}
+
+// Hook runs once the transaction it was registered in has been committed.
+type Hook func(context.Context) error
+
+// UnitOfWork runs a function inside a database transaction carried by the context.
+type UnitOfWork interface {
+	// WithinTx commits when fn returns nil and rolls back when it returns an error or panics.
+	// A ctx that already carries a transaction joins it, the outermost call commits.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+var UnitOfWorkSet = wire.NewSet(NewUnitOfWork)
+
+type txKey struct{}
+
+type txState struct {
+	tx    *sql.Tx
+	hooks []Hook
+}
+
+type unitOfWork struct {
+	pg DBEngine
+}
+
+var _ UnitOfWork = (*unitOfWork)(nil)
+
+func NewUnitOfWork(pg DBEngine) UnitOfWork {
+	return &unitOfWork{pg: pg}
+}
+
+func (u *unitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	tx, err := u.pg.GetDB().BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "db.BeginTx")
+	}
+
+	state := &txState{tx: tx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback(tx)
+			panic(p)
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		rollback(tx)
+
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "tx.Commit")
+	}
+
+	// the hooks get the caller's ctx, so they don't run on the finished transaction
+	for _, hook := range state.hooks {
+		if err = hook(ctx); err != nil {
+			return errors.Wrap(err, "after commit")
+		}
+	}
+
+	return nil
+}
+
+// Conn returns the transaction carried by ctx, or the pool of pg outside of WithinTx.
+func Conn(ctx context.Context, pg DBEngine) DBTX {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.tx
+	}
+
+	return pg.GetDB()
+}
+
+// AfterCommit defers hook until the transaction carried by ctx has been committed,
+// it is dropped on rollback. Outside of WithinTx the hook runs right away.
+func AfterCommit(ctx context.Context, hook Hook) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.hooks = append(state.hooks, hook)
+
+		return nil
+	}
+
+	return hook(ctx)
+}
+
+func rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		slog.Error("failed to roll back", "error", err)
+	}
+}
@@ -0,0 +1,83 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres/postgrestest"
+)
+
+func TestWithinTxCommitsThenRunsTheHooks(t *testing.T) {
+	t.Parallel()
+
+	pg, mock := postgrestest.NewMockEngine(t)
+	uow := postgres.NewUnitOfWork(pg)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO orders").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	var hooked bool
+
+	err := uow.WithinTx(context.Background(), func(ctx context.Context) error {
+		_, err := postgres.Conn(ctx, pg).ExecContext(ctx, "INSERT INTO orders")
+		if err != nil {
+			return err
+		}
+
+		// joins the outer transaction instead of beginning another one
+		return uow.WithinTx(ctx, func(ctx context.Context) error {
+			return postgres.AfterCommit(ctx, func(context.Context) error {
+				hooked = true
+
+				return mock.ExpectationsWereMet()
+			})
+		})
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, hooked)
+}
+
+func TestWithinTxRollsBackOnError(t *testing.T) {
+	t.Parallel()
+
+	pg, mock := postgrestest.NewMockEngine(t)
+	errBoom := errors.New("boom")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err := postgres.NewUnitOfWork(pg).WithinTx(context.Background(), func(ctx context.Context) error {
+		_ = postgres.AfterCommit(ctx, func(context.Context) error {
+			t.Error("the hook must not run after a rollback")
+
+			return nil
+		})
+
+		return errBoom
+	})
+
+	assert.ErrorIs(t, err, errBoom)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithinTxRollsBackOnPanic(t *testing.T) {
+	t.Parallel()
+
+	pg, mock := postgrestest.NewMockEngine(t)
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	assert.PanicsWithValue(t, "boom", func() {
+		_ = postgres.NewUnitOfWork(pg).WithinTx(context.Background(), func(context.Context) error {
+			panic("boom")
+		})
+	})
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
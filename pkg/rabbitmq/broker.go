@@ -0,0 +1,46 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/google/wire"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/consumer"
+	"github.com/thangchung/go-coffeeshop/pkg/rabbitmq/publisher"
+)
+
+var BrokerSet = wire.NewSet(NewBroker)
+
+type broker struct {
+	conn *amqp.Connection
+}
+
+var _ messaging.Broker = (*broker)(nil)
+
+func NewBroker(conn *amqp.Connection) messaging.Broker {
+	return &broker{conn: conn}
+}
+
+func (b *broker) NewPublisher(opts ...messaging.Option) (messaging.Publisher, error) {
+	pub, err := publisher.NewPublisher(b.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return pub.Configure(opts...), nil
+}
+
+func (b *broker) NewSubscriber(opts ...messaging.Option) (messaging.Subscriber, error) {
+	sub, err := consumer.NewConsumer(b.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return sub.Configure(opts...), nil
+}
+
+func (b *broker) Check(ctx context.Context) error {
+	return health.AMQP(b.conn)(ctx)
+}
@@ -2,26 +2,25 @@ package consumer
 
 import (
 	"context"
+	"sync"
 
-	"github.com/google/wire"
 	"github.com/pkg/errors"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
 	"golang.org/x/exp/slog"
 )
 
 const (
+	_system = "rabbitmq"
+
 	_exchangeKind       = "direct"
 	_exchangeDurable    = true
 	_exchangeAutoDelete = false
 	_exchangeInternal   = false
 	_exchangeNoWait     = false
 
-	_queueDurable    = true
-	_queueAutoDelete = false
-	_queueExclusive  = false
-	_queueNoWait     = false
+	_queueNoWait = false
 
-	_prefetchCount  = 5
 	_prefetchSize   = 0
 	_prefetchGlobal = false
 
@@ -38,50 +37,53 @@ const (
 )
 
 type consumer struct {
-	exchangeName, queueName, bindingKey, consumerTag string
-	workerPoolSize                                   int
-	amqpConn                                         *amqp.Connection
-}
+	cfg      messaging.Config
+	amqpConn *amqp.Connection
 
-var _ EventConsumer = (*consumer)(nil)
+	mu sync.Mutex
+	// consuming is the channel the deliveries come from, nil until the consumer starts
+	consuming *amqp.Channel
+}
 
-var EventConsumerSet = wire.NewSet(NewConsumer)
+var _ messaging.Subscriber = (*consumer)(nil)
 
-func NewConsumer(amqpConn *amqp.Connection) (EventConsumer, error) {
+func NewConsumer(amqpConn *amqp.Connection) (messaging.Subscriber, error) {
 	sub := &consumer{
-		amqpConn:       amqpConn,
-		exchangeName:   _exchangeName,
-		queueName:      _queueName,
-		bindingKey:     _bindingKey,
-		consumerTag:    _consumerTag,
-		workerPoolSize: _workerPoolSize,
+		amqpConn: amqpConn,
+		cfg: messaging.Config{
+			ExchangeName:   _exchangeName,
+			QueueName:      _queueName,
+			BindingKey:     _bindingKey,
+			ConsumerTag:    _consumerTag,
+			WorkerPoolSize: _workerPoolSize,
+		},
 	}
 
 	return sub, nil
 }
 
-func (c *consumer) Configure(opts ...Option) EventConsumer {
+func (c *consumer) Configure(opts ...messaging.Option) messaging.Subscriber {
 	for _, opt := range opts {
-		opt(c)
+		opt(&c.cfg)
 	}
 
 	return c
 }
 
-// StartConsumer Start new rabbitmq consumer.
-func (c *consumer) StartConsumer(fn worker) error {
+// Subscribe Start new rabbitmq consumer.
+func (c *consumer) Subscribe(fn messaging.Worker) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	ch, err := c.createChannel()
+	ch, queueName, err := c.createChannel()
 	if err != nil {
 		return errors.Wrap(err, "CreateChannel")
 	}
 	defer ch.Close()
 
 	deliveries, err := ch.Consume(
-		c.queueName,
-		c.consumerTag,
+		queueName,
+		c.cfg.ConsumerTag,
 		_consumeAutoAck,
 		_consumeExclusive,
 		_consumeNoLocal,
@@ -92,29 +94,57 @@ func (c *consumer) StartConsumer(fn worker) error {
 		return errors.Wrap(err, "Consume")
 	}
 
+	c.mu.Lock()
+	c.consuming = ch
+	c.mu.Unlock()
+
 	forever := make(chan bool)
 
-	for i := 0; i < c.workerPoolSize; i++ {
-		go fn(ctx, deliveries)
+	workers.WithLabelValues(queueName).Set(float64(c.cfg.WorkerPoolSize))
+
+	metered := make(chan messaging.Delivery)
+
+	go func() {
+		defer close(metered)
+
+		for delivery := range deliveries {
+			metered <- meter(queueName, toDelivery(delivery))
+		}
+	}()
+
+	for i := 0; i < c.cfg.WorkerPoolSize; i++ {
+		go fn(ctx, metered)
 	}
 
 	chanErr := <-ch.NotifyClose(make(chan *amqp.Error))
-	slog.Error("ch.NotifyClose", chanErr)
+	slog.Error("ch.NotifyClose", "error", chanErr)
 	<-forever
 
 	return chanErr
 }
 
-// CreateChannel Consume messages.
-func (c *consumer) createChannel() (*amqp.Channel, error) {
+// Check tells whether the consumer receives deliveries, i.e. it started and its channel is still open.
+func (c *consumer) Check(context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.consuming == nil || c.consuming.IsClosed() {
+		return errors.Wrapf(messaging.ErrNotConsuming, "queue %s", c.cfg.QueueName)
+	}
+
+	return nil
+}
+
+// CreateChannel Consume messages, the name of the queue is generated by the broker for a transient queue.
+func (c *consumer) createChannel() (*amqp.Channel, string, error) {
 	ch, err := c.amqpConn.Channel()
 	if err != nil {
-		return nil, errors.Wrap(err, "Error amqpConn.Channel")
+		return nil, "", errors.Wrap(err, "Error amqpConn.Channel")
 	}
 
-	slog.Info("declaring exchange", "exchange_name", c.exchangeName)
+	slog.Info("declaring exchange", "exchange_name", c.cfg.ExchangeName)
 	err = ch.ExchangeDeclare(
-		c.exchangeName,
+		c.cfg.ExchangeName,
 		_exchangeKind,
 		_exchangeDurable,
 		_exchangeAutoDelete,
@@ -124,46 +154,67 @@ func (c *consumer) createChannel() (*amqp.Channel, error) {
 	)
 
 	if err != nil {
-		return nil, errors.Wrap(err, "Error ch.ExchangeDeclare")
+		return nil, "", errors.Wrap(err, "Error ch.ExchangeDeclare")
 	}
 
+	// a transient queue is neither durable nor shared and it is deleted with the channel
 	queue, err := ch.QueueDeclare(
-		c.queueName,
-		_queueDurable,
-		_queueAutoDelete,
-		_queueExclusive,
+		c.cfg.QueueName,
+		!c.cfg.Transient,
+		c.cfg.Transient,
+		c.cfg.Transient,
 		_queueNoWait,
 		nil,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error ch.QueueDeclare")
+		return nil, "", errors.Wrap(err, "Error ch.QueueDeclare")
 	}
 
 	slog.Info("declared queue, binding it to exchange", "queue", queue.Name, "messages_count", queue.Messages,
-		"consumer_count", queue.Consumers, "exchange", c.exchangeName, "binding_key", c.bindingKey,
+		"consumer_count", queue.Consumers, "exchange", c.cfg.ExchangeName, "binding_key", c.cfg.BindingKey,
 	)
 
 	err = ch.QueueBind(
 		queue.Name,
-		c.bindingKey,
-		c.exchangeName,
+		c.cfg.BindingKey,
+		c.cfg.ExchangeName,
 		_queueNoWait,
 		nil,
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error ch.QueueBind")
+		return nil, "", errors.Wrap(err, "Error ch.QueueBind")
 	}
 
-	slog.Info("queue bound to exchange, starting to consume from queue", "consumer_tag", c.consumerTag)
+	slog.Info("queue bound to exchange, starting to consume from queue", "consumer_tag", c.cfg.ConsumerTag)
 
+	// the broker hands out as many unacknowledged deliveries as there are workers, so none of them waits for work
+	// while deliveries are ready and the in-flight deliveries can reach the size of the pool
 	err = ch.Qos(
-		_prefetchCount,  // prefetch count
-		_prefetchSize,   // prefetch size
-		_prefetchGlobal, // global
+		c.cfg.WorkerPoolSize, // prefetch count
+		_prefetchSize,        // prefetch size
+		_prefetchGlobal,      // global
 	)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error ch.Qos")
+		return nil, "", errors.Wrap(err, "Error ch.Qos")
 	}
 
-	return ch, nil
+	return ch, queue.Name, nil
+}
+
+// toDelivery hands an AMQP delivery to the workers, the channel it came from settles it.
+func toDelivery(d amqp.Delivery) messaging.Delivery {
+	return messaging.Delivery{
+		Acknowledger: d.Acknowledger,
+		Headers:      d.Headers,
+		ContentType:  d.ContentType,
+		MessageID:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Type:         d.Type,
+		Body:         d.Body,
+		DeliveryTag:  d.DeliveryTag,
+		Redelivered:  d.Redelivered,
+		Exchange:     d.Exchange,
+		RoutingKey:   d.RoutingKey,
+		System:       _system,
+	}
 }
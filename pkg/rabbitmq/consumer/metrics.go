@@ -0,0 +1,116 @@
+package consumer
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/metrics"
+)
+
+const (
+	_outcomeAck    = "ack"
+	_outcomeNack   = "nack"
+	_outcomeReject = "reject"
+)
+
+var (
+	deliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "deliveries_total",
+		Help:      "Deliveries received from the queue, by message type.",
+	}, []string{"queue", "type"})
+
+	acksTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "acks_total",
+		Help:      "Deliveries the workers acknowledged.",
+	}, []string{"queue"})
+
+	rejectsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "rejects_total",
+		Help:      "Deliveries the workers rejected or nacked, by whether they went back to the queue.",
+	}, []string{"queue", "requeue"})
+
+	handlingSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "handling_seconds",
+		Help:      "Time from handing a delivery to the workers until they settle it.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"queue", "outcome"})
+
+	inFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "in_flight_deliveries",
+		Help:      "Deliveries handed to the workers which they haven't settled yet.",
+	}, []string{"queue"})
+
+	workers = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "consumer",
+		Name:      "workers",
+		Help:      "Size of the worker pool, the pool is saturated when the in-flight deliveries reach it.",
+	}, []string{"queue"})
+)
+
+// meteredAcknowledger counts how a delivery is settled and how long it took,
+// only the first settlement counts since the broker ignores the others.
+type meteredAcknowledger struct {
+	messaging.Acknowledger
+	queue string
+	start time.Time
+	once  sync.Once
+}
+
+func meter(queue string, delivery messaging.Delivery) messaging.Delivery {
+	deliveriesTotal.WithLabelValues(queue, delivery.Type).Inc()
+	inFlight.WithLabelValues(queue).Inc()
+
+	delivery.Acknowledger = &meteredAcknowledger{
+		Acknowledger: delivery.Acknowledger,
+		queue:        queue,
+		start:        time.Now(),
+	}
+
+	return delivery
+}
+
+func (a *meteredAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.settle(_outcomeAck, false)
+
+	return a.Acknowledger.Ack(tag, multiple)
+}
+
+func (a *meteredAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.settle(_outcomeNack, requeue)
+
+	return a.Acknowledger.Nack(tag, multiple, requeue)
+}
+
+func (a *meteredAcknowledger) Reject(tag uint64, requeue bool) error {
+	a.settle(_outcomeReject, requeue)
+
+	return a.Acknowledger.Reject(tag, requeue)
+}
+
+func (a *meteredAcknowledger) settle(outcome string, requeue bool) {
+	a.once.Do(func() {
+		inFlight.WithLabelValues(a.queue).Dec()
+		handlingSeconds.WithLabelValues(a.queue, outcome).Observe(time.Since(a.start).Seconds())
+
+		if outcome == _outcomeAck {
+			acksTotal.WithLabelValues(a.queue).Inc()
+		} else {
+			rejectsTotal.WithLabelValues(a.queue, strconv.FormatBool(requeue)).Inc()
+		}
+	})
+}
@@ -1,10 +0,0 @@
-package publisher
-
-import (
-	"context"
-)
-
-type EventPublisher interface {
-	Configure(...Option) EventPublisher
-	Publish(context.Context, []byte, string) error
-}
@@ -0,0 +1,24 @@
+package publisher
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/thangchung/go-coffeeshop/pkg/metrics"
+)
+
+var (
+	publishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "publisher",
+		Name:      "messages_total",
+		Help:      "Messages published, by exchange, message type and whether the broker took them.",
+	}, []string{"exchange", "type", "outcome"})
+
+	publishSeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "publisher",
+		Name:      "publish_seconds",
+		Help:      "Time to open a channel and publish a message.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"exchange"})
+)
@@ -6,9 +6,10 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/google/wire"
 	"github.com/pkg/errors"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"go.opentelemetry.io/otel/codes"
 	"golang.org/x/exp/slog"
 )
 
@@ -16,23 +17,22 @@ const (
 	_publishMandatory = false
 	_publishImmediate = false
 
+	_system = "rabbitmq"
+
 	_exchangeName    = "orders-exchange"
 	_bindingKey      = "orders-routing-key"
 	_messageTypeName = "ordered"
 )
 
 type publisher struct {
-	exchangeName, bindingKey string
-	messageTypeName          string
-	amqpChan                 *amqp.Channel
-	amqpConn                 *amqp.Connection
+	cfg      messaging.Config
+	amqpChan *amqp.Channel
+	amqpConn *amqp.Connection
 }
 
-var _ EventPublisher = (*publisher)(nil)
-
-var EventPublisherSet = wire.NewSet(NewPublisher)
+var _ messaging.Publisher = (*publisher)(nil)
 
-func NewPublisher(amqpConn *amqp.Connection) (EventPublisher, error) {
+func NewPublisher(amqpConn *amqp.Connection) (messaging.Publisher, error) {
 	ch, err := amqpConn.Channel()
 	if err != nil {
 		panic(err)
@@ -40,19 +40,21 @@ func NewPublisher(amqpConn *amqp.Connection) (EventPublisher, error) {
 	defer ch.Close()
 
 	pub := &publisher{
-		amqpConn:        amqpConn,
-		amqpChan:        ch,
-		exchangeName:    _exchangeName,
-		bindingKey:      _bindingKey,
-		messageTypeName: _messageTypeName,
+		amqpConn: amqpConn,
+		amqpChan: ch,
+		cfg: messaging.Config{
+			ExchangeName:    _exchangeName,
+			BindingKey:      _bindingKey,
+			MessageTypeName: _messageTypeName,
+		},
 	}
 
 	return pub, nil
 }
 
-func (p *publisher) Configure(opts ...Option) EventPublisher {
+func (p *publisher) Configure(opts ...messaging.Option) messaging.Publisher {
 	for _, opt := range opts {
-		opt(p)
+		opt(&p.cfg)
 	}
 
 	return p
@@ -75,30 +77,51 @@ func (p *publisher) PublishEvents(ctx context.Context, events []any) error {
 }
 
 // Publish message.
-func (p *publisher) Publish(ctx context.Context, body []byte, contentType string) error {
+func (p *publisher) Publish(ctx context.Context, body []byte, contentType string) (err error) {
+	start := time.Now()
+
+	defer func() {
+		outcome := "ok"
+		if err != nil {
+			outcome = "error"
+		}
+
+		publishedTotal.WithLabelValues(p.cfg.ExchangeName, p.cfg.MessageTypeName, outcome).Inc()
+		publishSeconds.WithLabelValues(p.cfg.ExchangeName).Observe(time.Since(start).Seconds())
+	}()
+
 	ch, err := p.amqpConn.Channel()
 	if err != nil {
 		return errors.Wrap(err, "CreateChannel")
 	}
 	defer ch.Close()
 
-	slog.Info("publish message", "exchange", p.exchangeName, "routing_key", p.bindingKey)
+	slog.Info("publish message", "exchange", p.cfg.ExchangeName, "routing_key", p.cfg.BindingKey)
+
+	headers := map[string]any{}
+
+	ctx, span := messaging.StartPublishSpan(ctx, _system, p.cfg.ExchangeName, p.cfg.BindingKey, headers)
+	defer span.End()
 
 	if err := ch.PublishWithContext(
 		ctx,
-		p.exchangeName,
-		p.bindingKey,
+		p.cfg.ExchangeName,
+		p.cfg.BindingKey,
 		_publishMandatory,
 		_publishImmediate,
 		amqp.Publishing{
+			Headers:      amqp.Table(headers),
 			ContentType:  contentType,
 			DeliveryMode: amqp.Persistent,
 			MessageId:    uuid.New().String(),
 			Timestamp:    time.Now(),
 			Body:         body,
-			Type:         p.messageTypeName,
+			Type:         p.cfg.MessageTypeName,
 		},
 	); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
 		return errors.Wrap(err, "ch.Publish")
 	}
 
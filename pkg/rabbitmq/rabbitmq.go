@@ -2,6 +2,7 @@ package rabbitmq
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -26,7 +27,7 @@ func NewRabbitMQConn(rabbitMqURL RabbitMQConnStr) (*amqp.Connection, error) {
 	for {
 		connection, err := amqp.Dial(string(rabbitMqURL))
 		if err != nil {
-			slog.Error("failed to connect to RabbitMq...", err, rabbitMqURL)
+			slog.Error("failed to connect to RabbitMq...", "error", err, "host", host(rabbitMqURL))
 			counts++
 		} else {
 			amqpConn = connection
@@ -35,7 +36,7 @@ func NewRabbitMQConn(rabbitMqURL RabbitMQConnStr) (*amqp.Connection, error) {
 		}
 
 		if counts > _retryTimes {
-			slog.Error("failed to retry", err)
+			slog.Error("failed to retry", "error", err)
 
 			return nil, ErrCannotConnectRabbitMQ
 		}
@@ -50,3 +51,13 @@ func NewRabbitMQConn(rabbitMqURL RabbitMQConnStr) (*amqp.Connection, error) {
 
 	return amqpConn, nil
 }
+
+// host is where the broker is without the credentials of the URL, for the log.
+func host(rabbitMqURL RabbitMQConnStr) string {
+	uri, err := amqp.ParseURI(string(rabbitMqURL))
+	if err != nil {
+		return "unparsable URL"
+	}
+
+	return fmt.Sprintf("%s:%d", uri.Host, uri.Port)
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+type Option func(*options)
+
+type options struct {
+	exporter sdktrace.SpanExporter
+	sync     bool
+}
+
+// WithSpanExporter replaces the configured exporter, e.g. with a tracetest.InMemoryExporter in tests.
+// The spans are exported as they end, so they can be read right away.
+func WithSpanExporter(exporter sdktrace.SpanExporter) Option {
+	return func(o *options) {
+		o.exporter = exporter
+		o.sync = true
+	}
+}
+
+// Init installs the global tracer provider of the service and the W3C trace context propagator,
+// the returned function flushes the spans which are not exported yet and stops the provider.
+func Init(ctx context.Context, app configs.App, cfg configs.Tracing, opts ...Option) (func(context.Context) error, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.exporter == nil {
+		exporter, err := newExporter(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		o.exporter = exporter
+	}
+
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(app.Name),
+			semconv.ServiceVersion(app.Version),
+		)),
+	}
+
+	switch {
+	case o.exporter == nil:
+	case o.sync:
+		providerOpts = append(providerOpts, sdktrace.WithSyncer(o.exporter))
+	default:
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(o.exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// newExporter returns no exporter for none, the spans are then only used to pass the trace on.
+func newExporter(ctx context.Context, cfg configs.Tracing) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.Exporter) {
+	case ExporterOTLP:
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+
+		exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, errors.Wrap(err, "otlptracegrpc.New")
+		}
+
+		return exporter, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, errors.Wrap(err, "stdouttrace.New")
+		}
+
+		return exporter, nil
+	case ExporterNone, "":
+		return nil, nil
+	default:
+		return nil, errors.Wrapf(ErrUnknownExporter, "%q", cfg.Exporter)
+	}
+}
@@ -24,6 +24,7 @@ const _ = grpc.SupportPackageIsVersion7
 type CounterServiceClient interface {
 	GetListOrderFulfillment(ctx context.Context, in *GetListOrderFulfillmentRequest, opts ...grpc.CallOption) (*GetListOrderFulfillmentResponse, error)
 	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error)
+	GetOrderTimeline(ctx context.Context, in *GetOrderTimelineRequest, opts ...grpc.CallOption) (*GetOrderTimelineResponse, error)
 }
 
 type counterServiceClient struct {
@@ -52,12 +53,22 @@ func (c *counterServiceClient) PlaceOrder(ctx context.Context, in *PlaceOrderReq
 	return out, nil
 }
 
+func (c *counterServiceClient) GetOrderTimeline(ctx context.Context, in *GetOrderTimelineRequest, opts ...grpc.CallOption) (*GetOrderTimelineResponse, error) {
+	out := new(GetOrderTimelineResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.counterapi.CounterService/GetOrderTimeline", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CounterServiceServer is the server API for CounterService service.
 // All implementations should embed UnimplementedCounterServiceServer
 // for forward compatibility
 type CounterServiceServer interface {
 	GetListOrderFulfillment(context.Context, *GetListOrderFulfillmentRequest) (*GetListOrderFulfillmentResponse, error)
 	PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error)
+	GetOrderTimeline(context.Context, *GetOrderTimelineRequest) (*GetOrderTimelineResponse, error)
 }
 
 // UnimplementedCounterServiceServer should be embedded to have forward compatible implementations.
@@ -70,6 +81,9 @@ func (UnimplementedCounterServiceServer) GetListOrderFulfillment(context.Context
 func (UnimplementedCounterServiceServer) PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method PlaceOrder not implemented")
 }
+func (UnimplementedCounterServiceServer) GetOrderTimeline(context.Context, *GetOrderTimelineRequest) (*GetOrderTimelineResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderTimeline not implemented")
+}
 
 // UnsafeCounterServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to CounterServiceServer will
@@ -118,6 +132,24 @@ func _CounterService_PlaceOrder_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CounterService_GetOrderTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CounterServiceServer).GetOrderTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.counterapi.CounterService/GetOrderTimeline",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CounterServiceServer).GetOrderTimeline(ctx, req.(*GetOrderTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CounterService_ServiceDesc is the grpc.ServiceDesc for CounterService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -133,6 +165,10 @@ var CounterService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PlaceOrder",
 			Handler:    _CounterService_PlaceOrder_Handler,
 		},
+		{
+			MethodName: "GetOrderTimeline",
+			Handler:    _CounterService_GetOrderTimeline_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "counter.proto",
@@ -8,6 +8,7 @@ package gen
 
 import (
 	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -23,7 +24,14 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type ProductServiceClient interface {
 	GetItemTypes(ctx context.Context, in *GetItemTypesRequest, opts ...grpc.CallOption) (*GetItemTypesResponse, error)
+	// GetItemsByType skips the item types it can't price, LookupItems reports them.
 	GetItemsByType(ctx context.Context, in *GetItemsByTypeRequest, opts ...grpc.CallOption) (*GetItemsByTypeResponse, error)
+	LookupItems(ctx context.Context, in *LookupItemsRequest, opts ...grpc.CallOption) (*LookupItemsResponse, error)
+	SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*SearchItemsResponse, error)
+	UploadItemImage(ctx context.Context, in *UploadItemImageRequest, opts ...grpc.CallOption) (*ItemTypeDto, error)
+	GetItemImage(ctx context.Context, in *GetItemImageRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	ImportCatalog(ctx context.Context, in *ImportCatalogRequest, opts ...grpc.CallOption) (*ImportCatalogResponse, error)
+	ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
 }
 
 type productServiceClient struct {
@@ -52,12 +60,73 @@ func (c *productServiceClient) GetItemsByType(ctx context.Context, in *GetItemsB
 	return out, nil
 }
 
+func (c *productServiceClient) LookupItems(ctx context.Context, in *LookupItemsRequest, opts ...grpc.CallOption) (*LookupItemsResponse, error) {
+	out := new(LookupItemsResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/LookupItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) SearchItems(ctx context.Context, in *SearchItemsRequest, opts ...grpc.CallOption) (*SearchItemsResponse, error) {
+	out := new(SearchItemsResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/SearchItems", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) UploadItemImage(ctx context.Context, in *UploadItemImageRequest, opts ...grpc.CallOption) (*ItemTypeDto, error) {
+	out := new(ItemTypeDto)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/UploadItemImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) GetItemImage(ctx context.Context, in *GetItemImageRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/GetItemImage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ImportCatalog(ctx context.Context, in *ImportCatalogRequest, opts ...grpc.CallOption) (*ImportCatalogResponse, error) {
+	out := new(ImportCatalogResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/ImportCatalog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productServiceClient) ExportCatalog(ctx context.Context, in *ExportCatalogRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.productapi.ProductService/ExportCatalog", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ProductServiceServer is the server API for ProductService service.
 // All implementations should embed UnimplementedProductServiceServer
 // for forward compatibility
 type ProductServiceServer interface {
 	GetItemTypes(context.Context, *GetItemTypesRequest) (*GetItemTypesResponse, error)
+	// GetItemsByType skips the item types it can't price, LookupItems reports them.
 	GetItemsByType(context.Context, *GetItemsByTypeRequest) (*GetItemsByTypeResponse, error)
+	LookupItems(context.Context, *LookupItemsRequest) (*LookupItemsResponse, error)
+	SearchItems(context.Context, *SearchItemsRequest) (*SearchItemsResponse, error)
+	UploadItemImage(context.Context, *UploadItemImageRequest) (*ItemTypeDto, error)
+	GetItemImage(context.Context, *GetItemImageRequest) (*httpbody.HttpBody, error)
+	ImportCatalog(context.Context, *ImportCatalogRequest) (*ImportCatalogResponse, error)
+	ExportCatalog(context.Context, *ExportCatalogRequest) (*httpbody.HttpBody, error)
 }
 
 // UnimplementedProductServiceServer should be embedded to have forward compatible implementations.
@@ -70,6 +139,24 @@ func (UnimplementedProductServiceServer) GetItemTypes(context.Context, *GetItemT
 func (UnimplementedProductServiceServer) GetItemsByType(context.Context, *GetItemsByTypeRequest) (*GetItemsByTypeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetItemsByType not implemented")
 }
+func (UnimplementedProductServiceServer) LookupItems(context.Context, *LookupItemsRequest) (*LookupItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupItems not implemented")
+}
+func (UnimplementedProductServiceServer) SearchItems(context.Context, *SearchItemsRequest) (*SearchItemsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchItems not implemented")
+}
+func (UnimplementedProductServiceServer) UploadItemImage(context.Context, *UploadItemImageRequest) (*ItemTypeDto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UploadItemImage not implemented")
+}
+func (UnimplementedProductServiceServer) GetItemImage(context.Context, *GetItemImageRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemImage not implemented")
+}
+func (UnimplementedProductServiceServer) ImportCatalog(context.Context, *ImportCatalogRequest) (*ImportCatalogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ImportCatalog not implemented")
+}
+func (UnimplementedProductServiceServer) ExportCatalog(context.Context, *ExportCatalogRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportCatalog not implemented")
+}
 
 // UnsafeProductServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ProductServiceServer will
@@ -118,6 +205,114 @@ func _ProductService_GetItemsByType_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProductService_LookupItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).LookupItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/LookupItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).LookupItems(ctx, req.(*LookupItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_SearchItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).SearchItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/SearchItems",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).SearchItems(ctx, req.(*SearchItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_UploadItemImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadItemImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).UploadItemImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/UploadItemImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).UploadItemImage(ctx, req.(*UploadItemImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_GetItemImage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemImageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).GetItemImage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/GetItemImage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).GetItemImage(ctx, req.(*GetItemImageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ImportCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ImportCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/ImportCatalog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ImportCatalog(ctx, req.(*ImportCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductService_ExportCatalog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportCatalogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductServiceServer).ExportCatalog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.productapi.ProductService/ExportCatalog",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductServiceServer).ExportCatalog(ctx, req.(*ExportCatalogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ProductService_ServiceDesc is the grpc.ServiceDesc for ProductService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -133,6 +328,30 @@ var ProductService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetItemsByType",
 			Handler:    _ProductService_GetItemsByType_Handler,
 		},
+		{
+			MethodName: "LookupItems",
+			Handler:    _ProductService_LookupItems_Handler,
+		},
+		{
+			MethodName: "SearchItems",
+			Handler:    _ProductService_SearchItems_Handler,
+		},
+		{
+			MethodName: "UploadItemImage",
+			Handler:    _ProductService_UploadItemImage_Handler,
+		},
+		{
+			MethodName: "GetItemImage",
+			Handler:    _ProductService_GetItemImage_Handler,
+		},
+		{
+			MethodName: "ImportCatalog",
+			Handler:    _ProductService_ImportCatalog_Handler,
+		},
+		{
+			MethodName: "ExportCatalog",
+			Handler:    _ProductService_ExportCatalog_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "product.proto",
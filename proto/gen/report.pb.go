@@ -0,0 +1,773 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.0-devel
+// 	protoc        (unknown)
+// source: report.proto
+
+package gen
+
+import (
+	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2/options"
+	_ "google.golang.org/genproto/googleapis/api/annotations"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// from_date and to_date are YYYY-MM-DD and both included in the report.
+// The days are UTC days, the report has no time zone per location.
+type GetSalesReportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromDate string   `protobuf:"bytes,1,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate   string   `protobuf:"bytes,2,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	GroupBy  []string `protobuf:"bytes,3,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+}
+
+func (x *GetSalesReportRequest) Reset() {
+	*x = GetSalesReportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSalesReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSalesReportRequest) ProtoMessage() {}
+
+func (x *GetSalesReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSalesReportRequest.ProtoReflect.Descriptor instead.
+func (*GetSalesReportRequest) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetSalesReportRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *GetSalesReportRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *GetSalesReportRequest) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+type GetSalesReportResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rows []*SalesReportRowDto `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+}
+
+func (x *GetSalesReportResponse) Reset() {
+	*x = GetSalesReportResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetSalesReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSalesReportResponse) ProtoMessage() {}
+
+func (x *GetSalesReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSalesReportResponse.ProtoReflect.Descriptor instead.
+func (*GetSalesReportResponse) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetSalesReportResponse) GetRows() []*SalesReportRowDto {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type SalesReportRowDto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Day           string  `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Location      string  `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	ItemType      string  `protobuf:"bytes,3,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Orders        int64   `protobuf:"varint,4,opt,name=orders,proto3" json:"orders,omitempty"`
+	Items         int64   `protobuf:"varint,5,opt,name=items,proto3" json:"items,omitempty"`
+	Revenue       float64 `protobuf:"fixed64,6,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	AverageTicket float64 `protobuf:"fixed64,7,opt,name=average_ticket,json=averageTicket,proto3" json:"average_ticket,omitempty"`
+}
+
+func (x *SalesReportRowDto) Reset() {
+	*x = SalesReportRowDto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SalesReportRowDto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SalesReportRowDto) ProtoMessage() {}
+
+func (x *SalesReportRowDto) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SalesReportRowDto.ProtoReflect.Descriptor instead.
+func (*SalesReportRowDto) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SalesReportRowDto) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *SalesReportRowDto) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *SalesReportRowDto) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *SalesReportRowDto) GetOrders() int64 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *SalesReportRowDto) GetItems() int64 {
+	if x != nil {
+		return x.Items
+	}
+	return 0
+}
+
+func (x *SalesReportRowDto) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *SalesReportRowDto) GetAverageTicket() float64 {
+	if x != nil {
+		return x.AverageTicket
+	}
+	return 0
+}
+
+// from_date and to_date are UTC days, like in GetSalesReportRequest.
+type GetPrepTimeReportRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FromDate string   `protobuf:"bytes,1,opt,name=from_date,json=fromDate,proto3" json:"from_date,omitempty"`
+	ToDate   string   `protobuf:"bytes,2,opt,name=to_date,json=toDate,proto3" json:"to_date,omitempty"`
+	GroupBy  []string `protobuf:"bytes,3,rep,name=group_by,json=groupBy,proto3" json:"group_by,omitempty"`
+}
+
+func (x *GetPrepTimeReportRequest) Reset() {
+	*x = GetPrepTimeReportRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPrepTimeReportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPrepTimeReportRequest) ProtoMessage() {}
+
+func (x *GetPrepTimeReportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPrepTimeReportRequest.ProtoReflect.Descriptor instead.
+func (*GetPrepTimeReportRequest) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetPrepTimeReportRequest) GetFromDate() string {
+	if x != nil {
+		return x.FromDate
+	}
+	return ""
+}
+
+func (x *GetPrepTimeReportRequest) GetToDate() string {
+	if x != nil {
+		return x.ToDate
+	}
+	return ""
+}
+
+func (x *GetPrepTimeReportRequest) GetGroupBy() []string {
+	if x != nil {
+		return x.GroupBy
+	}
+	return nil
+}
+
+type GetPrepTimeReportResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Rows []*PrepTimeReportRowDto `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+}
+
+func (x *GetPrepTimeReportResponse) Reset() {
+	*x = GetPrepTimeReportResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetPrepTimeReportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPrepTimeReportResponse) ProtoMessage() {}
+
+func (x *GetPrepTimeReportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPrepTimeReportResponse.ProtoReflect.Descriptor instead.
+func (*GetPrepTimeReportResponse) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetPrepTimeReportResponse) GetRows() []*PrepTimeReportRowDto {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type PrepTimeReportRowDto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Day             string  `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Location        string  `protobuf:"bytes,2,opt,name=location,proto3" json:"location,omitempty"`
+	ItemType        string  `protobuf:"bytes,3,opt,name=item_type,json=itemType,proto3" json:"item_type,omitempty"`
+	Station         string  `protobuf:"bytes,4,opt,name=station,proto3" json:"station,omitempty"`
+	Actor           string  `protobuf:"bytes,5,opt,name=actor,proto3" json:"actor,omitempty"`
+	Items           int64   `protobuf:"varint,6,opt,name=items,proto3" json:"items,omitempty"`
+	AvgPrepSeconds  float64 `protobuf:"fixed64,7,opt,name=avg_prep_seconds,json=avgPrepSeconds,proto3" json:"avg_prep_seconds,omitempty"`
+	P50PrepSeconds  float64 `protobuf:"fixed64,8,opt,name=p50_prep_seconds,json=p50PrepSeconds,proto3" json:"p50_prep_seconds,omitempty"`
+	P90PrepSeconds  float64 `protobuf:"fixed64,9,opt,name=p90_prep_seconds,json=p90PrepSeconds,proto3" json:"p90_prep_seconds,omitempty"`
+	P95PrepSeconds  float64 `protobuf:"fixed64,10,opt,name=p95_prep_seconds,json=p95PrepSeconds,proto3" json:"p95_prep_seconds,omitempty"`
+	AvgTotalSeconds float64 `protobuf:"fixed64,11,opt,name=avg_total_seconds,json=avgTotalSeconds,proto3" json:"avg_total_seconds,omitempty"`
+}
+
+func (x *PrepTimeReportRowDto) Reset() {
+	*x = PrepTimeReportRowDto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_report_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PrepTimeReportRowDto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepTimeReportRowDto) ProtoMessage() {}
+
+func (x *PrepTimeReportRowDto) ProtoReflect() protoreflect.Message {
+	mi := &file_report_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepTimeReportRowDto.ProtoReflect.Descriptor instead.
+func (*PrepTimeReportRowDto) Descriptor() ([]byte, []int) {
+	return file_report_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PrepTimeReportRowDto) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *PrepTimeReportRowDto) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *PrepTimeReportRowDto) GetItemType() string {
+	if x != nil {
+		return x.ItemType
+	}
+	return ""
+}
+
+func (x *PrepTimeReportRowDto) GetStation() string {
+	if x != nil {
+		return x.Station
+	}
+	return ""
+}
+
+func (x *PrepTimeReportRowDto) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *PrepTimeReportRowDto) GetItems() int64 {
+	if x != nil {
+		return x.Items
+	}
+	return 0
+}
+
+func (x *PrepTimeReportRowDto) GetAvgPrepSeconds() float64 {
+	if x != nil {
+		return x.AvgPrepSeconds
+	}
+	return 0
+}
+
+func (x *PrepTimeReportRowDto) GetP50PrepSeconds() float64 {
+	if x != nil {
+		return x.P50PrepSeconds
+	}
+	return 0
+}
+
+func (x *PrepTimeReportRowDto) GetP90PrepSeconds() float64 {
+	if x != nil {
+		return x.P90PrepSeconds
+	}
+	return 0
+}
+
+func (x *PrepTimeReportRowDto) GetP95PrepSeconds() float64 {
+	if x != nil {
+		return x.P95PrepSeconds
+	}
+	return 0
+}
+
+func (x *PrepTimeReportRowDto) GetAvgTotalSeconds() float64 {
+	if x != nil {
+		return x.AvgTotalSeconds
+	}
+	return 0
+}
+
+var File_report_proto protoreflect.FileDescriptor
+
+var file_report_proto_rawDesc = []byte{
+	0x0a, 0x0c, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1d,
+	0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x1a, 0x1c, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x19, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x68, 0x74, 0x74, 0x70, 0x62, 0x6f, 0x64, 0x79,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x2d, 0x67,
+	0x65, 0x6e, 0x2d, 0x6f, 0x70, 0x65, 0x6e, 0x61, 0x70, 0x69, 0x76, 0x32, 0x2f, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1b, 0x62, 0x75, 0x66, 0x2f, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x61, 0x74, 0x65, 0x2f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x0a, 0x61, 0x75, 0x74, 0x68, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0xbe, 0x01, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x40, 0x0a, 0x09, 0x66, 0x72, 0x6f,
+	0x6d, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x42, 0x23, 0xba, 0x48,
+	0x20, 0x72, 0x1e, 0x32, 0x1c, 0x5e, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x34, 0x7d, 0x2d, 0x5b,
+	0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d,
+	0x24, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x44, 0x61, 0x74, 0x65, 0x12, 0x3c, 0x0a, 0x07, 0x74,
+	0x6f, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x42, 0x23, 0xba, 0x48,
+	0x20, 0x72, 0x1e, 0x32, 0x1c, 0x5e, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x34, 0x7d, 0x2d, 0x5b,
+	0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d,
+	0x24, 0x52, 0x06, 0x74, 0x6f, 0x44, 0x61, 0x74, 0x65, 0x12, 0x25, 0x0a, 0x08, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x42, 0x0a, 0xba, 0x48, 0x07,
+	0x92, 0x01, 0x04, 0x10, 0x05, 0x18, 0x01, 0x52, 0x07, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x42, 0x79,
+	0x22, 0x5e, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x04, 0x72, 0x6f,
+	0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x30, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f,
+	0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x2e, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x77, 0x44, 0x74, 0x6f, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73,
+	0x22, 0xcd, 0x01, 0x0a, 0x11, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x6f, 0x77, 0x44, 0x74, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x61, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x74, 0x79, 0x70,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x74, 0x65, 0x6d, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x74, 0x65,
+	0x6d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x72, 0x65, 0x76, 0x65, 0x6e, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x07, 0x72, 0x65, 0x76, 0x65, 0x6e, 0x75, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x76, 0x65,
+	0x72, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0d, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65, 0x54, 0x69, 0x63, 0x6b, 0x65, 0x74,
+	0x22, 0xc1, 0x01, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x40, 0x0a,
+	0x09, 0x66, 0x72, 0x6f, 0x6d, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x42, 0x23, 0xba, 0x48, 0x20, 0x72, 0x1e, 0x32, 0x1c, 0x5e, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b,
+	0x34, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39,
+	0x5d, 0x7b, 0x32, 0x7d, 0x24, 0x52, 0x08, 0x66, 0x72, 0x6f, 0x6d, 0x44, 0x61, 0x74, 0x65, 0x12,
+	0x3c, 0x0a, 0x07, 0x74, 0x6f, 0x5f, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x42, 0x23, 0xba, 0x48, 0x20, 0x72, 0x1e, 0x32, 0x1c, 0x5e, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b,
+	0x34, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39, 0x5d, 0x7b, 0x32, 0x7d, 0x2d, 0x5b, 0x30, 0x2d, 0x39,
+	0x5d, 0x7b, 0x32, 0x7d, 0x24, 0x52, 0x06, 0x74, 0x6f, 0x44, 0x61, 0x74, 0x65, 0x12, 0x25, 0x0a,
+	0x08, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x5f, 0x62, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x42,
+	0x0a, 0xba, 0x48, 0x07, 0x92, 0x01, 0x04, 0x10, 0x05, 0x18, 0x01, 0x52, 0x07, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x42, 0x79, 0x22, 0x64, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54,
+	0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x47, 0x0a, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x33, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x2e,
+	0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f,
+	0x77, 0x44, 0x74, 0x6f, 0x52, 0x04, 0x72, 0x6f, 0x77, 0x73, 0x22, 0xfb, 0x02, 0x0a, 0x14, 0x50,
+	0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x6f, 0x77,
+	0x44, 0x74, 0x6f, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x64, 0x61, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x69, 0x74, 0x65, 0x6d, 0x54, 0x79, 0x70, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x73, 0x74, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x63, 0x74, 0x6f,
+	0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x61, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x14,
+	0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x69,
+	0x74, 0x65, 0x6d, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x61, 0x76, 0x67, 0x5f, 0x70, 0x72, 0x65, 0x70,
+	0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e,
+	0x61, 0x76, 0x67, 0x50, 0x72, 0x65, 0x70, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x28,
+	0x0a, 0x10, 0x70, 0x35, 0x30, 0x5f, 0x70, 0x72, 0x65, 0x70, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x70, 0x35, 0x30, 0x50, 0x72, 0x65,
+	0x70, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x39, 0x30, 0x5f,
+	0x70, 0x72, 0x65, 0x70, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x0e, 0x70, 0x39, 0x30, 0x50, 0x72, 0x65, 0x70, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x73, 0x12, 0x28, 0x0a, 0x10, 0x70, 0x39, 0x35, 0x5f, 0x70, 0x72, 0x65, 0x70, 0x5f, 0x73,
+	0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0e, 0x70, 0x39,
+	0x35, 0x50, 0x72, 0x65, 0x70, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x2a, 0x0a, 0x11,
+	0x61, 0x76, 0x67, 0x5f, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x61, 0x76, 0x67, 0x54, 0x6f, 0x74, 0x61,
+	0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x32, 0xc4, 0x08, 0x0a, 0x0d, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0xab, 0x02, 0x0a, 0x0e, 0x47,
+	0x65, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x34, 0x2e,
+	0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x35, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73,
+	0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0xab, 0x01, 0x90, 0x02, 0x01,
+	0x92, 0x41, 0x80, 0x01, 0x0a, 0x07, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x10, 0x47,
+	0x65, 0x74, 0x20, 0x73, 0x61, 0x6c, 0x65, 0x73, 0x20, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x1a,
+	0x63, 0x47, 0x65, 0x74, 0x20, 0x73, 0x61, 0x6c, 0x65, 0x73, 0x2c, 0x20, 0x69, 0x74, 0x65, 0x6d,
+	0x20, 0x6d, 0x69, 0x78, 0x20, 0x61, 0x6e, 0x64, 0x20, 0x61, 0x76, 0x65, 0x72, 0x61, 0x67, 0x65,
+	0x20, 0x74, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x61, 0x20, 0x64, 0x61,
+	0x74, 0x65, 0x20, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x2c, 0x20, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x65,
+	0x64, 0x20, 0x62, 0x79, 0x20, 0x64, 0x61, 0x79, 0x2c, 0x20, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x20, 0x61, 0x6e, 0x64, 0x2f, 0x6f, 0x72, 0x20, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x74,
+	0x79, 0x70, 0x65, 0x2e, 0xc2, 0xf3, 0x18, 0x03, 0x12, 0x01, 0x04, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x17, 0x12, 0x15, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x73, 0x2f, 0x73, 0x61, 0x6c, 0x65, 0x73, 0x12, 0xcf, 0x02, 0x0a, 0x11, 0x47, 0x65, 0x74,
+	0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x12, 0x37,
+	0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x2e, 0x47,
+	0x65, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x38, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66,
+	0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54,
+	0x69, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0xc6, 0x01, 0x90, 0x02, 0x01, 0x92, 0x41, 0x96, 0x01, 0x0a, 0x07, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x73, 0x12, 0x14, 0x47, 0x65, 0x74, 0x20, 0x70, 0x72, 0x65, 0x70, 0x20, 0x74,
+	0x69, 0x6d, 0x65, 0x20, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x1a, 0x75, 0x47, 0x65, 0x74, 0x20,
+	0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x70, 0x75, 0x74, 0x20, 0x61, 0x6e, 0x64, 0x20, 0x70,
+	0x72, 0x65, 0x70, 0x20, 0x74, 0x69, 0x6d, 0x65, 0x20, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x69, 0x6c, 0x65, 0x73, 0x20, 0x66, 0x6f, 0x72, 0x20, 0x61, 0x20, 0x64, 0x61, 0x74, 0x65, 0x20,
+	0x72, 0x61, 0x6e, 0x67, 0x65, 0x2c, 0x20, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x65, 0x64, 0x20, 0x62,
+	0x79, 0x20, 0x64, 0x61, 0x79, 0x2c, 0x20, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2c,
+	0x20, 0x69, 0x74, 0x65, 0x6d, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x2c, 0x20, 0x73, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x20, 0x61, 0x6e, 0x64, 0x2f, 0x6f, 0x72, 0x20, 0x61, 0x63, 0x74, 0x6f, 0x72,
+	0x2e, 0xc2, 0xf3, 0x18, 0x03, 0x12, 0x01, 0x04, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1c, 0x12, 0x1a,
+	0x2f, 0x76, 0x31, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x2f,
+	0x70, 0x72, 0x65, 0x70, 0x2d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x12, 0xce, 0x01, 0x0a, 0x11, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x12, 0x34, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65, 0x73, 0x68, 0x6f, 0x70,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x61, 0x70, 0x69,
+	0x2e, 0x47, 0x65, 0x74, 0x53, 0x61, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x61, 0x70, 0x69, 0x2e, 0x48, 0x74, 0x74, 0x70, 0x42, 0x6f, 0x64, 0x79, 0x22, 0x6d, 0x90, 0x02,
+	0x01, 0x92, 0x41, 0x3f, 0x0a, 0x07, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73, 0x12, 0x13, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x73, 0x61, 0x6c, 0x65, 0x73, 0x20, 0x72, 0x65, 0x70, 0x6f,
+	0x72, 0x74, 0x1a, 0x1f, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x74, 0x68, 0x65, 0x20, 0x73,
+	0x61, 0x6c, 0x65, 0x73, 0x20, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x61, 0x73, 0x20, 0x43,
+	0x53, 0x56, 0x2e, 0xc2, 0xf3, 0x18, 0x03, 0x12, 0x01, 0x04, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1b,
+	0x12, 0x19, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x73, 0x2f, 0x73, 0x61, 0x6c, 0x65, 0x73, 0x2f, 0x63, 0x73, 0x76, 0x12, 0xe1, 0x01, 0x0a, 0x14,
+	0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x65,
+	0x70, 0x6f, 0x72, 0x74, 0x12, 0x37, 0x2e, 0x67, 0x6f, 0x2e, 0x63, 0x6f, 0x66, 0x66, 0x65, 0x65,
+	0x73, 0x68, 0x6f, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x72, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x61, 0x70, 0x69, 0x2e, 0x47, 0x65, 0x74, 0x50, 0x72, 0x65, 0x70, 0x54, 0x69, 0x6d, 0x65,
+	0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x61, 0x70, 0x69, 0x2e, 0x48, 0x74, 0x74, 0x70, 0x42,
+	0x6f, 0x64, 0x79, 0x22, 0x7a, 0x90, 0x02, 0x01, 0x92, 0x41, 0x47, 0x0a, 0x07, 0x52, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x73, 0x12, 0x17, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x70, 0x72, 0x65,
+	0x70, 0x20, 0x74, 0x69, 0x6d, 0x65, 0x20, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x1a, 0x23, 0x45,
+	0x78, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x74, 0x68, 0x65, 0x20, 0x70, 0x72, 0x65, 0x70, 0x20, 0x74,
+	0x69, 0x6d, 0x65, 0x20, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x20, 0x61, 0x73, 0x20, 0x43, 0x53,
+	0x56, 0x2e, 0xc2, 0xf3, 0x18, 0x03, 0x12, 0x01, 0x04, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x20, 0x12,
+	0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x73,
+	0x2f, 0x70, 0x72, 0x65, 0x70, 0x2d, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x2f, 0x63, 0x73, 0x76, 0x42,
+	0x2f, 0x5a, 0x2d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x68,
+	0x61, 0x6e, 0x67, 0x63, 0x68, 0x75, 0x6e, 0x67, 0x2f, 0x67, 0x6f, 0x2d, 0x63, 0x6f, 0x66, 0x66,
+	0x65, 0x65, 0x73, 0x68, 0x6f, 0x70, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x67, 0x65, 0x6e,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_report_proto_rawDescOnce sync.Once
+	file_report_proto_rawDescData = file_report_proto_rawDesc
+)
+
+func file_report_proto_rawDescGZIP() []byte {
+	file_report_proto_rawDescOnce.Do(func() {
+		file_report_proto_rawDescData = protoimpl.X.CompressGZIP(file_report_proto_rawDescData)
+	})
+	return file_report_proto_rawDescData
+}
+
+var file_report_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_report_proto_goTypes = []interface{}{
+	(*GetSalesReportRequest)(nil),     // 0: go.coffeeshop.proto.reportapi.GetSalesReportRequest
+	(*GetSalesReportResponse)(nil),    // 1: go.coffeeshop.proto.reportapi.GetSalesReportResponse
+	(*SalesReportRowDto)(nil),         // 2: go.coffeeshop.proto.reportapi.SalesReportRowDto
+	(*GetPrepTimeReportRequest)(nil),  // 3: go.coffeeshop.proto.reportapi.GetPrepTimeReportRequest
+	(*GetPrepTimeReportResponse)(nil), // 4: go.coffeeshop.proto.reportapi.GetPrepTimeReportResponse
+	(*PrepTimeReportRowDto)(nil),      // 5: go.coffeeshop.proto.reportapi.PrepTimeReportRowDto
+	(*httpbody.HttpBody)(nil),         // 6: google.api.HttpBody
+}
+var file_report_proto_depIdxs = []int32{
+	2, // 0: go.coffeeshop.proto.reportapi.GetSalesReportResponse.rows:type_name -> go.coffeeshop.proto.reportapi.SalesReportRowDto
+	5, // 1: go.coffeeshop.proto.reportapi.GetPrepTimeReportResponse.rows:type_name -> go.coffeeshop.proto.reportapi.PrepTimeReportRowDto
+	0, // 2: go.coffeeshop.proto.reportapi.ReportService.GetSalesReport:input_type -> go.coffeeshop.proto.reportapi.GetSalesReportRequest
+	3, // 3: go.coffeeshop.proto.reportapi.ReportService.GetPrepTimeReport:input_type -> go.coffeeshop.proto.reportapi.GetPrepTimeReportRequest
+	0, // 4: go.coffeeshop.proto.reportapi.ReportService.ExportSalesReport:input_type -> go.coffeeshop.proto.reportapi.GetSalesReportRequest
+	3, // 5: go.coffeeshop.proto.reportapi.ReportService.ExportPrepTimeReport:input_type -> go.coffeeshop.proto.reportapi.GetPrepTimeReportRequest
+	1, // 6: go.coffeeshop.proto.reportapi.ReportService.GetSalesReport:output_type -> go.coffeeshop.proto.reportapi.GetSalesReportResponse
+	4, // 7: go.coffeeshop.proto.reportapi.ReportService.GetPrepTimeReport:output_type -> go.coffeeshop.proto.reportapi.GetPrepTimeReportResponse
+	6, // 8: go.coffeeshop.proto.reportapi.ReportService.ExportSalesReport:output_type -> google.api.HttpBody
+	6, // 9: go.coffeeshop.proto.reportapi.ReportService.ExportPrepTimeReport:output_type -> google.api.HttpBody
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_report_proto_init() }
+func file_report_proto_init() {
+	if File_report_proto != nil {
+		return
+	}
+	file_auth_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_report_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSalesReportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_report_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetSalesReportResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_report_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SalesReportRowDto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_report_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPrepTimeReportRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_report_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetPrepTimeReportResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_report_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PrepTimeReportRowDto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_report_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_report_proto_goTypes,
+		DependencyIndexes: file_report_proto_depIdxs,
+		MessageInfos:      file_report_proto_msgTypes,
+	}.Build()
+	File_report_proto = out.File
+	file_report_proto_rawDesc = nil
+	file_report_proto_goTypes = nil
+	file_report_proto_depIdxs = nil
+}
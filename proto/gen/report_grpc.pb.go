@@ -0,0 +1,212 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: report.proto
+
+package gen
+
+import (
+	context "context"
+	httpbody "google.golang.org/genproto/googleapis/api/httpbody"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// ReportServiceClient is the client API for ReportService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ReportServiceClient interface {
+	GetSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*GetSalesReportResponse, error)
+	GetPrepTimeReport(ctx context.Context, in *GetPrepTimeReportRequest, opts ...grpc.CallOption) (*GetPrepTimeReportResponse, error)
+	ExportSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+	ExportPrepTimeReport(ctx context.Context, in *GetPrepTimeReportRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error)
+}
+
+type reportServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReportServiceClient(cc grpc.ClientConnInterface) ReportServiceClient {
+	return &reportServiceClient{cc}
+}
+
+func (c *reportServiceClient) GetSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*GetSalesReportResponse, error) {
+	out := new(GetSalesReportResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.reportapi.ReportService/GetSalesReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) GetPrepTimeReport(ctx context.Context, in *GetPrepTimeReportRequest, opts ...grpc.CallOption) (*GetPrepTimeReportResponse, error) {
+	out := new(GetPrepTimeReportResponse)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.reportapi.ReportService/GetPrepTimeReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) ExportSalesReport(ctx context.Context, in *GetSalesReportRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.reportapi.ReportService/ExportSalesReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reportServiceClient) ExportPrepTimeReport(ctx context.Context, in *GetPrepTimeReportRequest, opts ...grpc.CallOption) (*httpbody.HttpBody, error) {
+	out := new(httpbody.HttpBody)
+	err := c.cc.Invoke(ctx, "/go.coffeeshop.proto.reportapi.ReportService/ExportPrepTimeReport", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReportServiceServer is the server API for ReportService service.
+// All implementations should embed UnimplementedReportServiceServer
+// for forward compatibility
+type ReportServiceServer interface {
+	GetSalesReport(context.Context, *GetSalesReportRequest) (*GetSalesReportResponse, error)
+	GetPrepTimeReport(context.Context, *GetPrepTimeReportRequest) (*GetPrepTimeReportResponse, error)
+	ExportSalesReport(context.Context, *GetSalesReportRequest) (*httpbody.HttpBody, error)
+	ExportPrepTimeReport(context.Context, *GetPrepTimeReportRequest) (*httpbody.HttpBody, error)
+}
+
+// UnimplementedReportServiceServer should be embedded to have forward compatible implementations.
+type UnimplementedReportServiceServer struct {
+}
+
+func (UnimplementedReportServiceServer) GetSalesReport(context.Context, *GetSalesReportRequest) (*GetSalesReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSalesReport not implemented")
+}
+func (UnimplementedReportServiceServer) GetPrepTimeReport(context.Context, *GetPrepTimeReportRequest) (*GetPrepTimeReportResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrepTimeReport not implemented")
+}
+func (UnimplementedReportServiceServer) ExportSalesReport(context.Context, *GetSalesReportRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportSalesReport not implemented")
+}
+func (UnimplementedReportServiceServer) ExportPrepTimeReport(context.Context, *GetPrepTimeReportRequest) (*httpbody.HttpBody, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportPrepTimeReport not implemented")
+}
+
+// UnsafeReportServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReportServiceServer will
+// result in compilation errors.
+type UnsafeReportServiceServer interface {
+	mustEmbedUnimplementedReportServiceServer()
+}
+
+func RegisterReportServiceServer(s grpc.ServiceRegistrar, srv ReportServiceServer) {
+	s.RegisterService(&ReportService_ServiceDesc, srv)
+}
+
+func _ReportService_GetSalesReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSalesReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).GetSalesReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.reportapi.ReportService/GetSalesReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).GetSalesReport(ctx, req.(*GetSalesReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_GetPrepTimeReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPrepTimeReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).GetPrepTimeReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.reportapi.ReportService/GetPrepTimeReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).GetPrepTimeReport(ctx, req.(*GetPrepTimeReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_ExportSalesReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSalesReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).ExportSalesReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.reportapi.ReportService/ExportSalesReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).ExportSalesReport(ctx, req.(*GetSalesReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReportService_ExportPrepTimeReport_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPrepTimeReportRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReportServiceServer).ExportPrepTimeReport(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/go.coffeeshop.proto.reportapi.ReportService/ExportPrepTimeReport",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReportServiceServer).ExportPrepTimeReport(ctx, req.(*GetPrepTimeReportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReportService_ServiceDesc is the grpc.ServiceDesc for ReportService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReportService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "go.coffeeshop.proto.reportapi.ReportService",
+	HandlerType: (*ReportServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetSalesReport",
+			Handler:    _ReportService_GetSalesReport_Handler,
+		},
+		{
+			MethodName: "GetPrepTimeReport",
+			Handler:    _ReportService_GetPrepTimeReport_Handler,
+		},
+		{
+			MethodName: "ExportSalesReport",
+			Handler:    _ReportService_ExportSalesReport_Handler,
+		},
+		{
+			MethodName: "ExportPrepTimeReport",
+			Handler:    _ReportService_ExportPrepTimeReport_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "report.proto",
+}
@@ -0,0 +1,104 @@
+package integration_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+)
+
+const (
+	_cashierKey = "cashier-key"
+	_baristaKey = "barista-key"
+	_webOrigin  = "http://localhost:8888"
+)
+
+// apiKeys are a till with the cashier role and a screen at the bar with the barista role.
+func apiKeys(t *testing.T) configs.Auth {
+	t.Helper()
+
+	entry := func(name, key, role string) string {
+		sum := sha256.Sum256([]byte(key))
+
+		return "  - name: " + name + "\n    sha256: " + hex.EncodeToString(sum[:]) + "\n    roles: [" + role + "]\n"
+	}
+
+	file := filepath.Join(t.TempDir(), "api_keys.yml")
+	content := "keys:\n" + entry("till", _cashierKey, "cashier") + entry("bar", _baristaKey, "barista")
+	require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	return configs.Auth{Enabled: true, APIKeysFile: file}
+}
+
+func withAPIKey(key string) http.Header {
+	return http.Header{"X-Api-Key": []string{key}}
+}
+
+func TestOnlyCashiersPlaceOrdersWhenAuthIsEnabled(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t, withAuth(apiKeys(t)))
+
+	order := placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    items(shared.ItemTypeLatte),
+	}
+
+	code, body := shop.doWith(nil, http.MethodPost, "/v1/api/orders", order, nil)
+	assert.Equal(t, http.StatusUnauthorized, code, body)
+
+	code, body = shop.doWith(withAPIKey("guessed"), http.MethodPost, "/v1/api/orders", order, nil)
+	assert.Equal(t, http.StatusUnauthorized, code, body)
+
+	code, body = shop.doWith(withAPIKey(_baristaKey), http.MethodPost, "/v1/api/orders", order, nil)
+	assert.Equal(t, http.StatusForbidden, code, body)
+
+	// the counter looks the items up at the product service with the key of the till
+	code, body = shop.doWith(withAPIKey(_cashierKey), http.MethodPost, "/v1/api/orders", order, nil)
+	require.Equal(t, http.StatusOK, code, body)
+
+	// the menu is public
+	code, body = shop.doWith(nil, http.MethodGet, "/v1/api/item-types", nil, nil)
+	assert.Equal(t, http.StatusOK, code, body)
+
+	code, body = shop.doWith(withAPIKey(_baristaKey), http.MethodGet, "/v1/fulfillment-orders", nil, nil)
+	assert.Equal(t, http.StatusOK, code, body)
+}
+
+func TestCORSOnlyAllowsTheConfiguredOrigins(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t, withOrigins(_webOrigin))
+
+	preflight := func(origin string) *http.Response {
+		req, err := http.NewRequest(http.MethodOptions, shop.server.URL+"/v1/api/orders", nil)
+		require.NoError(t, err)
+
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		req.Header.Set("Access-Control-Request-Headers", "content-type,x-api-key")
+
+		res, err := shop.server.Client().Do(req)
+		require.NoError(t, err)
+		res.Body.Close()
+
+		return res
+	}
+
+	res := preflight(_webOrigin)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	assert.Equal(t, _webOrigin, res.Header.Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, res.Header.Get("Access-Control-Allow-Headers"), "X-API-Key")
+
+	res = preflight("https://evil.example")
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	assert.Empty(t, res.Header.Get("Access-Control-Allow-Origin"))
+}
@@ -0,0 +1,329 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/thangchung/go-coffeeshop/cmd/coffeeshop/config"
+	"github.com/thangchung/go-coffeeshop/internal/gateway"
+	"github.com/thangchung/go-coffeeshop/internal/inprocess"
+	"github.com/thangchung/go-coffeeshop/pkg/auth"
+	configs "github.com/thangchung/go-coffeeshop/pkg/config"
+	"github.com/thangchung/go-coffeeshop/pkg/grpcx"
+	"github.com/thangchung/go-coffeeshop/pkg/health"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	_fulfilmentTimeout = 10 * time.Second
+	_pollInterval      = 20 * time.Millisecond
+)
+
+// coffeeshop is every service booted in the test process on a SQLite file and the in-memory bus,
+// the test talks to it through the gateway like the web UI does.
+type coffeeshop struct {
+	t      *testing.T
+	bus    *inmem.Bus
+	server *httptest.Server
+}
+
+type options struct {
+	productDown bool
+	auth        configs.Auth
+	origins     []string
+}
+
+type option func(*options)
+
+// withProductDown leaves the product service out, the counter and the gateway dial a listener nobody serves.
+func withProductDown() option {
+	return func(o *options) {
+		o.productDown = true
+	}
+}
+
+// withAuth makes the gateway and the services check the callers against cfg.
+func withAuth(cfg configs.Auth) option {
+	return func(o *options) {
+		o.auth = cfg
+	}
+}
+
+// withOrigins lets the pages of origins call the gateway from a browser.
+func withOrigins(origins ...string) option {
+	return func(o *options) {
+		o.origins = origins
+	}
+}
+
+func newCoffeeshop(t *testing.T, opts ...option) *coffeeshop {
+	t.Helper()
+
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	cfg := &config.Config{
+		App:  configs.App{Name: "coffeeshop-integration", Version: "test"},
+		GRPC: configs.GRPC{Timeout: 5 * time.Second},
+		PG: configs.PG{
+			Driver: postgres.DriverSQLite,
+			DsnURL: filepath.Join(t.TempDir(), "coffeeshop.db"),
+		},
+		// the items are made at once, the flow is the same
+		Preparation: configs.Preparation{TimeScale: 0},
+		Auth:        o.auth,
+		ProductCache: config.ProductCache{
+			TTL:      time.Minute,
+			MaxStale: time.Minute,
+		},
+	}
+	cfg.Images.Dir = t.TempDir()
+	cfg.Reporting.RefreshInterval = time.Minute
+
+	pg, closeDB, err := inprocess.OpenDatabase(cfg)
+	require.NoError(t, err)
+
+	bus := inmem.NewBus()
+
+	// the subscriptions end before the database goes away
+	t.Cleanup(func() {
+		bus.Close()
+		closeDB()
+	})
+
+	checker := health.NewChecker()
+
+	var productConn *grpc.ClientConn
+	if o.productDown {
+		productConn = dialNobody(t)
+	} else {
+		productConn, err = inprocess.StartProduct(ctx, cfg, bus, checker)
+		require.NoError(t, err)
+	}
+
+	counterConn, err := inprocess.StartCounter(ctx, cfg, pg, bus, productConn, checker)
+	require.NoError(t, err)
+	require.NoError(t, inprocess.StartBarista(cfg, pg, bus, checker))
+	require.NoError(t, inprocess.StartKitchen(cfg, pg, bus, checker))
+
+	gw, err := gateway.New(ctx, productConn, counterConn)
+	require.NoError(t, err)
+
+	authn, err := auth.NewAuthenticator(cfg.Auth)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(gateway.Handler(
+		cfg.Name,
+		gw,
+		gateway.AllowedOrigins(o.origins...),
+		gateway.Authenticator(authn),
+	))
+	t.Cleanup(server.Close)
+
+	return &coffeeshop{t: t, bus: bus, server: server}
+}
+
+// dialNobody connects to a listener which is closed, every call fails as if the service were down.
+func dialNobody(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	l := bufconn.Listen(1024)
+	require.NoError(t, l.Close())
+
+	conn, err := grpcx.Dial(
+		"product-service",
+		grpcx.CallTimeout(time.Second),
+		grpcx.Dialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return l.DialContext(ctx)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// do sends a JSON request to the gateway and decodes the response into out unless it is nil,
+// it returns the status code and the body.
+func (c *coffeeshop) do(method, path string, in interface{}, out proto.Message) (int, string) {
+	c.t.Helper()
+
+	return c.doWith(nil, method, path, in, out)
+}
+
+// doWith is do with the headers of a browser or a device, like its credentials.
+func (c *coffeeshop) doWith(header http.Header, method, path string, in interface{}, out proto.Message) (int, string) {
+	c.t.Helper()
+
+	var body io.Reader
+
+	if in != nil {
+		b, err := json.Marshal(in)
+		require.NoError(c.t, err)
+
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, c.server.URL+path, body)
+	require.NoError(c.t, err)
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := c.server.Client().Do(req)
+	require.NoError(c.t, err)
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	require.NoError(c.t, err)
+
+	if out != nil && res.StatusCode == http.StatusOK {
+		require.NoError(c.t, protojson.Unmarshal(b, out), string(b))
+	}
+
+	return res.StatusCode, string(b)
+}
+
+type placeOrder struct {
+	LoyaltyMemberID string        `json:"loyaltyMemberId"`
+	Location        int32         `json:"location"`
+	BaristaItems    []commandItem `json:"baristaItems,omitempty"`
+	KitchenItems    []commandItem `json:"kitchenItems,omitempty"`
+}
+
+type commandItem struct {
+	ItemType int32 `json:"itemType"`
+}
+
+func (c *coffeeshop) placeOrder(order placeOrder) (int, string) {
+	c.t.Helper()
+
+	return c.do(http.MethodPost, "/v1/api/orders", order, nil)
+}
+
+func (c *coffeeshop) orders() []*gen.OrderDto {
+	c.t.Helper()
+
+	var res gen.GetListOrderFulfillmentResponse
+
+	code, body := c.do(http.MethodGet, "/v1/fulfillment-orders", nil, &res)
+	require.Equal(c.t, http.StatusOK, code, body)
+
+	return res.GetOrders()
+}
+
+func (c *coffeeshop) timeline(orderID string) []*gen.TimelineEntryDto {
+	c.t.Helper()
+
+	var res gen.GetOrderTimelineResponse
+
+	code, body := c.do(http.MethodGet, fmt.Sprintf("/v1/api/orders/%s/timeline", orderID), nil, &res)
+	require.Equal(c.t, http.StatusOK, code, body)
+
+	return res.GetEntries()
+}
+
+// waitForOrders polls until there are n orders and every one of them satisfies done.
+func (c *coffeeshop) waitForOrders(n int, done func(*gen.OrderDto) bool) []*gen.OrderDto {
+	c.t.Helper()
+
+	var orders []*gen.OrderDto
+
+	require.Eventually(c.t, func() bool {
+		orders = c.orders()
+		if len(orders) != n {
+			return false
+		}
+
+		for _, o := range orders {
+			if !done(o) {
+				return false
+			}
+		}
+
+		return true
+	}, _fulfilmentTimeout, _pollInterval, "orders never got there: %v", &orders)
+
+	return orders
+}
+
+// spy copies every message published with the routing of a queue into a queue of its own,
+// the service keeps consuming the messages as usual.
+type spy struct {
+	mu       sync.Mutex
+	messages []messaging.Delivery
+}
+
+func (c *coffeeshop) spy(exchange, bindingKey string) *spy {
+	c.t.Helper()
+
+	s := &spy{}
+
+	sub, err := c.bus.NewSubscriber(
+		messaging.ExchangeName(exchange),
+		messaging.QueueName(c.t.Name()+"-"+exchange+"-spy"),
+		messaging.BindingKey(bindingKey),
+	)
+	require.NoError(c.t, err)
+
+	go func() {
+		_ = sub.Subscribe(func(_ context.Context, deliveries <-chan messaging.Delivery) {
+			for d := range deliveries {
+				s.mu.Lock()
+				s.messages = append(s.messages, d)
+				s.mu.Unlock()
+
+				_ = d.Ack(false)
+			}
+		})
+	}()
+
+	return s
+}
+
+func (s *spy) received() []messaging.Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]messaging.Delivery(nil), s.messages...)
+}
+
+// redeliver publishes the messages again with their type, as a broker does after a lost ack.
+func (c *coffeeshop) redeliver(exchange, bindingKey string, deliveries []messaging.Delivery) {
+	c.t.Helper()
+
+	for _, d := range deliveries {
+		pub, err := c.bus.NewPublisher(
+			messaging.ExchangeName(exchange),
+			messaging.BindingKey(bindingKey),
+			messaging.MessageTypeName(d.Type),
+		)
+		require.NoError(c.t, err)
+		require.NoError(c.t, pub.Publish(context.Background(), d.Body, d.ContentType))
+	}
+}
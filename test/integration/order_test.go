@@ -0,0 +1,213 @@
+package integration_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	gen "github.com/thangchung/go-coffeeshop/proto/gen"
+)
+
+func fulfilled(o *gen.OrderDto) bool {
+	if o.GetOrderStatus() != int32(shared.StatusFulfilled) {
+		return false
+	}
+
+	for _, item := range o.GetLineItems() {
+		if item.GetItemStatus() != int32(shared.StatusFulfilled) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func items(types ...shared.ItemType) []commandItem {
+	result := make([]commandItem, 0, len(types))
+	for _, t := range types {
+		result = append(result, commandItem{ItemType: int32(t)})
+	}
+
+	return result
+}
+
+func TestPlacedOrderIsFulfilledByTheBaristaAndTheKitchen(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t)
+
+	code, body := shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		Location:        int32(shared.LocationAtlanta),
+		BaristaItems:    items(shared.ItemTypeCappuccino, shared.ItemTypeLatte),
+		KitchenItems:    items(shared.ItemTypeCroissant),
+	})
+	require.Equal(t, http.StatusOK, code, body)
+
+	orders := shop.waitForOrders(1, fulfilled)
+
+	order := orders[0]
+	assert.Len(t, order.GetLineItems(), 3)
+
+	// every item went through the counter and its station
+	stages := map[string]int{}
+	for _, entry := range shop.timeline(order.GetId()) {
+		stages[entry.GetStage()]++
+	}
+
+	assert.Equal(t, map[string]int{"placed": 3, "sent_to_station": 3, "started": 3, "completed": 3}, stages)
+}
+
+func TestOrdersOfSeveralCustomersAreAllFulfilled(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t)
+
+	const n = 5
+
+	for i := 0; i < n; i++ {
+		code, body := shop.placeOrder(placeOrder{
+			LoyaltyMemberID: uuid.NewString(),
+			BaristaItems:    items(shared.ItemTypeEspresso),
+			KitchenItems:    items(shared.ItemTypeMuffin),
+		})
+		require.Equal(t, http.StatusOK, code, body)
+	}
+
+	shop.waitForOrders(n, fulfilled)
+}
+
+func TestDuplicateDeliveriesChangeNothing(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t)
+
+	// what the counter sends to the stations and what they send back
+	toBarista := shop.spy("barista-order-exchange", "barista-order-routing-key")
+	toKitchen := shop.spy("kitchen-order-exchange", "kitchen-order-routing-key")
+	toCounter := shop.spy("counter-order-exchange", "counter-order-routing-key")
+
+	code, body := shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    items(shared.ItemTypeCoffeeBlack),
+		KitchenItems:    items(shared.ItemTypeCakePop),
+	})
+	require.Equal(t, http.StatusOK, code, body)
+
+	order := shop.waitForOrders(1, fulfilled)[0]
+	before := shop.timeline(order.GetId())
+
+	require.Eventually(t, func() bool {
+		return len(toBarista.received()) == 1 && len(toKitchen.received()) == 1 && len(toCounter.received()) == 2
+	}, _fulfilmentTimeout, _pollInterval)
+
+	// the stations get their orders again and the counter the updates
+	shop.redeliver("barista-order-exchange", "barista-order-routing-key", toBarista.received())
+	shop.redeliver("kitchen-order-exchange", "kitchen-order-routing-key", toKitchen.received())
+	shop.redeliver("counter-order-exchange", "counter-order-routing-key", toCounter.received()[:2])
+
+	// a station which made the item already doesn't make it again
+	require.Eventually(t, func() bool {
+		return len(toBarista.received()) == 2 && len(toKitchen.received()) == 2 && len(toCounter.received()) == 4
+	}, _fulfilmentTimeout, _pollInterval)
+
+	// a later order shows that the redeliveries have been consumed
+	code, body = shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    items(shared.ItemTypeCoffeeBlack),
+	})
+	require.Equal(t, http.StatusOK, code, body)
+
+	orders := shop.waitForOrders(2, fulfilled)
+
+	for _, o := range orders {
+		if o.GetId() == order.GetId() {
+			assert.Len(t, o.GetLineItems(), 2)
+		}
+	}
+
+	assert.Len(t, toCounter.received(), 5)
+	assert.Len(t, shop.timeline(order.GetId()), len(before))
+}
+
+func TestUnknownItemTypeIsRejectedAndNothingIsStored(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t)
+
+	code, body := shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    items(shared.ItemTypeLatte, shared.ItemType(999)),
+	})
+	assert.Equal(t, http.StatusBadRequest, code, body)
+	assert.Contains(t, body, "is unknown")
+
+	code, body = shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		KitchenItems:    []commandItem{{ItemType: -1}},
+	})
+	assert.Equal(t, http.StatusBadRequest, code, body)
+
+	assert.Empty(t, shop.orders())
+}
+
+func TestNoOrderIsTakenWhileTheProductServiceIsDown(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t, withProductDown())
+
+	code, body := shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    items(shared.ItemTypeCappuccino),
+	})
+	assert.Equal(t, http.StatusServiceUnavailable, code, body)
+
+	assert.Empty(t, shop.orders())
+}
+
+func TestImportedItemIsOrderedUnderItsCatalogName(t *testing.T) {
+	t.Parallel()
+
+	shop := newCoffeeshop(t)
+
+	var imported gen.ImportCatalogResponse
+
+	code, body := shop.do(http.MethodPost, "/v1/api/catalog/import", map[string]string{
+		"format": "csv",
+		"data":   "name,sku,price,station\nFLAT_WHITE,BAR-FW,4.2,barista\n",
+	}, &imported)
+	require.Equal(t, http.StatusOK, code, body)
+	require.True(t, imported.GetApplied(), body)
+	require.Len(t, imported.GetChanges(), 1, body)
+
+	// the first imported item gets the type after the ones this build has names for
+	itemType := imported.GetChanges()[0].GetAfter().GetType()
+	require.Greater(t, itemType, int32(shared.ItemTypeCroissantChocolate))
+
+	code, body = shop.placeOrder(placeOrder{
+		LoyaltyMemberID: uuid.NewString(),
+		BaristaItems:    []commandItem{{ItemType: itemType}},
+	})
+	require.Equal(t, http.StatusOK, code, body)
+
+	orders := shop.waitForOrders(1, fulfilled)
+
+	require.Len(t, orders[0].GetLineItems(), 1)
+	assert.Equal(t, "FLAT_WHITE", orders[0].GetLineItems()[0].GetName())
+	assert.InDelta(t, 4.2, orders[0].GetLineItems()[0].GetPrice(), 0.001)
+
+	// the item mix shows the item under the same name
+	var report gen.GetSalesReportResponse
+
+	// a day on either side, the order may have been placed before midnight
+	from := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	to := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	code, body = shop.do(http.MethodGet, "/v1/api/reports/sales?from_date="+from+"&to_date="+to+"&group_by=item_type", nil, &report)
+	require.Equal(t, http.StatusOK, code, body)
+	require.Len(t, report.GetRows(), 1, body)
+	assert.Equal(t, "FLAT_WHITE", report.GetRows()[0].GetItemType())
+}
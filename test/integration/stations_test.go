@@ -0,0 +1,179 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	baristaConfig "github.com/thangchung/go-coffeeshop/cmd/barista/config"
+	kitchenConfig "github.com/thangchung/go-coffeeshop/cmd/kitchen/config"
+	baristaApp "github.com/thangchung/go-coffeeshop/internal/barista/app"
+	baristaHandlers "github.com/thangchung/go-coffeeshop/internal/barista/eventhandlers"
+	baristaRepo "github.com/thangchung/go-coffeeshop/internal/barista/infras/repo"
+	kitchenApp "github.com/thangchung/go-coffeeshop/internal/kitchen/app"
+	kitchenHandlers "github.com/thangchung/go-coffeeshop/internal/kitchen/eventhandlers"
+	kitchenRepo "github.com/thangchung/go-coffeeshop/internal/kitchen/infras/repo"
+	"github.com/thangchung/go-coffeeshop/internal/pkg/event"
+	shared "github.com/thangchung/go-coffeeshop/internal/pkg/shared_kernel"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging"
+	"github.com/thangchung/go-coffeeshop/pkg/messaging/inmem"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres"
+	"github.com/thangchung/go-coffeeshop/pkg/postgres/postgrestest"
+)
+
+// station is the barista or the kitchen as the counter sees it: the orders it takes and the updates it sends back.
+type station struct {
+	name string
+	// orderExchange, orderBindingKey and orderType route an order to the station
+	orderExchange, orderBindingKey, orderType string
+	updatedType                               string
+	// ordered is the order of an item, e.g. event.BaristaOrdered
+	ordered func(orderID, itemLineID uuid.UUID) any
+	// expectInsert expects the station to store the item it made
+	expectInsert func(mock sqlmock.Sqlmock, orderID, itemLineID uuid.UUID)
+	// newWorker routes the app of the station over the bus and returns its worker, which makes the items at once
+	newWorker func(pg postgres.DBEngine, bus *inmem.Bus, counterPub messaging.Publisher, consumer messaging.Subscriber) messaging.Worker
+}
+
+var _stations = []station{
+	{
+		name:            "barista",
+		orderExchange:   "barista-order-exchange",
+		orderBindingKey: "barista-order-routing-key",
+		orderType:       "barista-order-created",
+		updatedType:     "barista-order-updated",
+		ordered: func(orderID, itemLineID uuid.UUID) any {
+			return event.BaristaOrdered{OrderID: orderID, ItemLineID: itemLineID, ItemType: shared.ItemTypeLatte}
+		},
+		expectInsert: func(mock sqlmock.Sqlmock, _, itemLineID uuid.UUID) {
+			mock.ExpectQuery("INSERT INTO\\s+barista.barista_orders").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "item_type", "item_name", "time_up", "created", "updated"}).
+					AddRow(itemLineID.String(), int32(shared.ItemTypeLatte), "LATTE", time.Now(), time.Now(), time.Now()))
+		},
+		newWorker: func(pg postgres.DBEngine, bus *inmem.Bus, counterPub messaging.Publisher, consumer messaging.Subscriber) messaging.Worker {
+			cfg := &baristaConfig.Config{}
+			handler := baristaHandlers.NewBaristaOrderedEventHandler(cfg, postgres.NewUnitOfWork(pg), baristaRepo.NewOrderRepo(pg), counterPub)
+			a := baristaApp.New(cfg, pg, bus, counterPub, consumer, handler)
+			a.ConfigureRoutes()
+
+			return a.Worker
+		},
+	},
+	{
+		name:            "kitchen",
+		orderExchange:   "kitchen-order-exchange",
+		orderBindingKey: "kitchen-order-routing-key",
+		orderType:       "kitchen-order-created",
+		updatedType:     "kitchen-order-updated",
+		ordered: func(orderID, itemLineID uuid.UUID) any {
+			return event.KitchenOrdered{OrderID: orderID, ItemLineID: itemLineID, ItemType: shared.ItemTypeCroissant}
+		},
+		expectInsert: func(mock sqlmock.Sqlmock, orderID, itemLineID uuid.UUID) {
+			mock.ExpectQuery("INSERT INTO\\s+kitchen.kitchen_orders").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "item_type", "item_name", "time_up", "created", "updated"}).
+					AddRow(itemLineID.String(), orderID.String(), int32(shared.ItemTypeCroissant), "CROISSANT", time.Now(), time.Now(), time.Now()))
+		},
+		newWorker: func(pg postgres.DBEngine, bus *inmem.Bus, counterPub messaging.Publisher, consumer messaging.Subscriber) messaging.Worker {
+			cfg := &kitchenConfig.Config{}
+			handler := kitchenHandlers.NewKitchenOrderedEventHandler(cfg, postgres.NewUnitOfWork(pg), kitchenRepo.NewOrderRepo(pg), counterPub)
+			a := kitchenApp.New(cfg, pg, bus, counterPub, consumer, handler)
+			a.ConfigureRoutes()
+
+			return a.Worker
+		},
+	},
+}
+
+func TestStationMakesTheItemAndTellsTheCounterWithoutABroker(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range _stations {
+		s := s
+
+		t.Run(s.name, func(t *testing.T) {
+			t.Parallel()
+
+			orderID, itemLineID := uuid.New(), uuid.New()
+
+			pg, mock := postgrestest.NewMockEngine(t)
+			mock.ExpectBegin()
+			s.expectInsert(mock, orderID, itemLineID)
+			mock.ExpectCommit()
+
+			updates := runStation(t, s, s.ordered(orderID, itemLineID), pg)
+
+			select {
+			case d := <-updates:
+				var updated struct {
+					OrderID    uuid.UUID `json:"orderId"`
+					ItemLineID uuid.UUID `json:"itemLineId"`
+				}
+				require.NoError(t, json.Unmarshal(d.Body, &updated))
+
+				assert.Equal(t, s.updatedType, d.Type)
+				assert.Equal(t, orderID, updated.OrderID)
+				assert.Equal(t, itemLineID, updated.ItemLineID)
+			case <-time.After(10 * time.Second):
+				t.Fatal("the counter got no update")
+			}
+
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// runStation runs the worker of the station over an in-memory bus, sends it the order
+// and returns the updates the counter gets, they are acknowledged on the way.
+func runStation(t *testing.T, s station, ordered any, pg postgres.DBEngine) <-chan messaging.Delivery {
+	t.Helper()
+
+	bus := inmem.NewBus()
+	t.Cleanup(bus.Close)
+
+	counterPub, err := bus.NewPublisher()
+	require.NoError(t, err)
+
+	consumer, err := bus.NewSubscriber()
+	require.NoError(t, err)
+
+	worker := s.newWorker(pg, bus, counterPub, consumer)
+
+	updates := make(chan messaging.Delivery, 1)
+	counter, err := bus.NewSubscriber(
+		messaging.ExchangeName("counter-order-exchange"),
+		messaging.QueueName("counter-order-queue"),
+		messaging.BindingKey("counter-order-routing-key"),
+	)
+	require.NoError(t, err)
+
+	go func() {
+		assert.NoError(t, counter.Subscribe(func(_ context.Context, deliveries <-chan messaging.Delivery) {
+			for d := range deliveries {
+				updates <- d
+				_ = d.Ack(false)
+			}
+		}))
+	}()
+
+	go func() {
+		assert.NoError(t, consumer.Subscribe(worker))
+	}()
+
+	body, err := json.Marshal(ordered)
+	require.NoError(t, err)
+
+	orderPub, err := bus.NewPublisher(
+		messaging.ExchangeName(s.orderExchange),
+		messaging.BindingKey(s.orderBindingKey),
+		messaging.MessageTypeName(s.orderType),
+	)
+	require.NoError(t, err)
+	require.NoError(t, orderPub.Publish(context.Background(), body, "text/plain"))
+
+	return updates
+}
@@ -0,0 +1,9 @@
+module github.com/thangchung/go-coffeeshop/tools/sqlc
+
+go 1.19
+
+require (
+	github.com/kyleconroy/sqlc v1.16.0
+	golang.org/x/exp v0.0.0-20221026153819-32f3d567a233
+	golang.org/x/text v0.4.0
+)
@@ -0,0 +1,10 @@
+//go:build tools
+// +build tools
+
+// Package sqlc pins the sqlc code generator in a module of its own,
+// its SQL parsers don't build against the golang.org/x/exp of the services.
+package sqlc
+
+import (
+	_ "github.com/kyleconroy/sqlc/cmd/sqlc"
+)
@@ -6,6 +6,5 @@ package tools
 import (
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-grpc-gateway"
 	_ "github.com/grpc-ecosystem/grpc-gateway/v2/protoc-gen-openapiv2"
-	_ "github.com/kyleconroy/sqlc"
 	_ "google.golang.org/protobuf/cmd/protoc-gen-go"
 )